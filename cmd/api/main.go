@@ -17,10 +17,16 @@ import (
 	"github.com/romankravchuk/eldorado/internal/server/http/api"
 	"github.com/romankravchuk/eldorado/internal/server/http/handlers"
 	authhandlers "github.com/romankravchuk/eldorado/internal/server/http/handlers/auth"
+	labelshandlers "github.com/romankravchuk/eldorado/internal/server/http/handlers/labels"
+	projectshandlers "github.com/romankravchuk/eldorado/internal/server/http/handlers/projects"
 	taskshandlers "github.com/romankravchuk/eldorado/internal/server/http/handlers/tasks"
 	"github.com/romankravchuk/eldorado/internal/server/http/middleware"
 	"github.com/romankravchuk/eldorado/internal/services/auth/client"
 	"github.com/romankravchuk/eldorado/internal/services/tasks"
+	"github.com/romankravchuk/eldorado/internal/storages"
+	"github.com/romankravchuk/eldorado/internal/storages/blobs/s3"
+	"github.com/romankravchuk/eldorado/internal/storages/sessions"
+	sessionsredis "github.com/romankravchuk/eldorado/internal/storages/sessions/redis"
 )
 
 func init() {
@@ -42,9 +48,36 @@ func main() {
 		os.Exit(1)
 	}
 
+	jwtAuth := middleware.JWT(log, authClient)
+	if cfg.Verification.Mode == "local" {
+		redisClient, err := storages.NewRedisClient(cfg.Verification.RedisURL)
+		if err != nil {
+			slog.Error("failed to create sessions redis client", sl.Err(err))
+			os.Exit(1)
+		}
+
+		jwtAuth = middleware.LocalJWT(
+			log,
+			client.NewKeys(authClient, cfg.Verification.KeysTTL),
+			sessions.NewDenylist(sessionsredis.New(redisClient)),
+		)
+	}
+
+	blobStorage := tasks.WithLocalBlobStorage(cfg.Blobs.Dir)
+	if cfg.Blobs.Driver == "s3" {
+		blobStorage = tasks.WithS3BlobStorage(s3.Config{
+			Endpoint:  cfg.Blobs.S3.Endpoint,
+			Region:    cfg.Blobs.S3.Region,
+			Bucket:    cfg.Blobs.S3.Bucket,
+			AccessKey: cfg.Blobs.S3.AccessKey,
+			SecretKey: cfg.Blobs.S3.SecretKey,
+		})
+	}
+
 	svc, err := tasks.New(
 		tasks.WithTaskPostgresStorage(cfg.Postgres.URL),
 		tasks.WithRedisCache(cfg.Redis.URL, cfg.Redis.TTL),
+		blobStorage,
 	)
 	if err != nil {
 		slog.Error("failed to create tasks service", sl.Err(err))
@@ -60,18 +93,87 @@ func main() {
 	mux.Use(chimiddleware.Recoverer)
 
 	mux.Get("/health", api.MakeHTTPHandlerFunc(handlers.HandleHealthCheck))
+	mux.Get("/.well-known/jwks.json", api.MakeHTTPHandlerFunc(authhandlers.HandleJWKS(log, authClient)))
 	mux.Route("/api", func(r chi.Router) {
 		r.Route("/auth", func(r chi.Router) {
 			r.Post("/", api.MakeHTTPHandlerFunc(authhandlers.HandleRegister(log, authClient)))
 			r.Post("/token", api.MakeHTTPHandlerFunc(authhandlers.HandleGetTokenPairs(log, authClient)))
 			r.Post("/refresh", api.MakeHTTPHandlerFunc(authhandlers.HandleRefreshToken(log, authClient)))
+			r.With(jwtAuth).Post("/logout", api.MakeHTTPHandlerFunc(authhandlers.HandleLogout(log, authClient)))
+			r.With(jwtAuth).Post("/logout-all", api.MakeHTTPHandlerFunc(authhandlers.HandleLogoutAll(log, authClient)))
+			r.With(jwtAuth).Route("/sessions", func(r chi.Router) {
+				r.Get("/", api.MakeHTTPHandlerFunc(authhandlers.HandleGetSessions(log, authClient)))
+				r.Delete("/{id}", api.MakeHTTPHandlerFunc(authhandlers.HandleRevokeSession(log, authClient)))
+			})
 		})
-		r.With(middleware.JWT(log, authClient)).Route("/tasks", func(r chi.Router) {
+		r.With(jwtAuth).Route("/tasks", func(r chi.Router) {
 			r.Post("/", api.MakeHTTPHandlerFunc(taskshandlers.HandleCreateTask(log, svc)))
 			r.Get("/", api.MakeHTTPHandlerFunc(taskshandlers.HandleGetTasks(log, svc)))
+			r.Get("/search", api.MakeHTTPHandlerFunc(taskshandlers.HandleSearchTasks(log, svc)))
+			r.Get("/trash", api.MakeHTTPHandlerFunc(taskshandlers.HandleGetTrash(log, svc)))
+			r.Post("/batch", api.MakeHTTPHandlerFunc(taskshandlers.HandleBatchTasks(log, svc)))
+			r.Get("/export", api.MakeHTTPHandlerFunc(taskshandlers.HandleExportTasks(log, svc)))
+			r.Post("/import", api.MakeHTTPHandlerFunc(taskshandlers.HandleImportTasks(log, svc)))
 			r.Route("/{id}", func(r chi.Router) {
+				r.Get("/", api.MakeHTTPHandlerFunc(taskshandlers.HandleGetTask(log, svc)))
 				r.Put("/", api.MakeHTTPHandlerFunc(taskshandlers.HandleUpdateTask(log, svc)))
+				r.Patch("/", api.MakeHTTPHandlerFunc(taskshandlers.HandlePatchTask(log, svc)))
 				r.Delete("/", api.MakeHTTPHandlerFunc(taskshandlers.HandleDeleteTask(log, svc)))
+				r.Post("/move", api.MakeHTTPHandlerFunc(taskshandlers.HandleMoveTask(log, svc)))
+				r.Post("/restore", api.MakeHTTPHandlerFunc(taskshandlers.HandleRestoreTask(log, svc)))
+				r.Get("/history", api.MakeHTTPHandlerFunc(taskshandlers.HandleGetTaskHistory(log, svc)))
+				r.Put("/assignee", api.MakeHTTPHandlerFunc(taskshandlers.HandleAssignTask(log, svc)))
+				r.Post("/labels", api.MakeHTTPHandlerFunc(taskshandlers.HandleAttachLabel(log, svc)))
+				r.Delete("/labels/{labelID}", api.MakeHTTPHandlerFunc(taskshandlers.HandleDetachLabel(log, svc)))
+				r.Route("/attachments", func(r chi.Router) {
+					limits := taskshandlers.AttachmentLimits{MaxSize: cfg.Attachments.MaxSize, Types: cfg.Attachments.Types}
+
+					r.Get("/", api.MakeHTTPHandlerFunc(taskshandlers.HandleGetAttachments(log, svc)))
+					r.Post("/", api.MakeHTTPHandlerFunc(taskshandlers.HandleUploadAttachment(log, svc, limits)))
+					r.Route("/{attachmentID}", func(r chi.Router) {
+						r.Get("/", api.MakeHTTPHandlerFunc(taskshandlers.HandleDownloadAttachment(log, svc)))
+						r.Delete("/", api.MakeHTTPHandlerFunc(taskshandlers.HandleDeleteAttachment(log, svc)))
+					})
+				})
+				r.Route("/comments", func(r chi.Router) {
+					r.Get("/", api.MakeHTTPHandlerFunc(taskshandlers.HandleGetComments(log, svc)))
+					r.Post("/", api.MakeHTTPHandlerFunc(taskshandlers.HandleCreateComment(log, svc)))
+					r.Route("/{commentID}", func(r chi.Router) {
+						r.Patch("/", api.MakeHTTPHandlerFunc(taskshandlers.HandleUpdateComment(log, svc)))
+						r.Delete("/", api.MakeHTTPHandlerFunc(taskshandlers.HandleDeleteComment(log, svc)))
+					})
+				})
+				r.Route("/items", func(r chi.Router) {
+					r.Get("/", api.MakeHTTPHandlerFunc(taskshandlers.HandleGetItems(log, svc)))
+					r.Post("/", api.MakeHTTPHandlerFunc(taskshandlers.HandleCreateItem(log, svc)))
+					r.Route("/{itemID}", func(r chi.Router) {
+						r.Patch("/", api.MakeHTTPHandlerFunc(taskshandlers.HandlePatchItem(log, svc)))
+						r.Delete("/", api.MakeHTTPHandlerFunc(taskshandlers.HandleDeleteItem(log, svc)))
+						r.Post("/move", api.MakeHTTPHandlerFunc(taskshandlers.HandleMoveItem(log, svc)))
+					})
+				})
+			})
+		})
+		r.With(jwtAuth).Route("/projects", func(r chi.Router) {
+			r.Get("/", api.MakeHTTPHandlerFunc(projectshandlers.HandleGetProjects(log, svc)))
+			r.Post("/", api.MakeHTTPHandlerFunc(projectshandlers.HandleCreateProject(log, svc)))
+			r.Route("/{projectID}", func(r chi.Router) {
+				r.Patch("/", api.MakeHTTPHandlerFunc(projectshandlers.HandlePatchProject(log, svc)))
+				r.Delete("/", api.MakeHTTPHandlerFunc(projectshandlers.HandleDeleteProject(log, svc)))
+				r.Route("/members", func(r chi.Router) {
+					r.Get("/", api.MakeHTTPHandlerFunc(projectshandlers.HandleGetMembers(log, svc)))
+					r.Post("/", api.MakeHTTPHandlerFunc(projectshandlers.HandleInviteMember(log, svc)))
+					r.Patch("/{userID}", api.MakeHTTPHandlerFunc(projectshandlers.HandleUpdateMember(log, svc)))
+					r.Delete("/{userID}", api.MakeHTTPHandlerFunc(projectshandlers.HandleRemoveMember(log, svc)))
+				})
+			})
+		})
+		r.With(jwtAuth).Route("/labels", func(r chi.Router) {
+			r.Get("/", api.MakeHTTPHandlerFunc(labelshandlers.HandleGetLabels(log, svc)))
+			r.Post("/", api.MakeHTTPHandlerFunc(labelshandlers.HandleCreateLabel(log, svc)))
+			r.Route("/{labelID}", func(r chi.Router) {
+				r.Patch("/", api.MakeHTTPHandlerFunc(labelshandlers.HandlePatchLabel(log, svc)))
+				r.Delete("/", api.MakeHTTPHandlerFunc(labelshandlers.HandleDeleteLabel(log, svc)))
 			})
 		})
 	})
@@ -29,6 +29,19 @@ var serviceRules = map[string]map[string]string{
 	"refresh": {
 		"Refresh": "required",
 	},
+	"logout": {
+		"Access": "required",
+	},
+	"logout-all": {
+		"Access": "required",
+	},
+	"list-sessions": {
+		"Access": "required",
+	},
+	"revoke-session": {
+		"Access": "required",
+		"Id":     "required,uuid",
+	},
 }
 
 func main() {
@@ -43,13 +56,27 @@ func main() {
 	validator.RegisterRules(&proto.SignUpRequest{}, serviceRules["sign-up"])
 	validator.RegisterRules(&proto.TokenRequest{}, serviceRules["token"])
 	validator.RegisterRules(&proto.RefreshRequest{}, serviceRules["refresh"])
+	validator.RegisterRules(&proto.LogoutRequest{}, serviceRules["logout"])
+	validator.RegisterRules(&proto.LogoutAllRequest{}, serviceRules["logout-all"])
+	validator.RegisterRules(&proto.ListSessionsRequest{}, serviceRules["list-sessions"])
+	validator.RegisterRules(&proto.RevokeSessionRequest{}, serviceRules["revoke-session"])
+
+	accessRotation := auth.Rotation{Overlap: cfg.AccessCreds.Rotation.Overlap}
+	for _, k := range cfg.AccessCreds.Rotation.Keys {
+		accessRotation.Keys = append(accessRotation.Keys, auth.RotationKey{PrivateKey: k.PrivateKey, ActiveFrom: k.ActiveFrom})
+	}
+
+	refreshRotation := auth.Rotation{Overlap: cfg.RefreshCreds.Rotation.Overlap}
+	for _, k := range cfg.RefreshCreds.Rotation.Keys {
+		refreshRotation.Keys = append(refreshRotation.Keys, auth.RotationKey{PrivateKey: k.PrivateKey, ActiveFrom: k.ActiveFrom})
+	}
 
 	svc, err := auth.New(
 		auth.WithLogger(log),
 		auth.WithUsersPostgresStorage(cfg.Postgres.URL),
 		auth.WtihRedisSessionsStorage(cfg.Redis.URL),
-		auth.WithAccessCreds(cfg.AccessCreds.PrivateKey, cfg.AccessCreds.PublicKey, cfg.AccessCreds.Expires),
-		auth.WithRefreshCreds(cfg.RefreshCreds.PrivateKey, cfg.RefreshCreds.PublicKey, cfg.RefreshCreds.Expires),
+		auth.WithAccessCreds(cfg.AccessCreds.PrivateKey, cfg.AccessCreds.PublicKey, cfg.AccessCreds.Expires, accessRotation),
+		auth.WithRefreshCreds(cfg.RefreshCreds.PrivateKey, cfg.RefreshCreds.PublicKey, cfg.RefreshCreds.Expires, refreshRotation),
 	)
 	failOnError("failed to create auth service", err)
 
@@ -17,10 +17,23 @@ func main() {
 	//
 	//log.Debug("the debug mode is activated")
 	//
+	//blobStorage := statistic.WithLocalBlobStorage(cfg.Blobs.Dir)
+	//if cfg.Blobs.Driver == "s3" {
+	//	blobStorage = statistic.WithS3BlobStorage(s3.Config{
+	//		Endpoint:  cfg.Blobs.S3.Endpoint,
+	//		Region:    cfg.Blobs.S3.Region,
+	//		Bucket:    cfg.Blobs.S3.Bucket,
+	//		AccessKey: cfg.Blobs.S3.AccessKey,
+	//		SecretKey: cfg.Blobs.S3.SecretKey,
+	//	})
+	//}
+	//
 	//svc, err := statistic.New(
 	//	statistic.WithCron(cfg.Schedule),
+	//	statistic.WithTrashPurge(cfg.Trash.PurgeSchedule, cfg.Trash.Retention),
 	//	statistic.WithLogger(log),
 	//	statistic.WithPosgresTasksStorage(cfg.Postgres.URL),
+	//	blobStorage,
 	//	statistic.WithRabbitMQ(cfg.RabbitMQ.URL, cfg.RabbitMQ.QueueName),
 	//)
 	//failOnError("failed to create statistic service", err)
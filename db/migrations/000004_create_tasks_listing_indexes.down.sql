@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS "public".idx_tasks_user_title;
+DROP INDEX IF EXISTS "public".idx_tasks_user_created_on;
@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_tasks_user_created_on ON "public".tasks (user_id, created_on, id) WHERE is_deleted = false;
+CREATE INDEX IF NOT EXISTS idx_tasks_user_title ON "public".tasks (user_id, title, id) WHERE is_deleted = false;
@@ -0,0 +1 @@
+ALTER TABLE "public".tasks ADD COLUMN IF NOT EXISTS version integer DEFAULT 1 NOT NULL;
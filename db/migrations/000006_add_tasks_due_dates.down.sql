@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS "public".idx_tasks_user_due_at;
+ALTER TABLE "public".tasks DROP CONSTRAINT IF EXISTS chk_tasks_remind_at;
+ALTER TABLE "public".tasks DROP COLUMN IF EXISTS remind_at;
+ALTER TABLE "public".tasks DROP COLUMN IF EXISTS due_at;
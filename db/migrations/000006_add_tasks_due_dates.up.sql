@@ -0,0 +1,5 @@
+ALTER TABLE "public".tasks ADD COLUMN IF NOT EXISTS due_at timestamp;
+ALTER TABLE "public".tasks ADD COLUMN IF NOT EXISTS remind_at timestamp;
+ALTER TABLE "public".tasks
+ADD CONSTRAINT chk_tasks_remind_at CHECK (remind_at IS NULL OR (due_at IS NOT NULL AND remind_at <= due_at));
+CREATE INDEX IF NOT EXISTS idx_tasks_user_due_at ON "public".tasks (user_id, due_at) WHERE is_deleted = false AND is_completed = false;
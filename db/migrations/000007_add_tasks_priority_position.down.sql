@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS "public".idx_tasks_user_priority;
+DROP INDEX IF EXISTS "public".idx_tasks_user_position;
+ALTER TABLE "public".tasks DROP COLUMN IF EXISTS position;
+ALTER TABLE "public".tasks DROP COLUMN IF EXISTS priority;
+DROP TYPE IF EXISTS task_priority;
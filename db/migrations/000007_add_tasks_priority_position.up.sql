@@ -0,0 +1,8 @@
+CREATE TYPE task_priority AS ENUM ('low', 'normal', 'high', 'urgent');
+ALTER TABLE "public".tasks ADD COLUMN IF NOT EXISTS priority task_priority DEFAULT 'normal' NOT NULL;
+ALTER TABLE "public".tasks ADD COLUMN IF NOT EXISTS position double precision DEFAULT 0 NOT NULL;
+UPDATE "public".tasks t SET position = r.rank
+FROM (SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY created_on, id) AS rank FROM "public".tasks) r
+WHERE t.id = r.id;
+CREATE INDEX IF NOT EXISTS idx_tasks_user_position ON "public".tasks (user_id, position, id) WHERE is_deleted = false;
+CREATE INDEX IF NOT EXISTS idx_tasks_user_priority ON "public".tasks (user_id, priority, id) WHERE is_deleted = false;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS "public".task_items (
+    id uuid DEFAULT uuid_generate_v4() NOT NULL,
+    task_id uuid NOT NULL,
+    title varchar(255) NOT NULL,
+    is_completed boolean DEFAULT false NOT NULL,
+    position double precision DEFAULT 0 NOT NULL,
+    created_on timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_on timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT pk_task_items PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_task_items_task_position ON "public".task_items (task_id, position, id);
+ALTER TABLE "public".task_items
+ADD CONSTRAINT fk_task_items_tasks FOREIGN KEY (task_id) REFERENCES "public".tasks(id) ON DELETE CASCADE;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "public".task_labels CASCADE;
+DROP TABLE IF EXISTS "public".labels CASCADE;
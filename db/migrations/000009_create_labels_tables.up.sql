@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS "public".labels (
+    id uuid DEFAULT uuid_generate_v4() NOT NULL,
+    user_id uuid NOT NULL,
+    name varchar(50) NOT NULL,
+    color varchar(7) DEFAULT '#9e9e9e' NOT NULL,
+    created_on timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT pk_labels PRIMARY KEY (id),
+    CONSTRAINT uq_labels_user_name UNIQUE (user_id, name)
+);
+ALTER TABLE "public".labels
+ADD CONSTRAINT fk_labels_users FOREIGN KEY (user_id) REFERENCES "public".users(id) ON DELETE CASCADE;
+CREATE TABLE IF NOT EXISTS "public".task_labels (
+    task_id uuid NOT NULL,
+    label_id uuid NOT NULL,
+    CONSTRAINT pk_task_labels PRIMARY KEY (task_id, label_id)
+);
+CREATE INDEX IF NOT EXISTS idx_task_labels_label ON "public".task_labels (label_id, task_id);
+ALTER TABLE "public".task_labels
+ADD CONSTRAINT fk_task_labels_tasks FOREIGN KEY (task_id) REFERENCES "public".tasks(id) ON DELETE CASCADE;
+ALTER TABLE "public".task_labels
+ADD CONSTRAINT fk_task_labels_labels FOREIGN KEY (label_id) REFERENCES "public".labels(id) ON DELETE CASCADE;
@@ -0,0 +1,4 @@
+ALTER TABLE "public".tasks DROP CONSTRAINT IF EXISTS fk_tasks_projects;
+DROP INDEX IF EXISTS "public".idx_tasks_project;
+ALTER TABLE "public".tasks DROP COLUMN IF EXISTS project_id;
+DROP TABLE IF EXISTS "public".projects CASCADE;
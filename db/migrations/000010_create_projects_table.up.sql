@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS "public".projects (
+    id uuid DEFAULT uuid_generate_v4() NOT NULL,
+    user_id uuid NOT NULL,
+    name varchar(100) NOT NULL,
+    color varchar(7) DEFAULT '#9e9e9e' NOT NULL,
+    is_archived boolean DEFAULT false NOT NULL,
+    created_on timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_on timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT pk_projects PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_projects_user ON "public".projects (user_id, name, id);
+ALTER TABLE "public".projects
+ADD CONSTRAINT fk_projects_users FOREIGN KEY (user_id) REFERENCES "public".users(id) ON DELETE CASCADE;
+ALTER TABLE "public".tasks ADD COLUMN IF NOT EXISTS project_id uuid;
+CREATE INDEX IF NOT EXISTS idx_tasks_project ON "public".tasks (project_id) WHERE is_deleted = false;
+ALTER TABLE "public".tasks
+ADD CONSTRAINT fk_tasks_projects FOREIGN KEY (project_id) REFERENCES "public".projects(id) ON DELETE SET NULL;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "public".project_members CASCADE;
+DROP TYPE IF EXISTS project_role;
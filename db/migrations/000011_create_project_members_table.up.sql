@@ -0,0 +1,15 @@
+CREATE TYPE project_role AS ENUM ('owner', 'editor', 'viewer');
+CREATE TABLE IF NOT EXISTS "public".project_members (
+    project_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    role project_role DEFAULT 'viewer' NOT NULL,
+    created_on timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT pk_project_members PRIMARY KEY (project_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_project_members_user ON "public".project_members (user_id, project_id);
+ALTER TABLE "public".project_members
+ADD CONSTRAINT fk_project_members_projects FOREIGN KEY (project_id) REFERENCES "public".projects(id) ON DELETE CASCADE;
+ALTER TABLE "public".project_members
+ADD CONSTRAINT fk_project_members_users FOREIGN KEY (user_id) REFERENCES "public".users(id) ON DELETE CASCADE;
+INSERT INTO "public".project_members (project_id, user_id, role)
+SELECT id, user_id, 'owner' FROM "public".projects;
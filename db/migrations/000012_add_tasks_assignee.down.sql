@@ -0,0 +1,3 @@
+ALTER TABLE "public".tasks DROP CONSTRAINT IF EXISTS fk_tasks_assignees;
+DROP INDEX IF EXISTS "public".idx_tasks_assignee;
+ALTER TABLE "public".tasks DROP COLUMN IF EXISTS assignee_id;
@@ -0,0 +1,4 @@
+ALTER TABLE "public".tasks ADD COLUMN IF NOT EXISTS assignee_id uuid;
+CREATE INDEX IF NOT EXISTS idx_tasks_assignee ON "public".tasks (assignee_id, created_on, id) WHERE is_deleted = false;
+ALTER TABLE "public".tasks
+ADD CONSTRAINT fk_tasks_assignees FOREIGN KEY (assignee_id) REFERENCES "public".users(id) ON DELETE SET NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE "public".tasks ADD COLUMN IF NOT EXISTS recurrence varchar(100);
+ALTER TABLE "public".tasks ADD COLUMN IF NOT EXISTS recurrence_start timestamp;
@@ -0,0 +1,2 @@
+UPDATE "public".tasks SET deleted_on = updated_on WHERE is_deleted = true AND deleted_on IS NULL;
+CREATE INDEX IF NOT EXISTS idx_tasks_deleted ON "public".tasks (deleted_on) WHERE is_deleted = true;
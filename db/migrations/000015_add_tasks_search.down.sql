@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS "public".idx_tasks_search;
+ALTER TABLE "public".tasks DROP COLUMN IF EXISTS search;
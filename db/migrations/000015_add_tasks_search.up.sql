@@ -0,0 +1,6 @@
+ALTER TABLE "public".tasks ADD COLUMN IF NOT EXISTS search tsvector
+GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(description, '')), 'B')
+) STORED;
+CREATE INDEX IF NOT EXISTS idx_tasks_search ON "public".tasks USING GIN (search);
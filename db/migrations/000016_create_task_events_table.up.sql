@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS "public".task_events (
+    id bigserial NOT NULL,
+    task_id uuid NOT NULL,
+    actor_id uuid,
+    kind varchar(20) NOT NULL,
+    changes jsonb DEFAULT '{}' NOT NULL,
+    created_on timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT pk_task_events PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_task_events_task ON "public".task_events (task_id, id);
+ALTER TABLE "public".task_events
+ADD CONSTRAINT fk_task_events_users FOREIGN KEY (actor_id) REFERENCES "public".users(id) ON DELETE SET NULL;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS "public".task_comments (
+    id uuid DEFAULT uuid_generate_v4() NOT NULL,
+    task_id uuid NOT NULL,
+    author_id uuid,
+    content varchar(5000) NOT NULL,
+    is_deleted boolean DEFAULT false NOT NULL,
+    created_on timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_on timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    deleted_on timestamp,
+    CONSTRAINT pk_task_comments PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_task_comments_task ON "public".task_comments (task_id, created_on, id) WHERE is_deleted = false;
+ALTER TABLE "public".task_comments
+ADD CONSTRAINT fk_task_comments_tasks FOREIGN KEY (task_id) REFERENCES "public".tasks(id) ON DELETE CASCADE;
+ALTER TABLE "public".task_comments
+ADD CONSTRAINT fk_task_comments_users FOREIGN KEY (author_id) REFERENCES "public".users(id) ON DELETE SET NULL;
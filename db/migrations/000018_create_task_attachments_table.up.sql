@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS "public".task_attachments (
+    id uuid NOT NULL,
+    task_id uuid,
+    uploader_id uuid,
+    filename varchar(255) NOT NULL,
+    content_type varchar(100) NOT NULL,
+    size bigint NOT NULL,
+    blob_key varchar(255) NOT NULL,
+    created_on timestamp DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT pk_task_attachments PRIMARY KEY (id),
+    CONSTRAINT uq_task_attachments_blob_key UNIQUE (blob_key)
+);
+CREATE INDEX IF NOT EXISTS idx_task_attachments_task ON "public".task_attachments (task_id, created_on, id);
+CREATE INDEX IF NOT EXISTS idx_task_attachments_orphans ON "public".task_attachments (created_on) WHERE task_id IS NULL;
+ALTER TABLE "public".task_attachments
+ADD CONSTRAINT fk_task_attachments_tasks FOREIGN KEY (task_id) REFERENCES "public".tasks(id) ON DELETE SET NULL;
+ALTER TABLE "public".task_attachments
+ADD CONSTRAINT fk_task_attachments_users FOREIGN KEY (uploader_id) REFERENCES "public".users(id) ON DELETE SET NULL;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS "public".idx_tasks_project_position;
@@ -0,0 +1,4 @@
+UPDATE "public".tasks t SET position = r.rank
+FROM (SELECT id, ROW_NUMBER() OVER (PARTITION BY COALESCE(project_id, user_id) ORDER BY position, id) AS rank FROM "public".tasks) r
+WHERE t.id = r.id;
+CREATE INDEX IF NOT EXISTS idx_tasks_project_position ON "public".tasks (project_id, position, id) WHERE is_deleted = false;
@@ -9,16 +9,21 @@ import (
 )
 
 type APIConfig struct {
-	Env             string   `yaml:"env"`
-	AuthServiceAddr string   `yaml:"auth_service_addr" env:"AUTH_SERVICE_ADDR"`
-	Postgres        postgres `yaml:"postgres"`
-	Server          server   `yaml:"server"`
-	Redis           redis    `yaml:"redis"`
+	Env             string       `yaml:"env"`
+	AuthServiceAddr string       `yaml:"auth_service_addr" env:"AUTH_SERVICE_ADDR"`
+	Postgres        postgres     `yaml:"postgres"`
+	Server          server       `yaml:"server"`
+	Redis           redis        `yaml:"redis"`
+	Blobs           blobs        `yaml:"blobs"`
+	Attachments     attachments  `yaml:"attachments"`
+	Verification    verification `yaml:"verification"`
 }
 
 type StatisticServiceConfig struct {
 	Env      string   `yaml:"env"`
 	Schedule string   `yaml:"schedule"`
+	Trash    trash    `yaml:"trash"`
+	Blobs    blobs    `yaml:"blobs"`
 	RabbitMQ rabbitmq `yaml:"rabbitmq"`
 	Postgres postgres `yaml:"postgres"`
 }
@@ -42,6 +47,19 @@ type rsacreds struct {
 	PrivateKey string        `yaml:"private_key" env:"RSA_PRIVATE_KEY"`
 	PublicKey  string        `yaml:"public_key" env:"RSA_PUBLIC_KEY"`
 	Expires    time.Duration `yaml:"expires"`
+	Rotation   rotation      `yaml:"rotation"`
+}
+
+// rotation schedules the keys replacing the key pair of rsacreds,
+// a replaced key verifies tokens for Overlap after the next key activates.
+type rotation struct {
+	Overlap time.Duration `yaml:"overlap"`
+	Keys    []rotationKey `yaml:"keys"`
+}
+
+type rotationKey struct {
+	PrivateKey string    `yaml:"private_key"`
+	ActiveFrom time.Time `yaml:"active_from"`
 }
 
 type server struct {
@@ -65,6 +83,39 @@ type rabbitmq struct {
 	QueueName string `yaml:"queue_name"`
 }
 
+type trash struct {
+	PurgeSchedule string        `yaml:"purge_schedule" env:"TRASH_PURGE_SCHEDULE" env-default:"@daily"`
+	Retention     time.Duration `yaml:"retention" env:"TRASH_RETENTION" env-default:"720h"`
+}
+
+// blobs selects a storage of attachment contents, Driver is either local or s3.
+type blobs struct {
+	Driver string `yaml:"driver" env:"BLOBS_DRIVER" env-default:"local"`
+	Dir    string `yaml:"dir" env:"BLOBS_DIR" env-default:"/var/lib/eldorado/blobs"`
+	S3     s3     `yaml:"s3"`
+}
+
+type s3 struct {
+	Endpoint  string `yaml:"endpoint" env:"S3_ENDPOINT"`
+	Region    string `yaml:"region" env:"S3_REGION" env-default:"us-east-1"`
+	Bucket    string `yaml:"bucket" env:"S3_BUCKET"`
+	AccessKey string `env:"S3_ACCESS_KEY"`
+	SecretKey string `env:"S3_SECRET_KEY"`
+}
+
+type attachments struct {
+	MaxSize int64    `yaml:"max_size" env:"ATTACHMENTS_MAX_SIZE" env-default:"10485760"`
+	Types   []string `yaml:"types" env:"ATTACHMENTS_TYPES" env-default:"image/png,image/jpeg,image/gif,image/webp,application/pdf"`
+}
+
+// verification selects how the api verifies access tokens, Mode is either remote or local.
+// The local mode reads the keys from the auth service and the revoked tokens from its redis.
+type verification struct {
+	Mode     string        `yaml:"mode" env:"AUTH_VERIFICATION_MODE" env-default:"remote"`
+	KeysTTL  time.Duration `yaml:"keys_ttl" env:"AUTH_KEYS_TTL" env-default:"10m"`
+	RedisURL string        `yaml:"redis_url" env:"AUTH_REDIS_URL"`
+}
+
 type smtp struct {
 	Host     string `yaml:"host" env:"SMTP_HOST"`
 	Port     string `yaml:"port" env:"SMTP_PORT"`
@@ -0,0 +1,27 @@
+package data
+
+import "time"
+
+// Label is a user's tag which can be attached to any number of the user's tasks.
+type Label struct {
+	ID        string    `db:"id"`
+	UserID    string    `db:"user_id"`
+	Name      string    `db:"name"`
+	Color     string    `db:"color"`
+	CreatedOn time.Time `db:"created_on"`
+}
+
+// LabelPatch is a partial update of a label, nil fields are left unchanged.
+type LabelPatch struct {
+	Name  *string
+	Color *string
+}
+
+const (
+	LabelsMaxFilter = 10
+
+	LabelsMatchAll = "all"
+	LabelsMatchAny = "any"
+
+	LabelDefaultColor = "#9e9e9e"
+)
@@ -0,0 +1,59 @@
+package data
+
+import "time"
+
+// Project groups tasks of a user.
+//
+// Tasks of an archived project are hidden from the default task list.
+// UserID is the owner of the project, Role is the role of the user who requested it.
+type Project struct {
+	ID         string    `db:"id"`
+	UserID     string    `db:"user_id"`
+	Name       string    `db:"name"`
+	Color      string    `db:"color"`
+	IsArchived bool      `db:"is_archived"`
+	CreatedOn  time.Time `db:"created_on"`
+	UpdatedOn  time.Time `db:"updated_on"`
+	Role       string    `db:"role"`
+}
+
+// ProjectMember is a user who has access to tasks of a project.
+type ProjectMember struct {
+	ProjectID string    `db:"project_id"`
+	UserID    string    `db:"user_id"`
+	Email     string    `db:"email"`
+	Role      string    `db:"role"`
+	CreatedOn time.Time `db:"created_on"`
+}
+
+// ProjectPatch is a partial update of a project, nil fields are left unchanged.
+type ProjectPatch struct {
+	Name       *string
+	Color      *string
+	IsArchived *bool
+}
+
+const (
+	ProjectDefaultColor = "#9e9e9e"
+
+	// RoleOwner manages the project and its members.
+	RoleOwner = "owner"
+	// RoleEditor creates and changes tasks of the project.
+	RoleEditor = "editor"
+	// RoleViewer only reads tasks of the project.
+	RoleViewer = "viewer"
+)
+
+// CanEditTasks reports whether a member with a given role may change tasks of a project.
+func CanEditTasks(role string) bool {
+	return role == RoleOwner || role == RoleEditor
+}
+
+// ProjectStatistic is a summary of tasks of a single project for the statistic digest.
+type ProjectStatistic struct {
+	Email     string `db:"email"`
+	Project   string `db:"project"`
+	Total     int    `db:"total"`
+	Completed int    `db:"completed"`
+	Overdue   int    `db:"overdue"`
+}
@@ -0,0 +1,14 @@
+package data
+
+import "time"
+
+// Session is a sign in of a user on a device.
+// Its ID is the family ID of the tokens issued for it.
+type Session struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"user_id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
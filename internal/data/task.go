@@ -3,17 +3,331 @@ package data
 import "time"
 
 type Task struct {
+	ID          string     `db:"id"`
+	UserID      string     `db:"user_id"`
+	Title       string     `db:"title"`
+	Description string     `db:"description"`
+	IsCompleted bool       `db:"is_completed"`
+	IsDeleted   bool       `db:"is_deleted"`
+	CreatedOn   time.Time  `db:"created_on"`
+	UpdatedOn   time.Time  `db:"updated_on"`
+	DeletedOn   *time.Time `db:"deleted_on"`
+	Version     int        `db:"version"`
+	DueAt       *time.Time `db:"due_at"`
+	RemindAt    *time.Time `db:"remind_at"`
+	Priority    string     `db:"priority"`
+	Position    float64    `db:"position"`
+	ProjectID   *string    `db:"project_id"`
+	AssigneeID  *string    `db:"assignee_id"`
+
+	// Recurrence is a recurrence rule of the task, see package recurrence.
+	// RecurrenceStart is the due time of the first task of the recurring series.
+	Recurrence      string     `db:"recurrence"`
+	RecurrenceStart *time.Time `db:"recurrence_start"`
+
+	ItemsCount     int `db:"items_count"`
+	ItemsCompleted int `db:"items_completed"`
+
+	Labels []Label `db:"labels"`
+}
+
+// Progress returns a percent of completed checklist items of the task.
+//
+// A task without items has 0 progress.
+func (t Task) Progress() int {
+	if t.ItemsCount == 0 {
+		return 0
+	}
+	return t.ItemsCompleted * 100 / t.ItemsCount
+}
+
+// IsOverdue reports whether the task is not completed after its due time.
+func (t Task) IsOverdue(now time.Time) bool {
+	return !t.IsCompleted && t.DueAt != nil && t.DueAt.Before(now)
+}
+
+// TaskPatch is a partial update of a task, nil fields are left unchanged.
+//
+// ClearDueAt, ClearRemindAt, ClearProjectID, ClearAssigneeID and ClearRecurrence reset the corresponding optional fields to null.
+// If Version is not 0 the patch is applied only to the task with this version.
+type TaskPatch struct {
+	Title           *string
+	Description     *string
+	IsCompleted     *bool
+	Priority        *string
+	DueAt           *time.Time
+	RemindAt        *time.Time
+	ProjectID       *string
+	AssigneeID      *string
+	Recurrence      *string
+	RecurrenceStart *time.Time
+	ClearDueAt      bool
+	ClearRemindAt   bool
+	ClearProjectID  bool
+	ClearAssigneeID bool
+	ClearRecurrence bool
+	Version         int
+}
+
+// IsCompletion reports whether the patch changes only whether the task is completed.
+func (p TaskPatch) IsCompletion() bool {
+	return p.IsCompleted != nil && p == TaskPatch{IsCompleted: p.IsCompleted, Version: p.Version}
+}
+
+// IsEmpty reports whether the patch changes nothing.
+func (p TaskPatch) IsEmpty() bool {
+	return p.Title == nil && p.Description == nil && p.IsCompleted == nil && p.Priority == nil &&
+		p.DueAt == nil && p.RemindAt == nil && p.ProjectID == nil && p.AssigneeID == nil &&
+		p.Recurrence == nil && p.RecurrenceStart == nil &&
+		!p.ClearDueAt && !p.ClearRemindAt && !p.ClearProjectID && !p.ClearAssigneeID && !p.ClearRecurrence
+}
+
+const (
+	TasksDefaultLimit = 50
+	TasksMaxLimit     = 100
+
+	SearchDefaultLimit = 20
+	SearchMaxLimit     = 50
+
+	TasksSortCreatedOn = "created_on"
+	TasksSortTitle     = "title"
+	TasksSortPriority  = "priority"
+	TasksSortPosition  = "position"
+	TasksSortDeletedOn = "deleted_on"
+
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+
+	PriorityLow    = "low"
+	PriorityNormal = "normal"
+	PriorityHigh   = "high"
+	PriorityUrgent = "urgent"
+)
+
+// TaskMove describes a new place of a task between its neighbours in a user's list.
+//
+// AfterID is the task which will precede the moved one, BeforeID is the task which
+// will follow it. An empty AfterID moves the task to the top of the list,
+// an empty BeforeID moves it to the bottom.
+type TaskMove struct {
+	AfterID  string
+	BeforeID string
+}
+
+// TasksQuery describes a page of user's tasks.
+//
+// Cursor is an opaque value returned as TasksPage.NextCursor of the previous page.
+// Nil Completed, DueBefore and Overdue mean tasks are not filtered by them.
+// An empty ProjectID means tasks of all projects except archived ones.
+// A non empty AssigneeID limits the page to the tasks assigned to this user.
+// Labels are label names, LabelsMatch selects whether a task must have all of them
+// (LabelsMatchAll, the default) or any of them (LabelsMatchAny).
+// Deleted selects the tasks in the trash instead of the active ones.
+type TasksQuery struct {
+	UserID      string
+	Limit       int
+	Cursor      string
+	Completed   *bool
+	DueBefore   *time.Time
+	Overdue     *bool
+	ProjectID   string
+	AssigneeID  string
+	Labels      []string
+	LabelsMatch string
+	Deleted     bool
+	Sort        string
+	Order       string
+}
+
+// TasksSearch describes a full-text search over the tasks visible to a user.
+//
+// Every word of Query matches the words of task titles and descriptions starting with it.
+type TasksSearch struct {
+	UserID string
+	Query  string
+	Limit  int
+}
+
+// TaskHit is a task found by a search.
+//
+// Title and Snippet are HTML escaped fragments of the task title and description
+// with the matched words wrapped into <mark> elements.
+type TaskHit struct {
+	Task    Task
+	Rank    float64
+	Title   string
+	Snippet string
+}
+
+type TasksPage struct {
+	Tasks      []Task
+	NextCursor string
+}
+
+const (
+	TaskOpCreate = "create"
+	TaskOpUpdate = "update"
+	TaskOpDelete = "delete"
+)
+
+// TaskOp is an operation of a batch.
+//
+// A create operation saves Task, an update operation applies Patch to the task with ID
+// and a delete operation moves the task with ID to the trash. A non zero Version of
+// update and delete operations must match the task version.
+type TaskOp struct {
+	Kind    string
+	ID      string
+	Version int
+	Task    Task
+	Patch   TaskPatch
+}
+
+// TaskOpResult is a result of a batch operation.
+//
+// Task is the created or updated task, Previous is the task before an update or delete.
+// Err is the error of a failed operation.
+type TaskOpResult struct {
+	Task     Task
+	Previous *Task
+	Err      error
+}
+
+const (
+	TaskEventCreated  = "created"
+	TaskEventUpdated  = "updated"
+	TaskEventDeleted  = "deleted"
+	TaskEventRestored = "restored"
+
+	TaskEventsDefaultLimit = 50
+	TaskEventsMaxLimit     = 100
+)
+
+// TaskEvent is a record of the task history.
+//
+// Changes of a created or updated task are keyed by the changed field names.
+// ActorID and ActorEmail are empty when the actor has been deleted.
+type TaskEvent struct {
+	ID         int64                 `db:"id"`
+	TaskID     string                `db:"task_id"`
+	ActorID    string                `db:"actor_id"`
+	ActorEmail string                `db:"email"`
+	Kind       string                `db:"kind"`
+	Changes    map[string]TaskChange `db:"changes"`
+	CreatedOn  time.Time             `db:"created_on"`
+}
+
+// TaskChange is a change of a single task field, From is nil for a created task.
+type TaskChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// TaskEventsQuery describes a page of the task history, the newest events first.
+//
+// A non zero Before limits the page to the events older than the event with this id.
+type TaskEventsQuery struct {
+	UserID string
+	TaskID string
+	Limit  int
+	Before int64
+}
+
+// TaskItem is a checklist item of a task.
+type TaskItem struct {
 	ID          string    `db:"id"`
-	UserID      string    `db:"user_id"`
+	TaskID      string    `db:"task_id"`
 	Title       string    `db:"title"`
-	Description string    `db:"description"`
 	IsCompleted bool      `db:"is_completed"`
-	IsDeleted   bool      `db:"is_deleted"`
+	Position    float64   `db:"position"`
+	CreatedOn   time.Time `db:"created_on"`
+	UpdatedOn   time.Time `db:"updated_on"`
+}
+
+// TaskItemPatch is a partial update of a task item, nil fields are left unchanged.
+type TaskItemPatch struct {
+	Title       *string
+	IsCompleted *bool
+}
+
+const (
+	CommentsDefaultLimit = 50
+	CommentsMaxLimit     = 100
+)
+
+// TaskComment is a comment in the discussion of a task.
+//
+// AuthorUsername and AuthorEmail are resolved from the author's account,
+// AuthorID and them are empty when the author has been deleted.
+type TaskComment struct {
+	ID        string     `db:"id"`
+	TaskID    string     `db:"task_id"`
+	AuthorID  string     `db:"author_id"`
+	Content   string     `db:"content"`
+	CreatedOn time.Time  `db:"created_on"`
+	UpdatedOn time.Time  `db:"updated_on"`
+	DeletedOn *time.Time `db:"deleted_on"`
+
+	AuthorUsername string `db:"-"`
+	AuthorEmail    string `db:"-"`
+}
+
+// TaskCommentsQuery describes a page of the task discussion, the oldest comments first.
+//
+// Cursor is an opaque value returned as TaskCommentsPage.NextCursor of the previous page.
+type TaskCommentsQuery struct {
+	UserID string
+	TaskID string
+	Limit  int
+	Cursor string
+}
+
+type TaskCommentsPage struct {
+	Comments   []TaskComment
+	NextCursor string
+}
+
+// ImportMaxRows is the max count of tasks in an imported file.
+const ImportMaxRows = 1000
+
+// TaskImport is a task read from an imported file.
+//
+// Row is the 1-based number of the task in the file, ExternalID is its id in the exporting tool.
+// Err is set if the row could not be read or is invalid, such a row is not imported.
+type TaskImport struct {
+	Row        int
+	ExternalID string
+	Task       Task
+	Err        error
+}
+
+// TaskImportResult is the outcome of importing a single task.
+//
+// A duplicate is a task with the ExternalID of an existing one or with the title
+// and due time of an existing or previously imported one, it is not imported.
+type TaskImportResult struct {
+	Task      Task
+	Duplicate bool
+	Err       error
+}
+
+// TaskAttachment is a file attached to a task, its content is kept in a blob storage under Key.
+//
+// UploaderID is empty when the uploader has been deleted.
+type TaskAttachment struct {
+	ID          string    `db:"id"`
+	TaskID      string    `db:"task_id"`
+	UploaderID  string    `db:"uploader_id"`
+	Filename    string    `db:"filename"`
+	ContentType string    `db:"content_type"`
+	Size        int64     `db:"size"`
+	Key         string    `db:"blob_key"`
 	CreatedOn   time.Time `db:"created_on"`
 }
 
 type StatisticTask struct {
-	Email     string    `db:"email"`
-	Title     string    `db:"title"`
-	CreatedOn time.Time `db:"created_on"`
+	Email     string     `db:"email"`
+	Title     string     `db:"title"`
+	CreatedOn time.Time  `db:"created_on"`
+	DueAt     *time.Time `db:"due_at"`
 }
@@ -1,9 +1,6 @@
 package data
 
 import (
-	"crypto/rsa"
-	"time"
-
 	"github.com/golang-jwt/jwt/v4"
 )
 
@@ -11,6 +8,8 @@ type TokenPayload struct {
 	ID     string
 	UserID string
 	Email  string
+	// FamilyID is shared by the tokens issued by one sign in and its refreshes.
+	FamilyID string
 }
 
 type TokenDetails struct {
@@ -23,11 +22,7 @@ type Claims struct {
 	TokenID string `json:"token_id"`
 	UserID  string `json:"user_id"`
 	Email   string `json:"email"`
+	// FamilyID is empty in the tokens issued before the families were introduced.
+	FamilyID string `json:"family_id,omitempty"`
 	jwt.RegisteredClaims
 }
-
-type RSACredentials struct {
-	PrivateKey *rsa.PrivateKey
-	PublicKey  *rsa.PublicKey
-	TTL        time.Duration
-}
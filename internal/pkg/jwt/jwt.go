@@ -3,6 +3,7 @@ package jwt
 import (
 	"crypto/rsa"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"time"
 
@@ -10,6 +11,9 @@ import (
 	"github.com/romankravchuk/eldorado/internal/data"
 )
 
+var ErrUnknownKey = errors.New("unknown key id")
+
+// CreateToken signs a token, the header carries the id of the key.
 func CreateToken(payload *data.TokenPayload, ttl time.Duration, key *rsa.PrivateKey) (*data.TokenDetails, error) {
 	now := time.Now().UTC()
 	td := &data.TokenDetails{
@@ -18,9 +22,10 @@ func CreateToken(payload *data.TokenPayload, ttl time.Duration, key *rsa.Private
 	}
 
 	claims := data.Claims{
-		TokenID: payload.ID,
-		UserID:  payload.UserID,
-		Email:   payload.Email,
+		TokenID:  payload.ID,
+		UserID:   payload.UserID,
+		Email:    payload.Email,
+		FamilyID: payload.FamilyID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
 			NotBefore: jwt.NewNumericDate(now),
@@ -31,7 +36,10 @@ func CreateToken(payload *data.TokenPayload, ttl time.Duration, key *rsa.Private
 
 	var err error
 
-	td.Token, err = jwt.NewWithClaims(jwt.SigningMethodRS256, &claims).SignedString(key)
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, &claims)
+	token.Header["kid"] = KeyID(&key.PublicKey)
+
+	td.Token, err = token.SignedString(key)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create token: %w", err)
 	}
@@ -39,7 +47,47 @@ func CreateToken(payload *data.TokenPayload, ttl time.Duration, key *rsa.Private
 	return td, nil
 }
 
-func ValidateToken(token string, key *rsa.PublicKey) (*data.TokenPayload, error) {
+// ValidateToken verifies a token with the key of the id from its header.
+// The tokens issued before the key ids were introduced are tried with every key.
+func ValidateToken(token string, keys ...*rsa.PublicKey) (*data.TokenPayload, error) {
+	var noKeyID bool
+
+	claims, err := parseToken(token, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		if kid == "" {
+			noKeyID = true
+			return nil, ErrUnknownKey
+		}
+
+		for _, key := range keys {
+			if KeyID(key) == kid {
+				return key, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	})
+	if noKeyID {
+		for _, key := range keys {
+			if claims, err = parseToken(token, func(*jwt.Token) (interface{}, error) { return key, nil }); err == nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	payload := &data.TokenPayload{
+		ID:       claims.TokenID,
+		UserID:   claims.UserID,
+		Email:    claims.Email,
+		FamilyID: claims.FamilyID,
+	}
+
+	return payload, nil
+}
+
+func parseToken(token string, keyFunc jwt.Keyfunc) (*data.Claims, error) {
 	parsedToken, err := jwt.ParseWithClaims(
 		token,
 		&data.Claims{},
@@ -47,7 +95,7 @@ func ValidateToken(token string, key *rsa.PublicKey) (*data.TokenPayload, error)
 			if _, ok := t.Method.(*jwt.SigningMethodRSA); !ok {
 				return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
 			}
-			return key, nil
+			return keyFunc(t)
 		},
 	)
 	if err != nil {
@@ -59,13 +107,7 @@ func ValidateToken(token string, key *rsa.PublicKey) (*data.TokenPayload, error)
 		return nil, fmt.Errorf("invalid claims")
 	}
 
-	payload := &data.TokenPayload{
-		ID:     claims.TokenID,
-		UserID: claims.UserID,
-		Email:  claims.Email,
-	}
-
-	return payload, nil
+	return claims, nil
 }
 
 func ParseKeyPairs(strPem, strPub string) (*rsa.PrivateKey, *rsa.PublicKey, error) {
@@ -91,3 +133,12 @@ func ParseKeyPairs(strPem, strPub string) (*rsa.PrivateKey, *rsa.PublicKey, erro
 
 	return pem, pub, nil
 }
+
+func ParsePrivateKey(strPem string) (*rsa.PrivateKey, error) {
+	decodedPem, err := base64.StdEncoding.DecodeString(strPem)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode private key: %w", err)
+	}
+
+	return jwt.ParseRSAPrivateKeyFromPEM(decodedPem)
+}
@@ -0,0 +1,81 @@
+package jwt
+
+import (
+	"crypto/rsa"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Keyring holds the keys of a kind of tokens scheduled for rotation.
+//
+// A key signs the tokens from its activation until the activation of the next key,
+// then it verifies them for the overlap window, while the tokens it signed live.
+// The keys scheduled for later verify tokens already, so the clients caching them
+// know the next key before it signs anything.
+type Keyring struct {
+	overlap time.Duration
+
+	mu   sync.RWMutex
+	keys []ringKey
+}
+
+type ringKey struct {
+	private    *rsa.PrivateKey
+	activeFrom time.Time
+}
+
+func NewKeyring(overlap time.Duration) *Keyring {
+	return &Keyring{overlap: overlap}
+}
+
+// Add schedules a key to sign the tokens from a given time.
+func (k *Keyring) Add(key *rsa.PrivateKey, activeFrom time.Time) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.keys = append(k.keys, ringKey{private: key, activeFrom: activeFrom})
+	slices.SortStableFunc(k.keys, func(a, b ringKey) int {
+		return a.activeFrom.Compare(b.activeFrom)
+	})
+}
+
+// SigningKey returns the latest active key, it is nil while no key is active.
+func (k *Keyring) SigningKey() *rsa.PrivateKey {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if i := k.active(time.Now()); i >= 0 {
+		return k.keys[i].private
+	}
+	return nil
+}
+
+// VerificationKeys returns the public keys of the active key, the retired keys
+// within the overlap window and the keys scheduled for later.
+func (k *Keyring) VerificationKeys() []*rsa.PublicKey {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	now := time.Now()
+	active := k.active(now)
+
+	var keys []*rsa.PublicKey
+	for i, key := range k.keys {
+		// a key retires when the next one activates.
+		if i < active && now.Sub(k.keys[i+1].activeFrom) >= k.overlap {
+			continue
+		}
+		keys = append(keys, &key.private.PublicKey)
+	}
+	return keys
+}
+
+// active returns the index of the latest key activated by now, -1 if there is none.
+func (k *Keyring) active(now time.Time) int {
+	i := len(k.keys) - 1
+	for i >= 0 && k.keys[i].activeFrom.After(now) {
+		i--
+	}
+	return i
+}
@@ -0,0 +1,178 @@
+// Package recurrence computes the occurrences of recurring tasks.
+//
+// A rule is one of the keywords daily, weekly and monthly, which repeat a task
+// at the wall clock time of its due date, or a standard cron expression parsed
+// the same way as the statistic schedule. A rule may start with a TZ=<IANA name>
+// (or CRON_TZ=<IANA name>) prefix selecting the time zone the wall clock time is
+// kept in, otherwise the rule is evaluated in UTC.
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	Daily   = "daily"
+	Weekly  = "weekly"
+	Monthly = "monthly"
+
+	// MaxLength is the max length of a rule.
+	MaxLength = 100
+)
+
+var (
+	ErrInvalidRule = errors.New("the recurrence rule is invalid")
+	ErrNoNext      = errors.New("the recurrence rule has no next occurrence")
+)
+
+// maxSteps limits the occurrences skipped by Next, so a rule which never
+// reaches a given time does not loop forever.
+const maxSteps = 100_000
+
+// Validate reports whether a given rule can be parsed.
+func Validate(rule string) error {
+	_, err := parse(rule)
+	return err
+}
+
+// Next returns the first occurrence of a rule after prev which is also later than now.
+//
+// Keyword rules count occurrences from start, the due date of the first task of the series,
+// so the day of a monthly series does not drift after short months. A zero start means prev.
+// Occurrences between prev and now are skipped, so completing an overdue task
+// does not create another overdue one.
+//
+// Keyword occurrences keep the wall clock time of start in the rule time zone across
+// daylight saving time changes, a time falling into the gap is moved forward by
+// the length of the gap. A monthly occurrence on a day missing in a month is moved
+// to the last day of that month.
+func Next(rule string, start, prev, now time.Time) (time.Time, error) {
+	schedule, err := parse(rule)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if prev.Before(now) {
+		prev = now
+	}
+
+	s, ok := schedule.(keyword)
+	if !ok {
+		next := schedule.Next(prev)
+		if next.IsZero() {
+			return time.Time{}, ErrNoNext
+		}
+		return next.UTC(), nil
+	}
+
+	if start.IsZero() {
+		start = prev
+	}
+
+	for k := 1; k <= maxSteps; k++ {
+		if next := s.occurrence(start, k); next.After(prev) {
+			return next.UTC(), nil
+		}
+	}
+
+	return time.Time{}, ErrNoNext
+}
+
+// parse parses a given rule.
+func parse(rule string) (cron.Schedule, error) {
+	rule = strings.TrimSpace(rule)
+	if rule == "" || len(rule) > MaxLength {
+		return nil, ErrInvalidRule
+	}
+
+	loc, body := time.UTC, rule
+	if strings.HasPrefix(rule, "TZ=") || strings.HasPrefix(rule, "CRON_TZ=") {
+		tz, rest, _ := strings.Cut(rule, " ")
+		_, name, _ := strings.Cut(tz, "=")
+
+		var err error
+		if loc, err = time.LoadLocation(name); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidRule, err)
+		}
+		body = strings.TrimSpace(rest)
+	}
+
+	switch body {
+	case Daily:
+		return keyword{loc: loc, days: 1}, nil
+	case Weekly:
+		return keyword{loc: loc, days: 7}, nil
+	case Monthly:
+		return keyword{loc: loc, months: 1}, nil
+	}
+
+	schedule, err := cron.ParseStandard(rule)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRule, err)
+	}
+
+	return schedule, nil
+}
+
+// keyword is a schedule of a keyword rule repeating every given number of days or months
+// at the same wall clock time in loc.
+type keyword struct {
+	loc    *time.Location
+	days   int
+	months int
+}
+
+// Next implements cron.Schedule counting occurrences from t.
+func (s keyword) Next(t time.Time) time.Time {
+	return s.occurrence(t, 1)
+}
+
+// occurrence returns the k-th occurrence after start.
+func (s keyword) occurrence(start time.Time, k int) time.Time {
+	start = start.In(s.loc)
+	year, month, day := start.Date()
+
+	month += time.Month(s.months * k)
+	if last := daysIn(year, month); day > last {
+		day = last
+	}
+	day += s.days * k
+
+	return inLocation(time.Date(year, month, day, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), time.UTC), s.loc)
+}
+
+// inLocation returns the time of a given wall clock in loc.
+//
+// A wall clock repeated when clocks go back is the first of its two times,
+// a wall clock skipped when clocks go forward is moved forward by the length of the gap.
+func inLocation(wall time.Time, loc *time.Location) time.Time {
+	t := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), loc)
+
+	_, before := t.Add(-12 * time.Hour).Zone()
+	_, after := t.Add(12 * time.Hour).Zone()
+
+	for _, offset := range []int{before, after} {
+		if c := wall.Add(-time.Duration(offset) * time.Second).In(loc); sameWallClock(c, wall) {
+			return c
+		}
+	}
+
+	return wall.Add(-time.Duration(before) * time.Second).In(loc)
+}
+
+// sameWallClock reports whether two times show the same date and clock in their locations.
+func sameWallClock(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd && a.Hour() == b.Hour() && a.Minute() == b.Minute() && a.Second() == b.Second()
+}
+
+// daysIn returns the number of days in a given month, month may be out of its usual range.
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
@@ -0,0 +1,114 @@
+package recurrence_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/romankravchuk/eldorado/internal/pkg/recurrence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNext(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	utc := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time
+		prev  time.Time
+		want  time.Time
+	}{
+		{
+			name:  "daily before spring forward keeps wall clock",
+			rule:  "TZ=America/New_York daily",
+			start: time.Date(2024, time.March, 9, 9, 0, 0, 0, ny),
+			want:  time.Date(2024, time.March, 10, 9, 0, 0, 0, ny),
+		},
+		{
+			name:  "daily in spring forward gap moves forward",
+			rule:  "TZ=America/New_York daily",
+			start: time.Date(2024, time.March, 9, 2, 30, 0, 0, ny),
+			want:  utc(2024, time.March, 10, 7, 30), // 03:30 EDT
+		},
+		{
+			name:  "daily after spring forward gap returns to wall clock",
+			rule:  "TZ=America/New_York daily",
+			start: time.Date(2024, time.March, 9, 2, 30, 0, 0, ny),
+			prev:  utc(2024, time.March, 10, 7, 30),
+			want:  time.Date(2024, time.March, 11, 2, 30, 0, 0, ny),
+		},
+		{
+			name:  "daily in fall back overlap takes first time",
+			rule:  "TZ=America/New_York daily",
+			start: time.Date(2024, time.November, 2, 1, 30, 0, 0, ny),
+			want:  utc(2024, time.November, 3, 5, 30), // 01:30 EDT
+		},
+		{
+			name:  "daily after fall back overlap keeps wall clock",
+			rule:  "TZ=America/New_York daily",
+			start: time.Date(2024, time.November, 2, 1, 30, 0, 0, ny),
+			prev:  utc(2024, time.November, 3, 5, 30),
+			want:  utc(2024, time.November, 4, 6, 30), // 01:30 EST
+		},
+		{
+			name:  "weekly across fall back keeps wall clock",
+			rule:  "TZ=America/New_York weekly",
+			start: time.Date(2024, time.October, 28, 9, 0, 0, 0, ny),
+			want:  time.Date(2024, time.November, 4, 9, 0, 0, 0, ny),
+		},
+		{
+			name:  "monthly Jan 31 to Feb 28",
+			rule:  "monthly",
+			start: utc(2023, time.January, 31, 10, 0),
+			want:  utc(2023, time.February, 28, 10, 0),
+		},
+		{
+			name:  "monthly Jan 31 to Feb 29 in leap year",
+			rule:  "monthly",
+			start: utc(2024, time.January, 31, 10, 0),
+			want:  utc(2024, time.February, 29, 10, 0),
+		},
+		{
+			name:  "monthly after Feb 28 returns to Mar 31",
+			rule:  "monthly",
+			start: utc(2023, time.January, 31, 10, 0),
+			prev:  utc(2023, time.February, 28, 10, 0),
+			want:  utc(2023, time.March, 31, 10, 0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prev := tt.prev
+			if prev.IsZero() {
+				prev = tt.start
+			}
+
+			got, err := recurrence.Next(tt.rule, tt.start, prev, prev)
+			require.NoError(t, err)
+			assert.True(t, tt.want.Equal(got), "want %s, got %s", tt.want.UTC(), got)
+		})
+	}
+}
+
+func TestNextSkipsOverdueOccurrences(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	now := time.Date(2024, time.January, 10, 12, 0, 0, 0, time.UTC)
+
+	got, err := recurrence.Next(recurrence.Daily, start, start, now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, time.January, 11, 9, 0, 0, 0, time.UTC), got)
+}
+
+func TestNextInvalidRule(t *testing.T) {
+	now := time.Now()
+
+	_, err := recurrence.Next("fortnightly", now, now, now)
+	assert.ErrorIs(t, err, recurrence.ErrInvalidRule)
+}
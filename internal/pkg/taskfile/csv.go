@@ -0,0 +1,200 @@
+package taskfile
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/romankravchuk/eldorado/internal/data"
+)
+
+// csvHeader are the columns of written files, read files may have them in any order.
+var csvHeader = []string{"id", "title", "description", "is_completed", "priority", "due_at", "remind_at", "recurrence", "project_id", "labels", "created_at"}
+
+// csvLabelsSep separates label names in the labels column.
+const csvLabelsSep = ";"
+
+type csvWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (w *csvWriter) Write(t data.Task) error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+
+	r := newRecord(t)
+
+	return w.w.Write([]string{
+		r.ID,
+		escapeFormula(r.Title),
+		escapeFormula(r.Description),
+		strconv.FormatBool(r.IsCompleted),
+		r.Priority,
+		formatTime(r.DueAt),
+		formatTime(r.RemindAt),
+		escapeFormula(r.Recurrence),
+		stringValue(r.ProjectID),
+		escapeFormula(strings.Join(r.Labels, csvLabelsSep)),
+		formatTime(r.CreatedAt),
+	})
+}
+
+func (w *csvWriter) Close() error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+
+	w.w.Flush()
+	return w.w.Error()
+}
+
+func (w *csvWriter) writeHeader() error {
+	if w.header {
+		return nil
+	}
+	w.header = true
+
+	return w.w.Write(csvHeader)
+}
+
+// readCSV reads records after a header row naming their columns, the title column is required.
+//
+// A record with a wrong count of fields or a malformed value fails its row only.
+func readCSV(r io.Reader, maxRows int) ([]data.TaskImport, error) {
+	cr := csv.NewReader(r)
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("the file must start with a header row: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, errors.New("the header must have a title column")
+	}
+
+	var rows []data.TaskImport
+	for {
+		fields, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+
+		if len(rows) == maxRows {
+			return nil, tooManyRows(maxRows)
+		}
+		row := len(rows) + 1
+
+		if err != nil {
+			if !errors.Is(err, csv.ErrFieldCount) {
+				return nil, err
+			}
+
+			rows = append(rows, data.TaskImport{Row: row, Err: errors.New("wrong number of fields")})
+			continue
+		}
+
+		rec, err := csvRecord(fields, columns)
+		if err != nil {
+			rows = append(rows, data.TaskImport{Row: row, Err: err})
+			continue
+		}
+
+		rows = append(rows, rec.taskImport(row))
+	}
+}
+
+func csvRecord(fields []string, columns map[string]int) (record, error) {
+	get := func(name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(fields[i])
+		}
+		return ""
+	}
+
+	rec := record{
+		ID:          get("id"),
+		Title:       unescapeFormula(get("title")),
+		Description: unescapeFormula(get("description")),
+		Priority:    get("priority"),
+		Recurrence:  unescapeFormula(get("recurrence")),
+	}
+
+	if v := get("is_completed"); v != "" {
+		completed, err := strconv.ParseBool(v)
+		if err != nil {
+			return record{}, errors.New("is_completed must be true or false")
+		}
+		rec.IsCompleted = completed
+	}
+
+	var err error
+	if rec.DueAt, err = parseTime("due_at", get("due_at")); err != nil {
+		return record{}, err
+	}
+	if rec.RemindAt, err = parseTime("remind_at", get("remind_at")); err != nil {
+		return record{}, err
+	}
+
+	if v := get("project_id"); v != "" {
+		rec.ProjectID = &v
+	}
+
+	return rec, nil
+}
+
+// escapeFormula prefixes a value which a spreadsheet would run as a formula with a quote.
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// unescapeFormula removes the quote added by escapeFormula.
+func unescapeFormula(s string) string {
+	if len(s) > 1 && s[0] == '\'' && strings.ContainsRune("=+-@\t\r", rune(s[1])) {
+		return s[1:]
+	}
+	return s
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func parseTime(name, s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an RFC 3339 time", name)
+	}
+
+	return &t, nil
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
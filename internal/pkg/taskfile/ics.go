@@ -0,0 +1,513 @@
+package taskfile
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/pkg/recurrence"
+)
+
+const (
+	icsDateTime    = "20060102T150405Z"
+	icsLocalTime   = "20060102T150405"
+	icsDate        = "20060102"
+	icsLineOctets  = 75
+	icsProductID   = "-//eldorado//tasks//EN"
+	icsRecurrence  = "X-ELDORADO-RECURRENCE"
+	icsProjectID   = "X-ELDORADO-PROJECT-ID"
+	icsMaxLineSize = 1 << 20
+)
+
+// icsPriorities maps task priorities to iCalendar ones, 1 is the highest.
+var icsPriorities = map[string]int{
+	data.PriorityUrgent: 1,
+	data.PriorityHigh:   3,
+	data.PriorityNormal: 5,
+	data.PriorityLow:    9,
+}
+
+// icsWriter writes tasks as VTODO components of a calendar.
+//
+// Recurrence rules are kept in an X-ELDORADO-RECURRENCE property, keyword rules
+// in UTC are also written as RRULE for other tools.
+type icsWriter struct {
+	w       io.Writer
+	now     time.Time
+	started bool
+	err     error
+}
+
+func (w *icsWriter) Write(t data.Task) error {
+	w.start()
+
+	r := newRecord(t)
+
+	w.line("BEGIN:VTODO")
+	w.line("UID:" + r.ID)
+	w.line("DTSTAMP:" + w.now.Format(icsDateTime))
+	if r.CreatedAt != nil {
+		w.line("CREATED:" + r.CreatedAt.Format(icsDateTime))
+	}
+	w.line("SUMMARY:" + escapeText(r.Title))
+	if r.Description != "" {
+		w.line("DESCRIPTION:" + escapeText(r.Description))
+	}
+	if r.IsCompleted {
+		w.line("STATUS:COMPLETED")
+	} else {
+		w.line("STATUS:NEEDS-ACTION")
+	}
+	if p, ok := icsPriorities[r.Priority]; ok {
+		w.line("PRIORITY:" + strconv.Itoa(p))
+	}
+	if r.DueAt != nil {
+		w.line("DUE:" + r.DueAt.Format(icsDateTime))
+	}
+	if r.Recurrence != "" {
+		switch r.Recurrence {
+		case recurrence.Daily, recurrence.Weekly, recurrence.Monthly:
+			w.line("RRULE:FREQ=" + strings.ToUpper(r.Recurrence))
+		}
+		w.line(icsRecurrence + ":" + escapeText(r.Recurrence))
+	}
+	if r.ProjectID != nil {
+		w.line(icsProjectID + ":" + *r.ProjectID)
+	}
+	if len(r.Labels) > 0 {
+		names := make([]string, len(r.Labels))
+		for i, name := range r.Labels {
+			names[i] = escapeText(name)
+		}
+		w.line("CATEGORIES:" + strings.Join(names, ","))
+	}
+	if r.RemindAt != nil {
+		w.line("BEGIN:VALARM")
+		w.line("ACTION:DISPLAY")
+		w.line("DESCRIPTION:" + escapeText(r.Title))
+		w.line("TRIGGER;VALUE=DATE-TIME:" + r.RemindAt.Format(icsDateTime))
+		w.line("END:VALARM")
+	}
+	w.line("END:VTODO")
+
+	return w.err
+}
+
+func (w *icsWriter) Close() error {
+	w.start()
+	w.line("END:VCALENDAR")
+
+	return w.err
+}
+
+func (w *icsWriter) start() {
+	if w.started {
+		return
+	}
+	w.started = true
+
+	w.line("BEGIN:VCALENDAR")
+	w.line("VERSION:2.0")
+	w.line("PRODID:" + icsProductID)
+}
+
+// line writes a content line folded into lines of at most 75 octets.
+func (w *icsWriter) line(s string) {
+	if w.err != nil {
+		return
+	}
+
+	var b strings.Builder
+	limit := icsLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		// a continuation line starts with a space.
+		limit = icsLineOctets - 1
+	}
+	b.WriteString(s)
+	b.WriteString("\r\n")
+
+	_, w.err = io.WriteString(w.w, b.String())
+}
+
+// icsProperty is a content line of a calendar.
+type icsProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// readICS reads VTODO components of a calendar, other components are skipped.
+func readICS(r io.Reader, maxRows int) ([]data.TaskImport, error) {
+	lines, err := unfoldLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		rows         []data.TaskImport
+		todo         []icsProperty
+		alarm        []icsProperty
+		seenCalendar bool
+		inCalendar   bool
+		inTodo       bool
+		inAlarm      bool
+		depth        int // depth of skipped components
+	)
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		p, err := parseProperty(line)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case !inCalendar:
+			if p.name != "BEGIN" || !strings.EqualFold(p.value, "VCALENDAR") {
+				return nil, errors.New("the file must be an iCalendar VCALENDAR")
+			}
+			seenCalendar, inCalendar = true, true
+		case depth > 0:
+			if p.name == "BEGIN" {
+				depth++
+			} else if p.name == "END" {
+				depth--
+			}
+		case p.name == "BEGIN" && strings.EqualFold(p.value, "VTODO") && !inTodo:
+			if len(rows) == maxRows {
+				return nil, tooManyRows(maxRows)
+			}
+			inTodo, todo, alarm = true, nil, nil
+		case p.name == "BEGIN" && strings.EqualFold(p.value, "VALARM") && inTodo && !inAlarm && alarm == nil:
+			// the first alarm of a task is its reminder, the others are skipped.
+			inAlarm, alarm = true, []icsProperty{}
+		case p.name == "BEGIN":
+			depth++
+		case p.name == "END" && inAlarm:
+			inAlarm = false
+		case p.name == "END" && inTodo:
+			inTodo = false
+			rows = append(rows, icsTodo(len(rows)+1, todo, alarm))
+		case p.name == "END":
+			inCalendar = false
+		case inAlarm:
+			alarm = append(alarm, p)
+		case inTodo:
+			todo = append(todo, p)
+		}
+	}
+
+	if !seenCalendar {
+		return nil, errors.New("the file must be an iCalendar VCALENDAR")
+	}
+	if inCalendar {
+		return nil, errors.New("the calendar is not complete")
+	}
+
+	return rows, nil
+}
+
+// icsTodo converts the properties of a VTODO and its alarm to a task.
+func icsTodo(row int, todo, alarm []icsProperty) data.TaskImport {
+	var (
+		rec     record
+		rrule   string
+		trigger *icsProperty
+	)
+	for _, p := range alarm {
+		if p.name == "TRIGGER" {
+			p := p
+			trigger = &p
+		}
+	}
+
+	fail := func(err error) data.TaskImport {
+		return data.TaskImport{Row: row, ExternalID: rec.ID, Err: err}
+	}
+
+	for _, p := range todo {
+		var err error
+		switch p.name {
+		case "UID":
+			rec.ID = p.value
+		case "SUMMARY":
+			rec.Title = unescapeText(p.value)
+		case "DESCRIPTION":
+			rec.Description = unescapeText(p.value)
+		case "STATUS":
+			rec.IsCompleted = rec.IsCompleted || strings.EqualFold(p.value, "COMPLETED")
+		case "COMPLETED":
+			rec.IsCompleted = true
+		case "PRIORITY":
+			if rec.Priority, err = icsPriority(p.value); err != nil {
+				return fail(err)
+			}
+		case "DUE":
+			if rec.DueAt, err = icsTime(p); err != nil {
+				return fail(fmt.Errorf("DUE: %w", err))
+			}
+		case "RRULE":
+			rrule = p.value
+		case icsRecurrence:
+			rec.Recurrence = unescapeText(p.value)
+		case icsProjectID:
+			id := p.value
+			rec.ProjectID = &id
+		}
+	}
+
+	if rec.Recurrence == "" && rrule != "" {
+		rule, err := icsRRule(rrule)
+		if err != nil {
+			return fail(err)
+		}
+		rec.Recurrence = rule
+	}
+
+	if trigger != nil {
+		remindAt, err := icsTrigger(*trigger, rec.DueAt)
+		if err != nil {
+			return fail(fmt.Errorf("TRIGGER: %w", err))
+		}
+		rec.RemindAt = remindAt
+	}
+
+	return rec.taskImport(row)
+}
+
+// unfoldLines splits a calendar into content lines joining folded ones.
+func unfoldLines(r io.Reader) ([]string, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), icsMaxLineSize)
+
+	var lines []string
+	for sc.Scan() {
+		line := strings.TrimSuffix(sc.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, sc.Err()
+}
+
+// parseProperty parses a content line NAME;PARAM=VALUE:VALUE, names are upper cased.
+func parseProperty(line string) (icsProperty, error) {
+	colon, quoted := -1, false
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return icsProperty{}, fmt.Errorf("malformed calendar line %q", line)
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	p := icsProperty{
+		name:   strings.ToUpper(parts[0]),
+		params: make(map[string]string, len(parts)-1),
+		value:  line[colon+1:],
+	}
+	for _, param := range parts[1:] {
+		name, value, _ := strings.Cut(param, "=")
+		p.params[strings.ToUpper(name)] = strings.Trim(value, `"`)
+	}
+
+	return p, nil
+}
+
+// icsTime parses a DATE or DATE-TIME value, a local time is read in its TZID zone or UTC.
+func icsTime(p icsProperty) (*time.Time, error) {
+	if strings.EqualFold(p.params["VALUE"], "DATE") || len(p.value) == len(icsDate) {
+		t, err := time.Parse(icsDate, p.value)
+		if err != nil {
+			return nil, err
+		}
+		return &t, nil
+	}
+
+	if strings.HasSuffix(p.value, "Z") {
+		t, err := time.Parse(icsDateTime, p.value)
+		if err != nil {
+			return nil, err
+		}
+		return &t, nil
+	}
+
+	loc := time.UTC
+	if tzid := p.params["TZID"]; tzid != "" {
+		var err error
+		if loc, err = time.LoadLocation(tzid); err != nil {
+			return nil, fmt.Errorf("unknown time zone %q", tzid)
+		}
+	}
+
+	t, err := time.ParseInLocation(icsLocalTime, p.value, loc)
+	if err != nil {
+		return nil, err
+	}
+	t = t.UTC()
+
+	return &t, nil
+}
+
+// icsTrigger returns the reminder time of an alarm, a relative trigger is counted from the due time.
+//
+// A relative trigger of a task without a due time has no reminder.
+func icsTrigger(p icsProperty, dueAt *time.Time) (*time.Time, error) {
+	if strings.EqualFold(p.params["VALUE"], "DATE-TIME") {
+		return icsTime(p)
+	}
+
+	d, err := icsDuration(p.value)
+	if err != nil {
+		return nil, err
+	}
+
+	if dueAt == nil {
+		return nil, nil
+	}
+
+	t := dueAt.Add(d)
+	return &t, nil
+}
+
+// icsDuration parses a duration like -PT15M or P1DT2H.
+func icsDuration(s string) (time.Duration, error) {
+	invalid := fmt.Errorf("invalid duration %q", s)
+
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(s, "-"):
+		sign, s = -1, s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	if !strings.HasPrefix(s, "P") || len(s) < 3 {
+		return 0, invalid
+	}
+	s = s[1:]
+
+	var (
+		d      time.Duration
+		inTime bool
+		num    string
+	)
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			num += string(r)
+		case r == 'T' && !inTime && num == "":
+			inTime = true
+		default:
+			n, err := strconv.Atoi(num)
+			if err != nil {
+				return 0, invalid
+			}
+			num = ""
+
+			switch {
+			case r == 'W' && !inTime:
+				d += time.Duration(n) * 7 * 24 * time.Hour
+			case r == 'D' && !inTime:
+				d += time.Duration(n) * 24 * time.Hour
+			case r == 'H' && inTime:
+				d += time.Duration(n) * time.Hour
+			case r == 'M' && inTime:
+				d += time.Duration(n) * time.Minute
+			case r == 'S' && inTime:
+				d += time.Duration(n) * time.Second
+			default:
+				return 0, invalid
+			}
+		}
+	}
+	if num != "" {
+		return 0, invalid
+	}
+
+	return sign * d, nil
+}
+
+// icsPriority maps an iCalendar priority to a task one, 0 means undefined.
+func icsPriority(s string) (string, error) {
+	p, err := strconv.Atoi(strings.TrimSpace(s))
+	switch {
+	case err != nil || p < 0 || p > 9:
+		return "", fmt.Errorf("invalid PRIORITY %q", s)
+	case p == 0:
+		return "", nil
+	case p <= 2:
+		return data.PriorityUrgent, nil
+	case p <= 4:
+		return data.PriorityHigh, nil
+	case p == 5:
+		return data.PriorityNormal, nil
+	default:
+		return data.PriorityLow, nil
+	}
+}
+
+// icsRRule converts a simple RRULE repeating every day, week or month to a keyword rule.
+func icsRRule(s string) (string, error) {
+	var freq string
+	for _, part := range strings.Split(s, ";") {
+		name, value, _ := strings.Cut(part, "=")
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			freq = strings.ToUpper(value)
+		case "INTERVAL":
+			if value != "1" {
+				return "", fmt.Errorf("unsupported RRULE %q", s)
+			}
+		default:
+			return "", fmt.Errorf("unsupported RRULE %q", s)
+		}
+	}
+
+	switch freq {
+	case "DAILY":
+		return recurrence.Daily, nil
+	case "WEEKLY":
+		return recurrence.Weekly, nil
+	case "MONTHLY":
+		return recurrence.Monthly, nil
+	default:
+		return "", fmt.Errorf("unsupported RRULE %q", s)
+	}
+}
+
+var (
+	textEscaper   = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	textUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+)
+
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+func unescapeText(s string) string {
+	return textUnescaper.Replace(s)
+}
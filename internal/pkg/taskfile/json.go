@@ -0,0 +1,98 @@
+package taskfile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/romankravchuk/eldorado/internal/data"
+)
+
+// jsonWriter writes tasks as elements of a JSON array, one per line.
+type jsonWriter struct {
+	w       io.Writer
+	written bool
+}
+
+func (w *jsonWriter) Write(t data.Task) error {
+	raw, err := json.Marshal(newRecord(t))
+	if err != nil {
+		return err
+	}
+
+	sep := ",\n"
+	if !w.written {
+		sep = "[\n"
+		w.written = true
+	}
+
+	if _, err := io.WriteString(w.w, sep); err != nil {
+		return err
+	}
+
+	_, err = w.w.Write(raw)
+	return err
+}
+
+func (w *jsonWriter) Close() error {
+	end := "\n]\n"
+	if !w.written {
+		end = "[]\n"
+	}
+
+	_, err := io.WriteString(w.w, end)
+	return err
+}
+
+// readJSON reads an array of task objects, a malformed value fails its row only.
+func readJSON(r io.Reader, maxRows int) ([]data.TaskImport, error) {
+	dec := json.NewDecoder(r)
+
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return nil, errors.New("the file must be a JSON array of tasks")
+	}
+
+	var rows []data.TaskImport
+	for dec.More() {
+		if len(rows) == maxRows {
+			return nil, tooManyRows(maxRows)
+		}
+
+		var rec record
+		err := dec.Decode(&rec)
+
+		// the decoder reads a whole value before unmarshaling it,
+		// so only a syntax error leaves the stream broken.
+		var (
+			syntaxErr *json.SyntaxError
+			typeErr   *json.UnmarshalTypeError
+			timeErr   *time.ParseError
+		)
+		switch {
+		case err == nil:
+			rows = append(rows, rec.taskImport(len(rows)+1))
+		case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+			return nil, fmt.Errorf("task %d: %w", len(rows)+1, err)
+		case errors.As(err, &typeErr):
+			rows = append(rows, data.TaskImport{
+				Row: len(rows) + 1,
+				Err: fmt.Errorf("%s must be %s", typeErr.Field, typeErr.Type),
+			})
+		case errors.As(err, &timeErr):
+			rows = append(rows, data.TaskImport{
+				Row: len(rows) + 1,
+				Err: fmt.Errorf("invalid time %s, must be RFC 3339", timeErr.Value),
+			})
+		default:
+			rows = append(rows, data.TaskImport{Row: len(rows) + 1, Err: err})
+		}
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,141 @@
+// Package taskfile reads and writes tasks in the files of other tools.
+//
+// Supported formats are a JSON array, CSV with a header row and iCalendar VTODO
+// components. All formats carry the same fields, so a written file can be read back.
+// Labels are written by names and ignored when read.
+package taskfile
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/romankravchuk/eldorado/internal/data"
+)
+
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	FormatICS  = "ics"
+)
+
+var ErrUnknownFormat = errors.New("the task file format is unknown")
+
+// Writer writes tasks to a file, Close completes the file.
+type Writer interface {
+	Write(t data.Task) error
+	Close() error
+}
+
+// NewWriter returns a writer of a given format.
+func NewWriter(w io.Writer, format string) (Writer, error) {
+	switch format {
+	case FormatJSON:
+		return &jsonWriter{w: w}, nil
+	case FormatCSV:
+		return newCSVWriter(w), nil
+	case FormatICS:
+		return &icsWriter{w: w, now: time.Now().UTC()}, nil
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// ContentType returns the media type of a format.
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatICS:
+		return "text/calendar; charset=utf-8"
+	default:
+		return "application/json"
+	}
+}
+
+// Read reads tasks of a given format.
+//
+// Errors of single rows, e.g. a malformed date, are set to the rows.
+// A file which cannot be read at all or has more than maxRows rows yields an error.
+func Read(r io.Reader, format string, maxRows int) ([]data.TaskImport, error) {
+	switch format {
+	case FormatJSON:
+		return readJSON(r, maxRows)
+	case FormatCSV:
+		return readCSV(r, maxRows)
+	case FormatICS:
+		return readICS(r, maxRows)
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// record is the set of task fields kept in files.
+type record struct {
+	ID          string     `json:"id,omitempty"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	IsCompleted bool       `json:"is_completed"`
+	Priority    string     `json:"priority,omitempty"`
+	DueAt       *time.Time `json:"due_at,omitempty"`
+	RemindAt    *time.Time `json:"remind_at,omitempty"`
+	Recurrence  string     `json:"recurrence,omitempty"`
+	ProjectID   *string    `json:"project_id,omitempty"`
+	Labels      []string   `json:"labels,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+}
+
+func newRecord(t data.Task) record {
+	createdAt := t.CreatedOn.UTC()
+
+	r := record{
+		ID:          t.ID,
+		Title:       t.Title,
+		Description: t.Description,
+		IsCompleted: t.IsCompleted,
+		Priority:    t.Priority,
+		DueAt:       utc(t.DueAt),
+		RemindAt:    utc(t.RemindAt),
+		Recurrence:  t.Recurrence,
+		ProjectID:   t.ProjectID,
+		CreatedAt:   &createdAt,
+	}
+	for _, l := range t.Labels {
+		r.Labels = append(r.Labels, l.Name)
+	}
+
+	return r
+}
+
+// taskImport converts a record read from the row of a file.
+func (r record) taskImport(row int) data.TaskImport {
+	return data.TaskImport{
+		Row:        row,
+		ExternalID: r.ID,
+		Task: data.Task{
+			Title:       r.Title,
+			Description: r.Description,
+			IsCompleted: r.IsCompleted,
+			Priority:    r.Priority,
+			DueAt:       r.DueAt,
+			RemindAt:    r.RemindAt,
+			Recurrence:  r.Recurrence,
+			ProjectID:   r.ProjectID,
+		},
+	}
+}
+
+// tooManyRows returns the error of a file exceeding maxRows.
+func tooManyRows(maxRows int) error {
+	return fmt.Errorf("the file has more than %d tasks", maxRows)
+}
+
+func utc(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	u := t.UTC()
+	return &u
+}
@@ -0,0 +1,55 @@
+package validator
+
+import (
+	"regexp"
+	"unicode"
+	"unicode/utf8"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	// markdownCode matches fenced code blocks and inline code spans, their content is shown verbatim.
+	markdownCode = regexp.MustCompile("(?s)```.*?```|`[^`\n]*`")
+	// markdownHTML matches raw HTML tags, comments and declarations.
+	markdownHTML = regexp.MustCompile(`<(?:/?[A-Za-z][A-Za-z0-9-]*(?:\s|/?>)|[!?])`)
+	// markdownUnsafeLink matches link destinations and autolinks with scripting or inline data schemes.
+	markdownUnsafeLink = regexp.MustCompile(`(?i)(?:\]\(\s*<?|<|\]:\s*<?)\s*(?:javascript|vbscript|data):`)
+)
+
+// isMarkdown validates that a string is markdown safe to render: valid UTF-8
+// without control characters except tabs and line breaks, raw HTML and links
+// with scripting or data schemes. Code spans and blocks may contain anything printable.
+func isMarkdown(fl validator.FieldLevel) bool {
+	s := fl.Field().String()
+
+	if !utf8.ValidString(s) {
+		return false
+	}
+
+	for _, r := range s {
+		if unicode.IsControl(r) && r != '\n' && r != '\r' && r != '\t' {
+			return false
+		}
+	}
+
+	if markdownUnsafeLink.MatchString(s) {
+		return false
+	}
+
+	return !markdownHTML.MatchString(markdownCode.ReplaceAllString(s, ""))
+}
+
+func registerMarkdown(v *validator.Validate, trans ut.Translator) {
+	_ = v.RegisterValidation("markdown", isMarkdown)
+	_ = v.RegisterTranslation("markdown", trans,
+		func(ut ut.Translator) error {
+			return ut.Add("markdown", "{0} must be markdown without HTML or unsafe links", true)
+		},
+		func(ut ut.Translator, fe validator.FieldError) string {
+			t, _ := ut.T("markdown", fe.Field())
+			return t
+		},
+	)
+}
@@ -22,6 +22,7 @@ func init() {
 	uni = ut.New(en, en)
 	trans, _ = uni.GetTranslator("en")
 	_ = ent.RegisterDefaultTranslations(validate, trans)
+	registerMarkdown(validate, trans)
 }
 
 // ValidateStruct validates the strcut 'v' using the validator instance and
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+// DecodeMergePatch decodes a merge patch object from the request body into T.
+//
+// Returns the set of nullable members which were null in the patch.
+// Unknown members and null values of other members are reported as errors.
+func DecodeMergePatch[T any](r *http.Request, nullable ...string) (T, map[string]bool, error) {
+	var patch T
+
+	var members map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&members); err != nil {
+		return patch, nil, errors.New("the patch must be a json object")
+	}
+
+	nulls := make(map[string]bool)
+	for name, value := range members {
+		if !bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+			continue
+		}
+
+		if !slices.Contains(nullable, name) {
+			return patch, nil, fmt.Errorf("%s cannot be null", name)
+		}
+
+		nulls[name] = true
+		delete(members, name)
+	}
+
+	raw, _ := json.Marshal(members)
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&patch); err != nil {
+		return patch, nil, fmt.Errorf("invalid patch: %w", err)
+	}
+
+	return patch, nulls, nil
+}
@@ -19,3 +19,10 @@ func NotFound(r string) APIError {
 		Message: r + " not found",
 	}
 }
+
+func PreconditionFailed(r string) APIError {
+	return APIError{
+		Status:  http.StatusPreconditionFailed,
+		Message: r + " has been modified",
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"log/slog"
+	"net"
 	"net/http"
 	"time"
 
@@ -127,8 +128,10 @@ func HandleGetTokenPairs(log *slog.Logger, client proto.AuthServiceClient) api.A
 		defer cancel()
 
 		resp, err := client.Token(ctx, &proto.TokenRequest{
-			Email:    input.Email,
-			Password: input.Password,
+			Email:     input.Email,
+			Password:  input.Password,
+			UserAgent: r.UserAgent(),
+			Ip:        clientIP(r),
 		})
 		if err != nil {
 			msg := "internal server error"
@@ -158,6 +161,8 @@ func HandleGetTokenPairs(log *slog.Logger, client proto.AuthServiceClient) api.A
 	}
 }
 
+// HandleRefreshToken rotates the refresh token from the cookie,
+// the response carries the new refresh token replacing it.
 func HandleRefreshToken(log *slog.Logger, client proto.AuthServiceClient) api.APIFunc {
 	return func(w http.ResponseWriter, r *http.Request) error {
 		token, err := r.Cookie("refresh_token")
@@ -176,7 +181,9 @@ func HandleRefreshToken(log *slog.Logger, client proto.AuthServiceClient) api.AP
 		defer cancel()
 
 		resp, err := client.Refresh(ctx, &proto.RefreshRequest{
-			Refresh: token.Value,
+			Refresh:   token.Value,
+			UserAgent: r.UserAgent(),
+			Ip:        clientIP(r),
 		})
 		if err != nil {
 			msg := "internal server error"
@@ -200,7 +207,124 @@ func HandleRefreshToken(log *slog.Logger, client proto.AuthServiceClient) api.AP
 		}
 
 		return response.JSON(w, http.StatusOK, response.M{
-			"access_token": resp.AccessToken,
+			"access_token":  resp.AccessToken,
+			"refresh_token": resp.RefreshToken,
 		})
 	}
 }
+
+// HandleLogout revokes the access token of the request and the refresh token from its cookie, if any.
+func HandleLogout(log *slog.Logger, client proto.AuthServiceClient) api.APIFunc {
+	const op = "server.http.handlers.auth.Logout"
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		access, ok := r.Context().Value(api.TokenKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no token in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		in := &proto.LogoutRequest{Access: access}
+		if cookie, err := r.Cookie("refresh_token"); err == nil {
+			in.Refresh = cookie.Value
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 1*time.Second)
+		defer cancel()
+
+		resp, err := client.Logout(ctx, in)
+		if err != nil {
+			msg := "internal server error"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusInternalServerError,
+				Message: msg,
+			}
+		}
+		if resp.Error != "" {
+			msg := "invalid request"
+
+			log.Error(msg, slog.Any("response", resp))
+
+			return response.APIError{
+				Status:  int(resp.Status),
+				Message: msg,
+			}
+		}
+
+		return response.JSON(w, http.StatusOK, response.M{"message": "ok"})
+	}
+}
+
+// HandleLogoutAll revokes every token of the user of the request.
+func HandleLogoutAll(log *slog.Logger, client proto.AuthServiceClient) api.APIFunc {
+	const op = "server.http.handlers.auth.LogoutAll"
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		access, ok := r.Context().Value(api.TokenKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no token in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 1*time.Second)
+		defer cancel()
+
+		resp, err := client.LogoutAll(ctx, &proto.LogoutAllRequest{Access: access})
+		if err != nil {
+			msg := "internal server error"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusInternalServerError,
+				Message: msg,
+			}
+		}
+		if resp.Error != "" {
+			msg := "invalid request"
+
+			log.Error(msg, slog.Any("response", resp))
+
+			return response.APIError{
+				Status:  int(resp.Status),
+				Message: msg,
+			}
+		}
+
+		return response.JSON(w, http.StatusOK, response.M{"message": "ok"})
+	}
+}
+
+// clientIP returns the address of the client without the port.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/romankravchuk/eldorado/internal/pkg/jwt"
+	"github.com/romankravchuk/eldorado/internal/pkg/sl"
+	"github.com/romankravchuk/eldorado/internal/server/http/api"
+	"github.com/romankravchuk/eldorado/internal/server/http/api/response"
+	"github.com/romankravchuk/eldorado/internal/services/auth/proto"
+)
+
+// HandleJWKS serves the keys verifying access tokens as a JSON Web Key Set.
+func HandleJWKS(log *slog.Logger, client proto.AuthServiceClient) api.APIFunc {
+	const op = "server.http.handlers.auth.JWKS"
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		ctx, cancel := context.WithTimeout(r.Context(), 1*time.Second)
+		defer cancel()
+
+		resp, err := client.JWKS(ctx, &proto.JWKSRequest{})
+		if err != nil {
+			msg := "internal server error"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusInternalServerError,
+				Message: msg,
+			}
+		}
+		if resp.Meta.Error != "" {
+			msg := "internal server error"
+
+			log.Error(msg, slog.Any("response", resp))
+
+			return response.APIError{
+				Status:  http.StatusInternalServerError,
+				Message: msg,
+			}
+		}
+
+		keys := make([]jwt.JWK, len(resp.Keys))
+		for i, key := range resp.Keys {
+			keys[i] = jwt.JWK{
+				Kty: key.Kty,
+				Kid: key.Kid,
+				Use: key.Use,
+				Alg: key.Alg,
+				N:   key.N,
+				E:   key.E,
+			}
+		}
+
+		w.Header().Set("Cache-Control", "public, max-age=300")
+
+		return response.JSON(w, http.StatusOK, response.M{"keys": keys})
+	}
+}
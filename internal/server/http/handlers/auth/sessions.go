@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/romankravchuk/eldorado/internal/pkg/sl"
+	"github.com/romankravchuk/eldorado/internal/server/http/api"
+	"github.com/romankravchuk/eldorado/internal/server/http/api/response"
+	"github.com/romankravchuk/eldorado/internal/services/auth/proto"
+)
+
+// HandleGetSessions lists the devices the user of the request is signed in on.
+func HandleGetSessions(log *slog.Logger, client proto.AuthServiceClient) api.APIFunc {
+	const op = "server.http.handlers.auth.GetSessions"
+
+	type session struct {
+		ID         string `json:"id"`
+		UserAgent  string `json:"user_agent"`
+		IP         string `json:"ip"`
+		CreatedAt  string `json:"created_at"`
+		LastSeenAt string `json:"last_seen_at"`
+		Current    bool   `json:"current"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		access, ok := r.Context().Value(api.TokenKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no token in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 1*time.Second)
+		defer cancel()
+
+		resp, err := client.ListSessions(ctx, &proto.ListSessionsRequest{Access: access})
+		if err != nil {
+			msg := "internal server error"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusInternalServerError,
+				Message: msg,
+			}
+		}
+		if resp.Meta.Error != "" {
+			msg := "invalid request"
+
+			log.Error(msg, slog.Any("response", resp))
+
+			return response.APIError{
+				Status:  int(resp.Meta.Status),
+				Message: msg,
+			}
+		}
+
+		objs := make([]session, len(resp.Sessions))
+		for i, s := range resp.Sessions {
+			objs[i] = session{
+				ID:         s.Id,
+				UserAgent:  s.UserAgent,
+				IP:         s.Ip,
+				CreatedAt:  s.CreatedAt,
+				LastSeenAt: s.LastSeenAt,
+				Current:    s.Current,
+			}
+		}
+
+		return response.JSON(w, http.StatusOK, response.M{"sessions": objs})
+	}
+}
+
+// HandleRevokeSession signs the user of the request out of a device.
+func HandleRevokeSession(log *slog.Logger, client proto.AuthServiceClient) api.APIFunc {
+	const op = "server.http.handlers.auth.RevokeSession"
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		access, ok := r.Context().Value(api.TokenKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no token in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 1*time.Second)
+		defer cancel()
+
+		resp, err := client.RevokeSession(ctx, &proto.RevokeSessionRequest{
+			Access: access,
+			Id:     chi.URLParam(r, "id"),
+		})
+		if err != nil {
+			msg := "internal server error"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusInternalServerError,
+				Message: msg,
+			}
+		}
+		if resp.Error != "" {
+			log.Error("invalid request", slog.Any("response", resp))
+
+			if resp.Status == http.StatusNotFound {
+				return response.NotFound("session")
+			}
+
+			return response.APIError{
+				Status:  int(resp.Status),
+				Message: "invalid request",
+			}
+		}
+
+		return response.JSON(w, http.StatusOK, response.M{"message": "ok"})
+	}
+}
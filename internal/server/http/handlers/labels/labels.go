@@ -0,0 +1,298 @@
+package labels
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/pkg/sl"
+	"github.com/romankravchuk/eldorado/internal/pkg/validator"
+	"github.com/romankravchuk/eldorado/internal/server/http/api"
+	"github.com/romankravchuk/eldorado/internal/server/http/api/response"
+	"github.com/romankravchuk/eldorado/internal/storages/labels"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
+)
+
+// label is a JSON representation of a label.
+type label struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+func newLabel(l data.Label) label {
+	return label{
+		ID:    l.ID,
+		Name:  l.Name,
+		Color: l.Color,
+	}
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name LabelsLister
+type LabelsLister interface {
+	Labels(ctx context.Context, userID string) ([]data.Label, error)
+}
+
+func HandleGetLabels(log *slog.Logger, lister LabelsLister) api.APIFunc {
+	const op = "server.http.handlers.labels.GetLabels"
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 150*time.Millisecond)
+		defer cancel()
+
+		ls, err := lister.Labels(ctx, userID)
+		if err != nil {
+			return labelError(log, err, userID, r)
+		}
+
+		objs := make([]label, len(ls))
+		for i, l := range ls {
+			objs[i] = newLabel(l)
+		}
+
+		return response.JSON(w, http.StatusOK, response.M{
+			"labels": objs,
+		})
+	}
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name LabelCreater
+type LabelCreater interface {
+	CreateLabel(ctx context.Context, userID string, l data.Label) (data.Label, error)
+}
+
+func HandleCreateLabel(log *slog.Logger, creater LabelCreater) api.APIFunc {
+	const op = "server.http.handlers.labels.CreateLabel"
+
+	type req struct {
+		Name  string `json:"name" validate:"required,min=1,max=50"`
+		Color string `json:"color" validate:"omitempty,hexcolor,len=7"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		input := new(req)
+		if err := json.NewDecoder(r.Body).Decode(input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: msg,
+			}
+		}
+
+		if err := validator.ValidateStruct(*input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: err.Error(),
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 150*time.Millisecond)
+		defer cancel()
+
+		l, err := creater.CreateLabel(ctx, userID, data.Label{Name: input.Name, Color: input.Color})
+		if err != nil {
+			return labelError(log, err, userID, r)
+		}
+
+		return response.JSON(w, http.StatusCreated, response.M{
+			"label": newLabel(l),
+		})
+	}
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name LabelPatcher
+type LabelPatcher interface {
+	PatchLabel(ctx context.Context, userID, id string, p data.LabelPatch) (data.Label, error)
+}
+
+// HandlePatchLabel applies a JSON Merge Patch (RFC 7396) to a label.
+func HandlePatchLabel(log *slog.Logger, patcher LabelPatcher) api.APIFunc {
+	const op = "server.http.handlers.labels.PatchLabel"
+
+	type req struct {
+		Name  *string `json:"name" validate:"omitempty,min=1,max=50"`
+		Color *string `json:"color" validate:"omitempty,hexcolor,len=7"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		input, _, err := api.DecodeMergePatch[req](r)
+		if err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: err.Error(),
+			}
+		}
+
+		if err := validator.ValidateStruct(input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: err.Error(),
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 150*time.Millisecond)
+		defer cancel()
+
+		l, err := patcher.PatchLabel(ctx, userID, chi.URLParam(r, "labelID"), data.LabelPatch{
+			Name:  input.Name,
+			Color: input.Color,
+		})
+		if err != nil {
+			return labelError(log, err, userID, r)
+		}
+
+		return response.JSON(w, http.StatusOK, response.M{
+			"label": newLabel(l),
+		})
+	}
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name LabelDeleter
+type LabelDeleter interface {
+	DeleteLabel(ctx context.Context, userID, id string) error
+}
+
+func HandleDeleteLabel(log *slog.Logger, deleter LabelDeleter) api.APIFunc {
+	const op = "server.http.handlers.labels.DeleteLabel"
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 150*time.Millisecond)
+		defer cancel()
+
+		if err := deleter.DeleteLabel(ctx, userID, chi.URLParam(r, "labelID")); err != nil {
+			return labelError(log, err, userID, r)
+		}
+
+		return response.JSON(w, http.StatusOK, response.M{"message": "ok"})
+	}
+}
+
+// labelError maps an error of a label operation to an API error.
+func labelError(log *slog.Logger, err error, userID string, r *http.Request) error {
+	switch {
+	case errors.Is(err, labels.ErrNotFound):
+		log.Error("label not found", sl.Err(err), slog.String("user_id", userID))
+
+		return response.NotFound("label")
+	case errors.Is(err, labels.ErrAlreadyExists):
+		msg := "label already exists"
+
+		log.Error(msg, sl.Err(err), slog.String("user_id", userID))
+
+		return response.APIError{
+			Status:  http.StatusConflict,
+			Message: err.Error(),
+		}
+	case errors.Is(err, tasks.ErrForbidden):
+		msg := "forbidden"
+
+		log.Error(msg, sl.Err(err), slog.String("user_id", userID))
+
+		return response.APIError{
+			Status:  http.StatusForbidden,
+			Message: msg,
+		}
+	default:
+		msg := "internal server error"
+
+		log.Error(msg,
+			sl.Err(err),
+			slog.String("user_id", userID),
+			slog.String("label_id", chi.URLParam(r, "labelID")),
+		)
+
+		return response.APIError{
+			Status:  http.StatusInternalServerError,
+			Message: msg,
+		}
+	}
+}
@@ -0,0 +1,542 @@
+package projects
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/pkg/sl"
+	"github.com/romankravchuk/eldorado/internal/pkg/validator"
+	"github.com/romankravchuk/eldorado/internal/server/http/api"
+	"github.com/romankravchuk/eldorado/internal/server/http/api/response"
+	"github.com/romankravchuk/eldorado/internal/storages/projects"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
+	"github.com/romankravchuk/eldorado/internal/storages/users"
+)
+
+// project is a JSON representation of a project.
+type project struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Color      string `json:"color"`
+	IsArchived bool   `json:"is_archived"`
+	Role       string `json:"role"`
+	CreatedOn  string `json:"created_at"`
+}
+
+func newProject(p data.Project) project {
+	return project{
+		ID:         p.ID,
+		Name:       p.Name,
+		Color:      p.Color,
+		IsArchived: p.IsArchived,
+		Role:       p.Role,
+		CreatedOn:  p.CreatedOn.Format(time.RFC3339),
+	}
+}
+
+// member is a JSON representation of a project member.
+type member struct {
+	UserID    string `json:"user_id"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	CreatedOn string `json:"created_at"`
+}
+
+func newMember(m data.ProjectMember) member {
+	return member{
+		UserID:    m.UserID,
+		Email:     m.Email,
+		Role:      m.Role,
+		CreatedOn: m.CreatedOn.Format(time.RFC3339),
+	}
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name ProjectsLister
+type ProjectsLister interface {
+	Projects(ctx context.Context, userID string) ([]data.Project, error)
+}
+
+func HandleGetProjects(log *slog.Logger, lister ProjectsLister) api.APIFunc {
+	const op = "server.http.handlers.projects.GetProjects"
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 150*time.Millisecond)
+		defer cancel()
+
+		ps, err := lister.Projects(ctx, userID)
+		if err != nil {
+			return projectError(log, err, userID, r)
+		}
+
+		objs := make([]project, len(ps))
+		for i, p := range ps {
+			objs[i] = newProject(p)
+		}
+
+		return response.JSON(w, http.StatusOK, response.M{
+			"projects": objs,
+		})
+	}
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name ProjectCreater
+type ProjectCreater interface {
+	CreateProject(ctx context.Context, userID string, p data.Project) (data.Project, error)
+}
+
+func HandleCreateProject(log *slog.Logger, creater ProjectCreater) api.APIFunc {
+	const op = "server.http.handlers.projects.CreateProject"
+
+	type req struct {
+		Name  string `json:"name" validate:"required,min=1,max=100"`
+		Color string `json:"color" validate:"omitempty,hexcolor,len=7"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		input := new(req)
+		if err := json.NewDecoder(r.Body).Decode(input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: msg,
+			}
+		}
+
+		if err := validator.ValidateStruct(*input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: err.Error(),
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 150*time.Millisecond)
+		defer cancel()
+
+		p, err := creater.CreateProject(ctx, userID, data.Project{Name: input.Name, Color: input.Color})
+		if err != nil {
+			return projectError(log, err, userID, r)
+		}
+
+		return response.JSON(w, http.StatusCreated, response.M{
+			"project": newProject(p),
+		})
+	}
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name ProjectPatcher
+type ProjectPatcher interface {
+	PatchProject(ctx context.Context, userID, id string, p data.ProjectPatch) (data.Project, error)
+}
+
+// HandlePatchProject applies a JSON Merge Patch (RFC 7396) to a project.
+//
+// Setting is_archived hides or shows the project tasks in the default task list.
+func HandlePatchProject(log *slog.Logger, patcher ProjectPatcher) api.APIFunc {
+	const op = "server.http.handlers.projects.PatchProject"
+
+	type req struct {
+		Name       *string `json:"name" validate:"omitempty,min=1,max=100"`
+		Color      *string `json:"color" validate:"omitempty,hexcolor,len=7"`
+		IsArchived *bool   `json:"is_archived"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		input, _, err := api.DecodeMergePatch[req](r)
+		if err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: err.Error(),
+			}
+		}
+
+		if err := validator.ValidateStruct(input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: err.Error(),
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 150*time.Millisecond)
+		defer cancel()
+
+		p, err := patcher.PatchProject(ctx, userID, chi.URLParam(r, "projectID"), data.ProjectPatch{
+			Name:       input.Name,
+			Color:      input.Color,
+			IsArchived: input.IsArchived,
+		})
+		if err != nil {
+			return projectError(log, err, userID, r)
+		}
+
+		return response.JSON(w, http.StatusOK, response.M{
+			"project": newProject(p),
+		})
+	}
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name ProjectDeleter
+type ProjectDeleter interface {
+	DeleteProject(ctx context.Context, userID, id string) error
+}
+
+// HandleDeleteProject deletes a project, its tasks are moved out of the project.
+func HandleDeleteProject(log *slog.Logger, deleter ProjectDeleter) api.APIFunc {
+	const op = "server.http.handlers.projects.DeleteProject"
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 150*time.Millisecond)
+		defer cancel()
+
+		if err := deleter.DeleteProject(ctx, userID, chi.URLParam(r, "projectID")); err != nil {
+			return projectError(log, err, userID, r)
+		}
+
+		return response.JSON(w, http.StatusOK, response.M{"message": "ok"})
+	}
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name MembersManager
+type MembersManager interface {
+	Members(ctx context.Context, userID, projectID string) ([]data.ProjectMember, error)
+	InviteMember(ctx context.Context, userID, projectID, email, role string) (data.ProjectMember, error)
+	UpdateMember(ctx context.Context, userID, projectID, memberID, role string) (data.ProjectMember, error)
+	RemoveMember(ctx context.Context, userID, projectID, memberID string) error
+}
+
+func HandleGetMembers(log *slog.Logger, manager MembersManager) api.APIFunc {
+	const op = "server.http.handlers.projects.GetMembers"
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 150*time.Millisecond)
+		defer cancel()
+
+		ms, err := manager.Members(ctx, userID, chi.URLParam(r, "projectID"))
+		if err != nil {
+			return projectError(log, err, userID, r)
+		}
+
+		objs := make([]member, len(ms))
+		for i, m := range ms {
+			objs[i] = newMember(m)
+		}
+
+		return response.JSON(w, http.StatusOK, response.M{
+			"members": objs,
+		})
+	}
+}
+
+// HandleInviteMember adds a registered user found by email to the project members.
+func HandleInviteMember(log *slog.Logger, manager MembersManager) api.APIFunc {
+	const op = "server.http.handlers.projects.InviteMember"
+
+	type req struct {
+		Email string `json:"email" validate:"required,email"`
+		Role  string `json:"role" validate:"required,oneof=editor viewer"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		input := new(req)
+		if err := json.NewDecoder(r.Body).Decode(input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: msg,
+			}
+		}
+
+		if err := validator.ValidateStruct(*input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: err.Error(),
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 150*time.Millisecond)
+		defer cancel()
+
+		m, err := manager.InviteMember(ctx, userID, chi.URLParam(r, "projectID"), input.Email, input.Role)
+		if err != nil {
+			return projectError(log, err, userID, r)
+		}
+
+		return response.JSON(w, http.StatusCreated, response.M{
+			"member": newMember(m),
+		})
+	}
+}
+
+func HandleUpdateMember(log *slog.Logger, manager MembersManager) api.APIFunc {
+	const op = "server.http.handlers.projects.UpdateMember"
+
+	type req struct {
+		Role string `json:"role" validate:"required,oneof=editor viewer"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		input := new(req)
+		if err := json.NewDecoder(r.Body).Decode(input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: msg,
+			}
+		}
+
+		if err := validator.ValidateStruct(*input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: err.Error(),
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 150*time.Millisecond)
+		defer cancel()
+
+		m, err := manager.UpdateMember(ctx, userID, chi.URLParam(r, "projectID"), chi.URLParam(r, "userID"), input.Role)
+		if err != nil {
+			return projectError(log, err, userID, r)
+		}
+
+		return response.JSON(w, http.StatusOK, response.M{
+			"member": newMember(m),
+		})
+	}
+}
+
+// HandleRemoveMember removes a member from the project, members may remove themselves to leave it.
+func HandleRemoveMember(log *slog.Logger, manager MembersManager) api.APIFunc {
+	const op = "server.http.handlers.projects.RemoveMember"
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 150*time.Millisecond)
+		defer cancel()
+
+		if err := manager.RemoveMember(ctx, userID, chi.URLParam(r, "projectID"), chi.URLParam(r, "userID")); err != nil {
+			return projectError(log, err, userID, r)
+		}
+
+		return response.JSON(w, http.StatusOK, response.M{"message": "ok"})
+	}
+}
+
+// projectError maps an error of a project operation to an API error.
+func projectError(log *slog.Logger, err error, userID string, r *http.Request) error {
+	switch {
+	case errors.Is(err, projects.ErrNotFound):
+		log.Error("project not found", sl.Err(err), slog.String("user_id", userID))
+
+		return response.NotFound("project")
+	case errors.Is(err, projects.ErrMemberNotFound):
+		log.Error("project member not found", sl.Err(err), slog.String("user_id", userID))
+
+		return response.NotFound("member")
+	case errors.Is(err, users.ErrNotFound):
+		log.Error("invited user not found", sl.Err(err), slog.String("user_id", userID))
+
+		return response.NotFound("user")
+	case errors.Is(err, projects.ErrMemberExists):
+		msg := "member already exists"
+
+		log.Error(msg, sl.Err(err), slog.String("user_id", userID))
+
+		return response.APIError{
+			Status:  http.StatusConflict,
+			Message: err.Error(),
+		}
+	case errors.Is(err, tasks.ErrForbidden):
+		msg := "forbidden"
+
+		log.Error(msg, sl.Err(err), slog.String("user_id", userID))
+
+		return response.APIError{
+			Status:  http.StatusForbidden,
+			Message: msg,
+		}
+	default:
+		msg := "internal server error"
+
+		log.Error(msg,
+			sl.Err(err),
+			slog.String("user_id", userID),
+			slog.String("project_id", chi.URLParam(r, "projectID")),
+		)
+
+		return response.APIError{
+			Status:  http.StatusInternalServerError,
+			Message: msg,
+		}
+	}
+}
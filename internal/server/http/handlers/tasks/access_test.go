@@ -0,0 +1,194 @@
+package tasks_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/server/http/api"
+	taskshandlers "github.com/romankravchuk/eldorado/internal/server/http/handlers/tasks"
+	taskssvc "github.com/romankravchuk/eldorado/internal/services/tasks"
+	projectsmocks "github.com/romankravchuk/eldorado/internal/storages/projects/mocks"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	strangerID = "2b7c9d14-1a3e-4f6b-8c5d-9e0f1a2b3c4d"
+	taskID     = "c6a1f3d2-5b4e-4a7c-9d8e-0f1a2b3c4d5e"
+	projectID  = "5d4c3b2a-1f0e-4d9c-8b7a-6f5e4d3c2b1a"
+)
+
+// newStrangerRouter returns a router serving the task routes of a service with given options
+// for a user who does not own the task.
+func newStrangerRouter(t *testing.T, opts ...taskssvc.Option) http.Handler {
+	t.Helper()
+
+	svc, err := taskssvc.New(opts...)
+	require.NoError(t, err)
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), api.UserIDKey, strangerID)))
+		})
+	})
+	r.Route("/api/tasks/{id}", func(r chi.Router) {
+		r.Put("/", api.MakeHTTPHandlerFunc(taskshandlers.HandleUpdateTask(log, svc)))
+		r.Patch("/", api.MakeHTTPHandlerFunc(taskshandlers.HandlePatchTask(log, svc)))
+		r.Delete("/", api.MakeHTTPHandlerFunc(taskshandlers.HandleDeleteTask(log, svc)))
+	})
+
+	return r
+}
+
+// hiddenTask makes the task invisible to the stranger, as the storage does for a task
+// which is neither owned by nor shared with the user.
+func hiddenTask(m *mocks.Storage) {
+	m.On("FindByID", mock.Anything, strangerID, taskID).Return(data.Task{}, tasks.ErrNotFound)
+}
+
+func TestTaskOfAnotherUserIsNotFound(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		expect func(*mocks.Storage)
+	}{
+		{
+			name:   "update",
+			method: http.MethodPut,
+			target: "/api/tasks/" + taskID,
+			body:   `{"title": "Take over", "description": "Not my task"}`,
+			expect: hiddenTask,
+		},
+		{
+			name:   "patch",
+			method: http.MethodPatch,
+			target: "/api/tasks/" + taskID,
+			body:   `{"is_completed": true}`,
+			expect: hiddenTask,
+		},
+		{
+			name:   "delete",
+			method: http.MethodDelete,
+			target: "/api/tasks/" + taskID,
+			expect: hiddenTask,
+		},
+		{
+			name:   "purge",
+			method: http.MethodDelete,
+			target: "/api/tasks/" + taskID + "?permanent=true",
+			expect: func(m *mocks.Storage) {
+				m.On("Purge", mock.Anything, strangerID, taskID, 0).Return(data.Task{}, tasks.ErrNotFound)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := mocks.NewStorage(t)
+			tt.expect(storage)
+
+			router := newStrangerRouter(t, taskssvc.WithTaskStorage(storage))
+
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestAssigneeViewerMayOnlyCompleteTask(t *testing.T) {
+	assigned := data.Task{
+		ID:         taskID,
+		UserID:     "0a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d",
+		ProjectID:  ptr(projectID),
+		AssigneeID: ptr(strangerID),
+		Version:    1,
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		header string
+		expect func(*mocks.Storage)
+		want   int
+	}{
+		{
+			name:   "complete",
+			method: http.MethodPatch,
+			target: "/api/tasks/" + taskID,
+			body:   `{"is_completed": true}`,
+			header: `"2"`,
+			expect: func(m *mocks.Storage) {
+				m.On("Patch", mock.Anything, strangerID, taskID, data.TaskPatch{IsCompleted: ptr(true), Version: 2}).Return(data.Task{}, tasks.ErrVersionConflict)
+			},
+			want: http.StatusPreconditionFailed,
+		},
+		{
+			name:   "rename",
+			method: http.MethodPatch,
+			target: "/api/tasks/" + taskID,
+			body:   `{"title": "Renamed by the assignee"}`,
+			want:   http.StatusForbidden,
+		},
+		{
+			name:   "update",
+			method: http.MethodPut,
+			target: "/api/tasks/" + taskID,
+			body:   `{"title": "Take over", "description": "Not my task", "is_completed": true}`,
+			want:   http.StatusForbidden,
+		},
+		{
+			name:   "delete",
+			method: http.MethodDelete,
+			target: "/api/tasks/" + taskID,
+			want:   http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := mocks.NewStorage(t)
+			storage.On("FindByID", mock.Anything, strangerID, taskID).Return(assigned, nil)
+			if tt.expect != nil {
+				tt.expect(storage)
+			}
+
+			projects := projectsmocks.NewStorage(t)
+			projects.On("FindByID", mock.Anything, strangerID, projectID).Return(data.Project{ID: projectID, Role: data.RoleViewer}, nil).Maybe()
+
+			router := newStrangerRouter(t, taskssvc.WithTaskStorage(storage), taskssvc.WithProjectStorage(projects))
+
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.header != "" {
+				req.Header.Set("If-Match", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.want, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
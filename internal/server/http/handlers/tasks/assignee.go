@@ -0,0 +1,157 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/pkg/sl"
+	"github.com/romankravchuk/eldorado/internal/pkg/validator"
+	"github.com/romankravchuk/eldorado/internal/server/http/api"
+	"github.com/romankravchuk/eldorado/internal/server/http/api/response"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
+	"github.com/romankravchuk/eldorado/internal/storages/users"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name TaskAssigner
+type TaskAssigner interface {
+	Assign(ctx context.Context, userID, id string, assigneeID *string, version int) (data.Task, error)
+}
+
+// HandleAssignTask replaces the assignee of a task, null assignee_id unassigns it.
+func HandleAssignTask(log *slog.Logger, assigner TaskAssigner) api.APIFunc {
+	const op = "server.http.handlers.tasks.AssignTask"
+
+	type req struct {
+		AssigneeID *string `json:"assignee_id" validate:"omitempty,uuid"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		input := new(req)
+		if err := json.NewDecoder(r.Body).Decode(input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: msg,
+			}
+		}
+
+		if err := validator.ValidateStruct(*input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: err.Error(),
+			}
+		}
+
+		version, err := ifMatchVersion(r)
+		if err != nil {
+			log.Error("invalid precondition", sl.Err(err), slog.String("user_id", userID))
+
+			if errors.Is(err, errMultipleETags) {
+				return response.APIError{
+					Status:  http.StatusBadRequest,
+					Message: err.Error(),
+				}
+			}
+
+			return response.PreconditionFailed("task")
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 150*time.Millisecond)
+		defer cancel()
+
+		assigned, err := assigner.Assign(ctx, userID, chi.URLParam(r, "id"), input.AssigneeID, version)
+		if err != nil {
+			if errors.Is(err, tasks.ErrNotFound) {
+				log.Error("task not found", sl.Err(err), slog.String("user_id", userID))
+
+				return response.NotFound("task")
+			}
+
+			if errors.Is(err, users.ErrNotFound) {
+				log.Error("assignee not found", sl.Err(err), slog.String("user_id", userID))
+
+				return response.NotFound("user")
+			}
+
+			if errors.Is(err, tasks.ErrInvalidAssignee) {
+				msg := "invalid request"
+
+				log.Error(msg, sl.Err(err), slog.String("user_id", userID))
+
+				return response.APIError{
+					Status:  http.StatusBadRequest,
+					Message: err.Error(),
+				}
+			}
+
+			if errors.Is(err, tasks.ErrVersionConflict) {
+				log.Error("task version conflict", sl.Err(err), slog.String("user_id", userID))
+
+				return response.PreconditionFailed("task")
+			}
+
+			if errors.Is(err, tasks.ErrForbidden) {
+				msg := "forbidden"
+
+				log.Error(msg, sl.Err(err), slog.String("user_id", userID))
+
+				return response.APIError{
+					Status:  http.StatusForbidden,
+					Message: msg,
+				}
+			}
+
+			msg := "internal server error"
+
+			log.Error(msg,
+				sl.Err(err),
+				slog.String("user_id", userID),
+				slog.String("task_id", chi.URLParam(r, "id")),
+				slog.Any("request body", input),
+			)
+
+			return response.APIError{
+				Status:  http.StatusInternalServerError,
+				Message: msg,
+			}
+		}
+
+		now := time.Now()
+		w.Header().Set(etagHeader, taskETag(assigned, now))
+
+		return response.JSON(w, http.StatusOK, response.M{
+			"task": newTask(assigned, now),
+		})
+	}
+}
@@ -0,0 +1,366 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/pkg/sl"
+	"github.com/romankravchuk/eldorado/internal/server/http/api"
+	"github.com/romankravchuk/eldorado/internal/server/http/api/response"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
+)
+
+const (
+	// multipartMemory is the part of an upload kept in memory, the rest is spooled to a temporary file.
+	multipartMemory = 1 << 20
+	// multipartOverhead is the room for multipart headers and boundaries over the file size limit.
+	multipartOverhead = 64 << 10
+)
+
+// AttachmentLimits restricts uploaded files, Types are the allowed media types
+// detected from the file contents.
+type AttachmentLimits struct {
+	MaxSize int64
+	Types   []string
+}
+
+// attachment is a JSON representation of a task attachment.
+type attachment struct {
+	ID          string `json:"id"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	UploaderID  string `json:"uploader_id,omitempty"`
+	CreatedOn   string `json:"created_at"`
+}
+
+func newAttachment(a data.TaskAttachment) attachment {
+	return attachment{
+		ID:          a.ID,
+		Filename:    a.Filename,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+		UploaderID:  a.UploaderID,
+		CreatedOn:   a.CreatedOn.Format(time.RFC3339),
+	}
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name AttachmentsLister
+type AttachmentsLister interface {
+	Attachments(ctx context.Context, userID, taskID string) ([]data.TaskAttachment, error)
+}
+
+func HandleGetAttachments(log *slog.Logger, lister AttachmentsLister) api.APIFunc {
+	const op = "server.http.handlers.tasks.GetAttachments"
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 150*time.Millisecond)
+		defer cancel()
+
+		attachments, err := lister.Attachments(ctx, userID, chi.URLParam(r, "id"))
+		if err != nil {
+			return attachmentError(log, err, userID, r)
+		}
+
+		objs := make([]attachment, len(attachments))
+		for i, a := range attachments {
+			objs[i] = newAttachment(a)
+		}
+
+		return response.JSON(w, http.StatusOK, response.M{
+			"attachments": objs,
+		})
+	}
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name AttachmentUploader
+type AttachmentUploader interface {
+	CreateAttachment(ctx context.Context, userID, taskID string, a data.TaskAttachment, r io.Reader) (data.TaskAttachment, error)
+}
+
+// HandleUploadAttachment attaches a file sent as the "file" field of a multipart form.
+//
+// The media type is detected from the file contents, a declared one is ignored.
+func HandleUploadAttachment(log *slog.Logger, uploader AttachmentUploader, limits AttachmentLimits) api.APIFunc {
+	const op = "server.http.handlers.tasks.UploadAttachment"
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, limits.MaxSize+multipartOverhead)
+		if err := r.ParseMultipartForm(multipartMemory); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				return fileTooLarge(log, err, limits)
+			}
+
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: msg,
+			}
+		}
+		defer r.MultipartForm.RemoveAll()
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: "file is required",
+			}
+		}
+		defer file.Close()
+
+		if header.Size > limits.MaxSize {
+			return fileTooLarge(log, errors.New("file exceeds the size limit"), limits)
+		}
+
+		sniff := make([]byte, 512)
+		n, err := io.ReadFull(file, sniff)
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: msg,
+			}
+		}
+
+		contentType, _, _ := mime.ParseMediaType(http.DetectContentType(sniff[:n]))
+		if !slices.Contains(limits.Types, contentType) {
+			msg := "unsupported file type"
+
+			log.Error(msg, slog.String("content_type", contentType))
+
+			return response.APIError{
+				Status:  http.StatusUnsupportedMediaType,
+				Message: msg,
+			}
+		}
+
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			msg := "internal server error"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusInternalServerError,
+				Message: msg,
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+		defer cancel()
+
+		created, err := uploader.CreateAttachment(ctx, userID, chi.URLParam(r, "id"), data.TaskAttachment{
+			Filename:    attachmentFilename(header.Filename),
+			ContentType: contentType,
+			Size:        header.Size,
+		}, file)
+		if err != nil {
+			return attachmentError(log, err, userID, r)
+		}
+
+		return response.JSON(w, http.StatusCreated, response.M{
+			"attachment": newAttachment(created),
+		})
+	}
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name AttachmentOpener
+type AttachmentOpener interface {
+	OpenAttachment(ctx context.Context, userID, taskID, id string) (data.TaskAttachment, io.ReadSeekCloser, error)
+}
+
+// HandleDownloadAttachment streams the content of an attachment, Range requests are supported.
+//
+// The content is always served as a download, so an uploaded file is never rendered by the browser.
+func HandleDownloadAttachment(log *slog.Logger, opener AttachmentOpener) api.APIFunc {
+	const op = "server.http.handlers.tasks.DownloadAttachment"
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		// the content is read while it is streamed, so the request context bounds the download.
+		a, content, err := opener.OpenAttachment(r.Context(), userID, chi.URLParam(r, "id"), chi.URLParam(r, "attachmentID"))
+		if err != nil {
+			return attachmentError(log, err, userID, r)
+		}
+		defer content.Close()
+
+		w.Header().Set("Content-Type", a.ContentType)
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename}))
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+
+		http.ServeContent(w, r, "", a.CreatedOn, content)
+
+		return nil
+	}
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name AttachmentDeleter
+type AttachmentDeleter interface {
+	DeleteAttachment(ctx context.Context, userID, taskID, id string) error
+}
+
+func HandleDeleteAttachment(log *slog.Logger, deleter AttachmentDeleter) api.APIFunc {
+	const op = "server.http.handlers.tasks.DeleteAttachment"
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		if err := deleter.DeleteAttachment(ctx, userID, chi.URLParam(r, "id"), chi.URLParam(r, "attachmentID")); err != nil {
+			return attachmentError(log, err, userID, r)
+		}
+
+		return response.JSON(w, http.StatusOK, response.M{"message": "ok"})
+	}
+}
+
+// attachmentFilename returns the base name of an uploaded file cut to 255 bytes.
+func attachmentFilename(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == "/" {
+		return "file"
+	}
+
+	for len(name) > 255 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+
+	return name
+}
+
+func fileTooLarge(log *slog.Logger, err error, limits AttachmentLimits) error {
+	msg := "file is too large"
+
+	log.Error(msg, sl.Err(err), slog.Int64("max_size", limits.MaxSize))
+
+	return response.APIError{
+		Status:  http.StatusRequestEntityTooLarge,
+		Message: msg,
+	}
+}
+
+func attachmentError(log *slog.Logger, err error, userID string, r *http.Request) error {
+	switch {
+	case errors.Is(err, tasks.ErrNotFound):
+		log.Error("task not found", sl.Err(err), slog.String("user_id", userID))
+
+		return response.NotFound("task")
+	case errors.Is(err, tasks.ErrAttachmentNotFound):
+		log.Error("task attachment not found", sl.Err(err), slog.String("user_id", userID))
+
+		return response.NotFound("attachment")
+	case errors.Is(err, tasks.ErrForbidden):
+		msg := "forbidden"
+
+		log.Error(msg, sl.Err(err), slog.String("user_id", userID))
+
+		return response.APIError{
+			Status:  http.StatusForbidden,
+			Message: msg,
+		}
+	default:
+		msg := "internal server error"
+
+		log.Error(msg,
+			sl.Err(err),
+			slog.String("user_id", userID),
+			slog.String("task_id", chi.URLParam(r, "id")),
+			slog.String("attachment_id", chi.URLParam(r, "attachmentID")),
+		)
+
+		return response.APIError{
+			Status:  http.StatusInternalServerError,
+			Message: msg,
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/pkg/sl"
+	"github.com/romankravchuk/eldorado/internal/pkg/validator"
+	"github.com/romankravchuk/eldorado/internal/server/http/api"
+	"github.com/romankravchuk/eldorado/internal/server/http/api/response"
+	"github.com/romankravchuk/eldorado/internal/storages/projects"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
+)
+
+const (
+	batchModeAtomic  = "atomic"
+	batchModePartial = "partial"
+
+	batchOpComplete = "complete"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name TasksBatcher
+type TasksBatcher interface {
+	Batch(ctx context.Context, userID string, ops []data.TaskOp, atomic bool) ([]data.TaskOpResult, error)
+}
+
+// HandleBatchTasks executes a list of create, update, complete and delete operations at once.
+//
+// In the atomic mode (default) the first failed operation fails the whole batch and
+// its error is returned. In the partial mode every operation gets its own result
+// with a status and either the task or an error.
+func HandleBatchTasks(log *slog.Logger, batcher TasksBatcher) api.APIFunc {
+	const op = "server.http.handlers.tasks.BatchTasks"
+
+	type fields struct {
+		Title       *string    `json:"title" validate:"omitempty,min=3,max=100"`
+		Description *string    `json:"description" validate:"omitempty,min=3,max=255"`
+		IsCompleted *bool      `json:"is_completed"`
+		Priority    *string    `json:"priority" validate:"omitempty,oneof=low normal high urgent"`
+		DueAt       *time.Time `json:"due_at"`
+		RemindAt    *time.Time `json:"remind_at"`
+		ProjectID   *string    `json:"project_id" validate:"omitempty,uuid"`
+	}
+
+	type operation struct {
+		Op      string  `json:"op" validate:"oneof=create update complete delete"`
+		ID      string  `json:"id" validate:"omitempty,uuid"`
+		Version int     `json:"version" validate:"min=0"`
+		Task    *fields `json:"task"`
+	}
+
+	type req struct {
+		Mode       string      `json:"mode" validate:"oneof=atomic partial"`
+		Operations []operation `json:"operations" validate:"required,min=1,max=500,dive"`
+	}
+
+	type result struct {
+		Status int    `json:"status"`
+		Task   *task  `json:"task,omitempty"`
+		Error  string `json:"error,omitempty"`
+	}
+
+	// toOp converts a valid operation of the request to a batch operation.
+	toOp := func(o operation) (data.TaskOp, error) {
+		if o.Op == data.TaskOpCreate {
+			if o.Task == nil || o.Task.Title == nil || o.Task.Description == nil {
+				return data.TaskOp{}, errors.New("title and description of a created task are required")
+			}
+
+			t := data.Task{
+				Title:       *o.Task.Title,
+				Description: *o.Task.Description,
+				DueAt:       o.Task.DueAt,
+				RemindAt:    o.Task.RemindAt,
+				ProjectID:   o.Task.ProjectID,
+			}
+			if o.Task.Priority != nil {
+				t.Priority = *o.Task.Priority
+			}
+
+			return data.TaskOp{Kind: data.TaskOpCreate, Task: t}, nil
+		}
+
+		if o.ID == "" {
+			return data.TaskOp{}, errors.New("id is required")
+		}
+
+		switch o.Op {
+		case data.TaskOpUpdate:
+			if o.Task == nil {
+				return data.TaskOp{}, errors.New("task of an update is required")
+			}
+
+			return data.TaskOp{
+				Kind:    data.TaskOpUpdate,
+				ID:      o.ID,
+				Version: o.Version,
+				Patch: data.TaskPatch{
+					Title:       o.Task.Title,
+					Description: o.Task.Description,
+					IsCompleted: o.Task.IsCompleted,
+					Priority:    o.Task.Priority,
+					DueAt:       o.Task.DueAt,
+					RemindAt:    o.Task.RemindAt,
+					ProjectID:   o.Task.ProjectID,
+				},
+			}, nil
+		case batchOpComplete:
+			completed := true
+
+			return data.TaskOp{
+				Kind:    data.TaskOpUpdate,
+				ID:      o.ID,
+				Version: o.Version,
+				Patch:   data.TaskPatch{IsCompleted: &completed},
+			}, nil
+		default:
+			return data.TaskOp{Kind: data.TaskOpDelete, ID: o.ID, Version: o.Version}, nil
+		}
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		input := &req{Mode: batchModeAtomic}
+		if err := json.NewDecoder(r.Body).Decode(input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: msg,
+			}
+		}
+
+		if err := validator.ValidateStruct(*input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: err.Error(),
+			}
+		}
+
+		ops := make([]data.TaskOp, len(input.Operations))
+		for i, o := range input.Operations {
+			var err error
+			if ops[i], err = toOp(o); err != nil {
+				msg := "invalid request"
+
+				log.Error(msg, sl.Err(err), slog.Int("operation", i))
+
+				return response.APIError{
+					Status:  http.StatusBadRequest,
+					Message: fmt.Sprintf("operation %d: %s", i, err),
+				}
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		results, err := batcher.Batch(ctx, userID, ops, input.Mode == batchModeAtomic)
+		if err != nil {
+			var opErr *tasks.OpError
+			if errors.As(err, &opErr) {
+				apiErr := batchOpError(log, opErr.Err, userID)
+				if apiErr.Status != http.StatusInternalServerError {
+					apiErr.Message = fmt.Sprintf("operation %d: %s", opErr.Index, apiErr.Message)
+				}
+
+				return apiErr
+			}
+
+			if errors.Is(err, tasks.ErrForbidden) {
+				msg := "forbidden"
+
+				log.Error(msg, sl.Err(err), slog.String("user_id", userID))
+
+				return response.APIError{
+					Status:  http.StatusForbidden,
+					Message: msg,
+				}
+			}
+
+			msg := "internal server error"
+
+			log.Error(msg, sl.Err(err), slog.String("user_id", userID))
+
+			return response.APIError{
+				Status:  http.StatusInternalServerError,
+				Message: msg,
+			}
+		}
+
+		now := time.Now()
+		objs := make([]result, len(results))
+		for i, res := range results {
+			if res.Err != nil {
+				apiErr := batchOpError(log, res.Err, userID)
+				objs[i] = result{Status: apiErr.Status, Error: apiErr.Message}
+				continue
+			}
+
+			switch ops[i].Kind {
+			case data.TaskOpCreate:
+				t := newTask(res.Task, now)
+				objs[i] = result{Status: http.StatusCreated, Task: &t}
+			case data.TaskOpUpdate:
+				t := newTask(res.Task, now)
+				objs[i] = result{Status: http.StatusOK, Task: &t}
+			default:
+				objs[i] = result{Status: http.StatusOK}
+			}
+		}
+
+		return response.JSON(w, http.StatusOK, response.M{
+			"results": objs,
+		})
+	}
+}
+
+// batchOpError converts an error of a batch operation to an API error.
+func batchOpError(log *slog.Logger, err error, userID string) response.APIError {
+	switch {
+	case errors.Is(err, tasks.ErrNotFound):
+		log.Error("task not found", sl.Err(err), slog.String("user_id", userID))
+
+		return response.NotFound("task")
+	case errors.Is(err, projects.ErrNotFound):
+		log.Error("project not found", sl.Err(err), slog.String("user_id", userID))
+
+		return response.NotFound("project")
+	case errors.Is(err, tasks.ErrVersionConflict):
+		log.Error("task version conflict", sl.Err(err), slog.String("user_id", userID))
+
+		return response.PreconditionFailed("task")
+	case errors.Is(err, tasks.ErrInvalidReminder):
+		msg := "invalid request"
+
+		log.Error(msg, sl.Err(err), slog.String("user_id", userID))
+
+		return response.APIError{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		}
+	case errors.Is(err, tasks.ErrForbidden):
+		msg := "forbidden"
+
+		log.Error(msg, sl.Err(err), slog.String("user_id", userID))
+
+		return response.APIError{
+			Status:  http.StatusForbidden,
+			Message: msg,
+		}
+	default:
+		msg := "internal server error"
+
+		log.Error(msg, sl.Err(err), slog.String("user_id", userID))
+
+		return response.APIError{
+			Status:  http.StatusInternalServerError,
+			Message: msg,
+		}
+	}
+}
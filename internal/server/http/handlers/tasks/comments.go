@@ -0,0 +1,354 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/pkg/sl"
+	"github.com/romankravchuk/eldorado/internal/pkg/validator"
+	"github.com/romankravchuk/eldorado/internal/server/http/api"
+	"github.com/romankravchuk/eldorado/internal/server/http/api/response"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
+)
+
+// author is a JSON representation of a comment author.
+type author struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+// comment is a JSON representation of a task comment, Author is nil if the author has been deleted.
+type comment struct {
+	ID        string  `json:"id"`
+	Author    *author `json:"author"`
+	Content   string  `json:"content"`
+	CreatedOn string  `json:"created_at"`
+	UpdatedOn string  `json:"updated_at"`
+}
+
+func newComment(c data.TaskComment) comment {
+	obj := comment{
+		ID:        c.ID,
+		Content:   c.Content,
+		CreatedOn: c.CreatedOn.Format(time.RFC3339),
+		UpdatedOn: c.UpdatedOn.Format(time.RFC3339),
+	}
+	if c.AuthorID != "" {
+		obj.Author = &author{ID: c.AuthorID, Username: c.AuthorUsername, Email: c.AuthorEmail}
+	}
+
+	return obj
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name CommentsLister
+type CommentsLister interface {
+	Comments(ctx context.Context, q data.TaskCommentsQuery) (data.TaskCommentsPage, error)
+}
+
+// HandleGetComments returns a page of the task discussion, the oldest comments first.
+func HandleGetComments(log *slog.Logger, lister CommentsLister) api.APIFunc {
+	const op = "server.http.handlers.tasks.GetComments"
+
+	type req struct {
+		Limit  int    `validate:"min=1,max=100"`
+		Cursor string `validate:"omitempty,base64rawurl"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		input := req{
+			Limit:  data.CommentsDefaultLimit,
+			Cursor: r.URL.Query().Get("cursor"),
+		}
+		if limit := r.URL.Query().Get("limit"); limit != "" {
+			var err error
+			if input.Limit, err = strconv.Atoi(limit); err != nil {
+				msg := "invalid request"
+
+				log.Error(msg, sl.Err(err))
+
+				return response.APIError{
+					Status:  http.StatusBadRequest,
+					Message: "limit must be a number",
+				}
+			}
+		}
+
+		if err := validator.ValidateStruct(input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: err.Error(),
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 150*time.Millisecond)
+		defer cancel()
+
+		page, err := lister.Comments(ctx, data.TaskCommentsQuery{
+			UserID: userID,
+			TaskID: chi.URLParam(r, "id"),
+			Limit:  input.Limit,
+			Cursor: input.Cursor,
+		})
+		if err != nil {
+			return commentError(log, err, userID, r)
+		}
+
+		objs := make([]comment, len(page.Comments))
+		for i, c := range page.Comments {
+			objs[i] = newComment(c)
+		}
+
+		return response.JSON(w, http.StatusOK, response.M{
+			"comments":    objs,
+			"next_cursor": page.NextCursor,
+		})
+	}
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name CommentCreater
+type CommentCreater interface {
+	CreateComment(ctx context.Context, userID, taskID, content string) (data.TaskComment, error)
+}
+
+func HandleCreateComment(log *slog.Logger, creater CommentCreater) api.APIFunc {
+	const op = "server.http.handlers.tasks.CreateComment"
+
+	type req struct {
+		Content string `json:"content" validate:"required,max=5000,markdown"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		input := new(req)
+		if err := json.NewDecoder(r.Body).Decode(input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: msg,
+			}
+		}
+
+		if err := validator.ValidateStruct(*input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: err.Error(),
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 150*time.Millisecond)
+		defer cancel()
+
+		created, err := creater.CreateComment(ctx, userID, chi.URLParam(r, "id"), input.Content)
+		if err != nil {
+			return commentError(log, err, userID, r)
+		}
+
+		return response.JSON(w, http.StatusCreated, response.M{
+			"comment": newComment(created),
+		})
+	}
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name CommentUpdater
+type CommentUpdater interface {
+	UpdateComment(ctx context.Context, userID, taskID, id, content string) (data.TaskComment, error)
+}
+
+// HandleUpdateComment edits a comment, only its author may edit it.
+func HandleUpdateComment(log *slog.Logger, updater CommentUpdater) api.APIFunc {
+	const op = "server.http.handlers.tasks.UpdateComment"
+
+	type req struct {
+		Content string `json:"content" validate:"required,max=5000,markdown"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		input := new(req)
+		if err := json.NewDecoder(r.Body).Decode(input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: msg,
+			}
+		}
+
+		if err := validator.ValidateStruct(*input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: err.Error(),
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 150*time.Millisecond)
+		defer cancel()
+
+		updated, err := updater.UpdateComment(ctx, userID, chi.URLParam(r, "id"), chi.URLParam(r, "commentID"), input.Content)
+		if err != nil {
+			return commentError(log, err, userID, r)
+		}
+
+		return response.JSON(w, http.StatusOK, response.M{
+			"comment": newComment(updated),
+		})
+	}
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name CommentDeleter
+type CommentDeleter interface {
+	DeleteComment(ctx context.Context, userID, taskID, id string) error
+}
+
+// HandleDeleteComment deletes a comment, only its author may delete it.
+func HandleDeleteComment(log *slog.Logger, deleter CommentDeleter) api.APIFunc {
+	const op = "server.http.handlers.tasks.DeleteComment"
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 150*time.Millisecond)
+		defer cancel()
+
+		if err := deleter.DeleteComment(ctx, userID, chi.URLParam(r, "id"), chi.URLParam(r, "commentID")); err != nil {
+			return commentError(log, err, userID, r)
+		}
+
+		return response.JSON(w, http.StatusOK, response.M{"message": "ok"})
+	}
+}
+
+func commentError(log *slog.Logger, err error, userID string, r *http.Request) error {
+	switch {
+	case errors.Is(err, tasks.ErrNotFound):
+		log.Error("task not found", sl.Err(err), slog.String("user_id", userID))
+
+		return response.NotFound("task")
+	case errors.Is(err, tasks.ErrCommentNotFound):
+		log.Error("task comment not found", sl.Err(err), slog.String("user_id", userID))
+
+		return response.NotFound("comment")
+	case errors.Is(err, tasks.ErrInvalidCursor):
+		msg := "invalid request"
+
+		log.Error(msg, sl.Err(err), slog.String("user_id", userID))
+
+		return response.APIError{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		}
+	case errors.Is(err, tasks.ErrForbidden):
+		msg := "forbidden"
+
+		log.Error(msg, sl.Err(err), slog.String("user_id", userID))
+
+		return response.APIError{
+			Status:  http.StatusForbidden,
+			Message: msg,
+		}
+	default:
+		msg := "internal server error"
+
+		log.Error(msg,
+			sl.Err(err),
+			slog.String("user_id", userID),
+			slog.String("task_id", chi.URLParam(r, "id")),
+			slog.String("comment_id", chi.URLParam(r, "commentID")),
+		)
+
+		return response.APIError{
+			Status:  http.StatusInternalServerError,
+			Message: msg,
+		}
+	}
+}
@@ -2,8 +2,10 @@ package tasks
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -11,13 +13,16 @@ import (
 	"github.com/romankravchuk/eldorado/internal/pkg/sl"
 	"github.com/romankravchuk/eldorado/internal/server/http/api"
 	"github.com/romankravchuk/eldorado/internal/server/http/api/response"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
 )
 
 //go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name TaskDeleter
 type TaskDeleter interface {
-	Delete(ctx context.Context, id string) error
+	Delete(ctx context.Context, userID, id string, version int) error
+	Purge(ctx context.Context, userID, id string, version int) error
 }
 
+// HandleDeleteTask moves a task to the trash, with permanent=true the task is deleted permanently.
 func HandleDeleteTask(log *slog.Logger, deleter TaskDeleter) api.APIFunc {
 	const op = "server.http.handlers.tasks.DeleteTask"
 
@@ -39,10 +44,72 @@ func HandleDeleteTask(log *slog.Logger, deleter TaskDeleter) api.APIFunc {
 			}
 		}
 
+		permanent := false
+		if value := r.URL.Query().Get("permanent"); value != "" {
+			var err error
+			if permanent, err = strconv.ParseBool(value); err != nil {
+				msg := "invalid request"
+
+				log.Error(msg, sl.Err(err))
+
+				return response.APIError{
+					Status:  http.StatusBadRequest,
+					Message: "permanent must be a boolean",
+				}
+			}
+		}
+
+		version, err := ifMatchVersion(r)
+		if err != nil {
+			log.Error("invalid precondition", sl.Err(err), slog.String("user_id", userID))
+
+			if errors.Is(err, errMultipleETags) {
+				return response.APIError{
+					Status:  http.StatusBadRequest,
+					Message: err.Error(),
+				}
+			}
+
+			return response.PreconditionFailed("task")
+		}
+
 		ctx, cancel := context.WithTimeout(r.Context(), 150*time.Millisecond)
 		defer cancel()
 
-		if err := deleter.Delete(ctx, chi.URLParam(r, "id")); err != nil {
+		del := deleter.Delete
+		if permanent {
+			del = deleter.Purge
+		}
+
+		if err := del(ctx, userID, chi.URLParam(r, "id"), version); err != nil {
+			if errors.Is(err, tasks.ErrNotFound) {
+				log.Error("task not found", sl.Err(err), slog.String("user_id", userID))
+
+				// If-Match: * fails when there is no task at all.
+				if ifMatchAny(r) {
+					return response.PreconditionFailed("task")
+				}
+
+				return response.NotFound("task")
+			}
+
+			if errors.Is(err, tasks.ErrVersionConflict) {
+				log.Error("task version conflict", sl.Err(err), slog.String("user_id", userID))
+
+				return response.PreconditionFailed("task")
+			}
+
+			if errors.Is(err, tasks.ErrForbidden) {
+				msg := "forbidden"
+
+				log.Error(msg, sl.Err(err), slog.String("user_id", userID))
+
+				return response.APIError{
+					Status:  http.StatusForbidden,
+					Message: msg,
+				}
+			}
+
 			msg := "internal server error"
 
 			log.Error(msg,
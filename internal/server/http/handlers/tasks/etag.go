@@ -0,0 +1,107 @@
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/romankravchuk/eldorado/internal/data"
+)
+
+const (
+	etagHeader        = "ETag"
+	ifMatchHeader     = "If-Match"
+	ifNoneMatchHeader = "If-None-Match"
+)
+
+var (
+	errMultipleETags = errors.New("the If-Match header must contain a single entity tag")
+	errUnknownETag   = errors.New("the If-Match header does not match any task version")
+)
+
+// overdueSuffix marks the entity tags of overdue tasks.
+//
+// A task becomes overdue with time and not with a new version,
+// so the suffix keeps a cached representation from hiding it.
+const overdueSuffix = "-o"
+
+// taskETag returns a strong entity tag of a task built from its version and,
+// for a task overdue at a given time, the overdue suffix.
+func taskETag(t data.Task, now time.Time) string {
+	tag := strconv.Itoa(t.Version)
+	if t.IsOverdue(now) {
+		tag += overdueSuffix
+	}
+
+	return `"` + tag + `"`
+}
+
+// pageETag returns a weak entity tag of a page of tasks.
+//
+// The tag changes whenever a task is added to, removed from or modified in the page
+// and whenever a task of the page becomes overdue at a given time.
+func pageETag(page data.TasksPage, now time.Time) string {
+	h := fnv.New64a()
+	for _, t := range page.Tasks {
+		fmt.Fprintf(h, "%s:%d:%t;", t.ID, t.Version, t.IsOverdue(now))
+	}
+	fmt.Fprint(h, page.NextCursor)
+
+	return fmt.Sprintf(`W/"%x"`, h.Sum64())
+}
+
+// ifMatchVersion returns a task version required by the If-Match header.
+//
+// If the header is absent or is "*" returns 0 which means any version,
+// see ifMatchAny for a missing task.
+// Weak or malformed tags can never match a task and errUnknownETag is returned.
+func ifMatchVersion(r *http.Request) (int, error) {
+	header := strings.TrimSpace(r.Header.Get(ifMatchHeader))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+
+	if strings.Contains(header, ",") {
+		return 0, errMultipleETags
+	}
+
+	if !strings.HasPrefix(header, `"`) || !strings.HasSuffix(header, `"`) || len(header) < 2 {
+		return 0, errUnknownETag
+	}
+
+	// the overdue suffix does not take part in matching the version.
+	version, err := strconv.Atoi(strings.TrimSuffix(header[1:len(header)-1], overdueSuffix))
+	if err != nil || version <= 0 {
+		return 0, errUnknownETag
+	}
+
+	return version, nil
+}
+
+// ifMatchAny reports whether the If-Match header is "*", which requires the task to exist.
+func ifMatchAny(r *http.Request) bool {
+	return strings.TrimSpace(r.Header.Get(ifMatchHeader)) == "*"
+}
+
+// ifNoneMatch reports whether the If-None-Match header matches a given entity tag
+// using the weak comparison.
+func ifNoneMatch(r *http.Request, etag string) bool {
+	header := r.Header.Get(ifNoneMatchHeader)
+	if header == "" {
+		return false
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,80 @@
+package tasks_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/romankravchuk/eldorado/internal/data"
+	taskssvc "github.com/romankravchuk/eldorado/internal/services/tasks"
+	cachemocks "github.com/romankravchuk/eldorado/internal/storages/cache/mocks"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIfMatchAnyOnMissingTask(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		body   string
+	}{
+		{name: "update", method: http.MethodPut, body: `{"title": "Missing", "description": "No such task"}`},
+		{name: "patch", method: http.MethodPatch, body: `{"is_completed": true}`},
+		{name: "delete", method: http.MethodDelete},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := mocks.NewStorage(t)
+			hiddenTask(storage)
+
+			router := newStrangerRouter(t, taskssvc.WithTaskStorage(storage))
+
+			req := httptest.NewRequest(tt.method, "/api/tasks/"+taskID, strings.NewReader(tt.body))
+			req.Header.Set("If-Match", "*")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusPreconditionFailed, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestOverdueTaskETag(t *testing.T) {
+	current := data.Task{
+		ID:       taskID,
+		UserID:   strangerID,
+		Title:    "Pay rent",
+		Priority: data.PriorityNormal,
+		DueAt:    ptr(time.Now().Add(-time.Hour)),
+		Version:  1,
+	}
+
+	stored := current
+	stored.Title = "Pay the rent"
+	stored.Version = 2
+
+	storage := mocks.NewStorage(t)
+	storage.On("FindByID", mock.Anything, strangerID, taskID).Return(current, nil)
+	// the overdue suffix of the If-Match tag is not a part of the version.
+	storage.On("Update", mock.Anything, mock.MatchedBy(func(t *data.Task) bool { return t.Version == 1 })).
+		Run(func(args mock.Arguments) { *args.Get(1).(*data.Task) = stored }).
+		Return(nil)
+
+	cache := cachemocks.NewCache(t)
+	cache.On("Del", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	router := newStrangerRouter(t, taskssvc.WithTaskStorage(storage), taskssvc.WithCache(cache, time.Minute))
+
+	req := httptest.NewRequest(http.MethodPut, "/api/tasks/"+taskID, strings.NewReader(`{"title": "Pay the rent", "description": "Before the first"}`))
+	req.Header.Set("If-Match", `"1-o"`)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, `"2-o"`, rec.Header().Get("ETag"))
+}
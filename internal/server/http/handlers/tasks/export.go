@@ -0,0 +1,119 @@
+package tasks
+
+import (
+	"context"
+	"log/slog"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/pkg/sl"
+	"github.com/romankravchuk/eldorado/internal/pkg/taskfile"
+	"github.com/romankravchuk/eldorado/internal/pkg/validator"
+	"github.com/romankravchuk/eldorado/internal/server/http/api"
+	"github.com/romankravchuk/eldorado/internal/server/http/api/response"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name TasksExporter
+type TasksExporter interface {
+	Export(ctx context.Context, userID string, f func(t data.Task) error) error
+}
+
+// HandleExportTasks streams the tasks of a user as a file of a given format, json by default.
+//
+// An error after the first task is written cuts the file, it can not be reported anymore.
+func HandleExportTasks(log *slog.Logger, exporter TasksExporter) api.APIFunc {
+	const op = "server.http.handlers.tasks.ExportTasks"
+
+	type req struct {
+		Format string `validate:"oneof=json csv ics"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		input := req{Format: taskfile.FormatJSON}
+		if format := r.URL.Query().Get("format"); format != "" {
+			input.Format = format
+		}
+
+		if err := validator.ValidateStruct(input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: err.Error(),
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+		defer cancel()
+
+		file, err := taskfile.NewWriter(w, input.Format)
+		if err != nil {
+			msg := "internal server error"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusInternalServerError,
+				Message: msg,
+			}
+		}
+
+		var written bool
+		writeHeader := func() {
+			if written {
+				return
+			}
+			written = true
+
+			w.Header().Set("Content-Type", taskfile.ContentType(input.Format))
+			w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": "tasks." + input.Format}))
+		}
+
+		err = exporter.Export(ctx, userID, func(t data.Task) error {
+			writeHeader()
+			return file.Write(t)
+		})
+		if err == nil {
+			writeHeader()
+			err = file.Close()
+		}
+		if err != nil {
+			msg := "internal server error"
+
+			log.Error(msg, sl.Err(err), slog.String("user_id", userID), slog.Bool("cut", written))
+
+			if written {
+				return nil
+			}
+
+			return response.APIError{
+				Status:  http.StatusInternalServerError,
+				Message: msg,
+			}
+		}
+
+		return nil
+	}
+}
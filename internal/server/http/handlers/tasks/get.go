@@ -2,32 +2,45 @@ package tasks
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"net/http"
+	"slices"
+	"strconv"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/romankravchuk/eldorado/internal/data"
 	"github.com/romankravchuk/eldorado/internal/pkg/sl"
+	"github.com/romankravchuk/eldorado/internal/pkg/validator"
 	"github.com/romankravchuk/eldorado/internal/server/http/api"
 	"github.com/romankravchuk/eldorado/internal/server/http/api/response"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
 )
 
 //go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name TasksLister
 type TasksLister interface {
-	List(ctx context.Context, userID string) ([]data.Task, error)
+	List(ctx context.Context, q data.TasksQuery) (data.TasksPage, error)
 }
 
 func HandleGetTasks(log *slog.Logger, lister TasksLister) api.APIFunc {
 	const op = "server.http.handlers.tasks.GetTasks"
 
-	type task struct {
-		ID          string `json:"id"`
-		Title       string `json:"title"`
-		Description string `json:"description"`
-		CreatedOn   string `json:"created_at"`
-		IsCompleted bool   `json:"is_completed"`
+	type req struct {
+		Limit      int      `validate:"min=1,max=100"`
+		Cursor     string   `validate:"omitempty,base64rawurl"`
+		Completed  string   `validate:"omitempty,boolean"`
+		DueBefore  string   `validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+		Overdue    string   `validate:"omitempty,boolean"`
+		Project    string   `validate:"omitempty,uuid"`
+		Assigned   string   `validate:"omitempty,oneof=me"`
+		Labels     []string `validate:"max=10,dive,min=1,max=50"`
+		LabelMatch string   `validate:"oneof=all any"`
+		Sort       string   `validate:"oneof=created_on title priority position"`
+		Order      string   `validate:"oneof=asc desc"`
 	}
+
 	return func(w http.ResponseWriter, r *http.Request) error {
 		log := log.With(
 			slog.String("op", op),
@@ -46,11 +59,95 @@ func HandleGetTasks(log *slog.Logger, lister TasksLister) api.APIFunc {
 			}
 		}
 
+		input := req{
+			Limit:      data.TasksDefaultLimit,
+			Cursor:     r.URL.Query().Get("cursor"),
+			Completed:  r.URL.Query().Get("completed"),
+			DueBefore:  r.URL.Query().Get("due_before"),
+			Overdue:    r.URL.Query().Get("overdue"),
+			Project:    r.URL.Query().Get("project"),
+			Assigned:   r.URL.Query().Get("assigned"),
+			Labels:     uniqueSorted(r.URL.Query()["label"]),
+			LabelMatch: data.LabelsMatchAll,
+			Sort:       data.TasksSortCreatedOn,
+			Order:      data.OrderAsc,
+		}
+		if limit := r.URL.Query().Get("limit"); limit != "" {
+			var err error
+			if input.Limit, err = strconv.Atoi(limit); err != nil {
+				msg := "invalid request"
+
+				log.Error(msg, sl.Err(err))
+
+				return response.APIError{
+					Status:  http.StatusBadRequest,
+					Message: "limit must be a number",
+				}
+			}
+		}
+		if sort := r.URL.Query().Get("sort"); sort != "" {
+			input.Sort = sort
+		}
+		if order := r.URL.Query().Get("order"); order != "" {
+			input.Order = order
+		}
+		if match := r.URL.Query().Get("label_match"); match != "" {
+			input.LabelMatch = match
+		}
+
+		if err := validator.ValidateStruct(input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: err.Error(),
+			}
+		}
+
+		q := data.TasksQuery{
+			UserID:      userID,
+			Limit:       input.Limit,
+			Cursor:      input.Cursor,
+			ProjectID:   input.Project,
+			Labels:      input.Labels,
+			LabelsMatch: input.LabelMatch,
+			Sort:        input.Sort,
+			Order:       input.Order,
+		}
+		if input.Completed != "" {
+			completed, _ := strconv.ParseBool(input.Completed)
+			q.Completed = &completed
+		}
+		if input.DueBefore != "" {
+			dueBefore, _ := time.Parse(time.RFC3339, input.DueBefore)
+			q.DueBefore = &dueBefore
+		}
+		if input.Assigned != "" {
+			q.AssigneeID = userID
+		}
+		if input.Overdue != "" {
+			overdue, _ := strconv.ParseBool(input.Overdue)
+			q.Overdue = &overdue
+		}
+
 		ctx, cancel := context.WithTimeout(r.Context(), 150*time.Millisecond)
 		defer cancel()
 
-		tasks, err := lister.List(ctx, userID)
+		page, err := lister.List(ctx, q)
 		if err != nil {
+			if errors.Is(err, tasks.ErrInvalidCursor) {
+				msg := "invalid request"
+
+				log.Error(msg, sl.Err(err), slog.String("user_id", userID))
+
+				return response.APIError{
+					Status:  http.StatusBadRequest,
+					Message: err.Error(),
+				}
+			}
+
 			msg := "internal server error"
 
 			log.Error(msg, sl.Err(err), slog.String("user_id", userID))
@@ -61,19 +158,101 @@ func HandleGetTasks(log *slog.Logger, lister TasksLister) api.APIFunc {
 			}
 		}
 
-		objs := make([]task, len(tasks))
-		for i, t := range tasks {
-			objs[i] = task{
-				ID:          t.ID,
-				Title:       t.Title,
-				Description: t.Description,
-				CreatedOn:   t.CreatedOn.Format(time.RFC3339),
-				IsCompleted: t.IsCompleted,
+		now := time.Now()
+		etag := pageETag(page, now)
+		w.Header().Set(etagHeader, etag)
+
+		if ifNoneMatch(r, etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return nil
+		}
+
+		objs := make([]task, len(page.Tasks))
+		for i, t := range page.Tasks {
+			objs[i] = newTask(t, now)
+		}
+
+		return response.JSON(w, http.StatusOK, response.M{
+			"tasks":       objs,
+			"next_cursor": page.NextCursor,
+		})
+	}
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name TaskGetter
+type TaskGetter interface {
+	Get(ctx context.Context, userID, id string) (data.Task, error)
+}
+
+func HandleGetTask(log *slog.Logger, getter TaskGetter) api.APIFunc {
+	const op = "server.http.handlers.tasks.GetTask"
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 150*time.Millisecond)
+		defer cancel()
+
+		t, err := getter.Get(ctx, userID, chi.URLParam(r, "id"))
+		if err != nil {
+			if errors.Is(err, tasks.ErrNotFound) {
+				log.Error("task not found", sl.Err(err), slog.String("user_id", userID))
+
+				return response.NotFound("task")
+			}
+
+			msg := "internal server error"
+
+			log.Error(msg,
+				sl.Err(err),
+				slog.String("user_id", userID),
+				slog.String("task_id", chi.URLParam(r, "id")),
+			)
+
+			return response.APIError{
+				Status:  http.StatusInternalServerError,
+				Message: msg,
 			}
 		}
 
+		now := time.Now()
+		etag := taskETag(t, now)
+		w.Header().Set(etagHeader, etag)
+
+		if ifNoneMatch(r, etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return nil
+		}
+
 		return response.JSON(w, http.StatusOK, response.M{
-			"tasks": objs,
+			"task": newTask(t, now),
 		})
 	}
 }
+
+// uniqueSorted returns sorted unique values of a given query parameter.
+func uniqueSorted(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	return slices.Compact(sorted)
+}
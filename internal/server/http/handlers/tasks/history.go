@@ -0,0 +1,152 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/pkg/sl"
+	"github.com/romankravchuk/eldorado/internal/pkg/validator"
+	"github.com/romankravchuk/eldorado/internal/server/http/api"
+	"github.com/romankravchuk/eldorado/internal/server/http/api/response"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
+)
+
+type event struct {
+	ID         int64                      `json:"id"`
+	ActorID    string                     `json:"actor_id,omitempty"`
+	ActorEmail string                     `json:"actor_email,omitempty"`
+	Kind       string                     `json:"kind"`
+	Changes    map[string]data.TaskChange `json:"changes,omitempty"`
+	CreatedAt  time.Time                  `json:"created_at"`
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name TaskHistorian
+type TaskHistorian interface {
+	History(ctx context.Context, q data.TaskEventsQuery) ([]data.TaskEvent, error)
+}
+
+// HandleGetTaskHistory returns a page of the task history, the newest events first.
+//
+// The next page is requested with the next_before value of the previous one.
+func HandleGetTaskHistory(log *slog.Logger, historian TaskHistorian) api.APIFunc {
+	const op = "server.http.handlers.tasks.GetTaskHistory"
+
+	type req struct {
+		Limit  int   `validate:"min=1,max=100"`
+		Before int64 `validate:"min=0"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		input := req{Limit: data.TaskEventsDefaultLimit}
+		if limit := r.URL.Query().Get("limit"); limit != "" {
+			var err error
+			if input.Limit, err = strconv.Atoi(limit); err != nil {
+				msg := "invalid request"
+
+				log.Error(msg, sl.Err(err))
+
+				return response.APIError{
+					Status:  http.StatusBadRequest,
+					Message: "limit must be a number",
+				}
+			}
+		}
+		if before := r.URL.Query().Get("before"); before != "" {
+			var err error
+			if input.Before, err = strconv.ParseInt(before, 10, 64); err != nil {
+				msg := "invalid request"
+
+				log.Error(msg, sl.Err(err))
+
+				return response.APIError{
+					Status:  http.StatusBadRequest,
+					Message: "before must be a number",
+				}
+			}
+		}
+
+		if err := validator.ValidateStruct(input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: err.Error(),
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 150*time.Millisecond)
+		defer cancel()
+
+		events, err := historian.History(ctx, data.TaskEventsQuery{
+			UserID: userID,
+			TaskID: chi.URLParam(r, "id"),
+			Limit:  input.Limit,
+			Before: input.Before,
+		})
+		if err != nil {
+			if errors.Is(err, tasks.ErrNotFound) {
+				log.Error("task not found", sl.Err(err), slog.String("user_id", userID))
+
+				return response.NotFound("task")
+			}
+
+			msg := "internal server error"
+
+			log.Error(msg,
+				sl.Err(err),
+				slog.String("user_id", userID),
+				slog.String("task_id", chi.URLParam(r, "id")),
+			)
+
+			return response.APIError{
+				Status:  http.StatusInternalServerError,
+				Message: msg,
+			}
+		}
+
+		objs := make([]event, len(events))
+		for i, e := range events {
+			objs[i] = event{
+				ID:         e.ID,
+				ActorID:    e.ActorID,
+				ActorEmail: e.ActorEmail,
+				Kind:       e.Kind,
+				Changes:    e.Changes,
+				CreatedAt:  e.CreatedOn,
+			}
+		}
+
+		resp := response.M{"events": objs}
+		if len(events) == input.Limit {
+			resp["next_before"] = events[len(events)-1].ID
+		}
+
+		return response.JSON(w, http.StatusOK, resp)
+	}
+}
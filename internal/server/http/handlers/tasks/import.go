@@ -0,0 +1,230 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/pkg/sl"
+	"github.com/romankravchuk/eldorado/internal/pkg/taskfile"
+	"github.com/romankravchuk/eldorado/internal/pkg/validator"
+	"github.com/romankravchuk/eldorado/internal/server/http/api"
+	"github.com/romankravchuk/eldorado/internal/server/http/api/response"
+	"github.com/romankravchuk/eldorado/internal/storages/projects"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
+)
+
+const (
+	importStatusCreated   = "created"
+	importStatusDuplicate = "duplicate"
+	importStatusFailed    = "failed"
+
+	// importMaxBytes limits the size of an imported file.
+	importMaxBytes = 5 << 20
+)
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name TasksImporter
+type TasksImporter interface {
+	Import(ctx context.Context, userID string, rows []data.TaskImport, dryRun bool) ([]data.TaskImportResult, error)
+}
+
+// HandleImportTasks imports tasks from a file of a given format sent as the request body.
+//
+// Every row of the file gets its own result with a status and either the task or an error.
+// With dry_run=true nothing is saved, the results show what would be imported.
+// Descriptions are optional unlike the created tasks, other tools often have none.
+func HandleImportTasks(log *slog.Logger, importer TasksImporter) api.APIFunc {
+	const op = "server.http.handlers.tasks.ImportTasks"
+
+	type req struct {
+		Format string `validate:"oneof=json csv ics"`
+		DryRun bool
+	}
+
+	type row struct {
+		Title       string `validate:"required,min=3,max=100"`
+		Description string `validate:"max=255"`
+		Priority    string `validate:"omitempty,oneof=low normal high urgent"`
+		DueAt       *time.Time
+		RemindAt    *time.Time `validate:"omitempty,ltefield=DueAt"`
+		ProjectID   *string    `validate:"omitempty,uuid"`
+		Recurrence  string     `validate:"max=100"`
+	}
+
+	type result struct {
+		Row    int    `json:"row"`
+		Status string `json:"status"`
+		Task   *task  `json:"task,omitempty"`
+		Error  string `json:"error,omitempty"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		input := req{Format: r.URL.Query().Get("format")}
+		if dryRun := r.URL.Query().Get("dry_run"); dryRun != "" {
+			var err error
+			if input.DryRun, err = strconv.ParseBool(dryRun); err != nil {
+				msg := "invalid request"
+
+				log.Error(msg, sl.Err(err))
+
+				return response.APIError{
+					Status:  http.StatusBadRequest,
+					Message: "dry_run must be a boolean",
+				}
+			}
+		}
+
+		if err := validator.ValidateStruct(input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: err.Error(),
+			}
+		}
+
+		rows, err := taskfile.Read(http.MaxBytesReader(w, r.Body, importMaxBytes), input.Format, data.ImportMaxRows)
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				msg := "file is too large"
+
+				log.Error(msg, sl.Err(err))
+
+				return response.APIError{
+					Status:  http.StatusRequestEntityTooLarge,
+					Message: msg,
+				}
+			}
+
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: err.Error(),
+			}
+		}
+
+		// invalid rows are reported with the messages of their errors.
+		invalid := make([]bool, len(rows))
+		for i := range rows {
+			if rows[i].Err == nil {
+				t := rows[i].Task
+				rows[i].Err = validator.ValidateStruct(row{
+					Title:       t.Title,
+					Description: t.Description,
+					Priority:    t.Priority,
+					DueAt:       t.DueAt,
+					RemindAt:    t.RemindAt,
+					ProjectID:   t.ProjectID,
+					Recurrence:  t.Recurrence,
+				})
+			}
+			invalid[i] = rows[i].Err != nil
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+		defer cancel()
+
+		results, err := importer.Import(ctx, userID, rows, input.DryRun)
+		if err != nil {
+			if errors.Is(err, tasks.ErrForbidden) {
+				msg := "forbidden"
+
+				log.Error(msg, sl.Err(err), slog.String("user_id", userID))
+
+				return response.APIError{
+					Status:  http.StatusForbidden,
+					Message: msg,
+				}
+			}
+
+			msg := "internal server error"
+
+			log.Error(msg, sl.Err(err), slog.String("user_id", userID))
+
+			return response.APIError{
+				Status:  http.StatusInternalServerError,
+				Message: msg,
+			}
+		}
+
+		var created, duplicates, failed int
+		now := time.Now()
+		objs := make([]result, len(results))
+		for i, res := range results {
+			objs[i].Row = rows[i].Row
+
+			switch {
+			case res.Err != nil && invalid[i]:
+				failed++
+				objs[i].Status, objs[i].Error = importStatusFailed, res.Err.Error()
+			case res.Err != nil:
+				failed++
+				objs[i].Status, objs[i].Error = importStatusFailed, importRowError(log, res.Err, userID, rows[i].Row)
+			case res.Duplicate:
+				duplicates++
+				objs[i].Status = importStatusDuplicate
+			default:
+				created++
+				objs[i].Status = importStatusCreated
+				if !input.DryRun {
+					t := newTask(res.Task, now)
+					objs[i].Task = &t
+				}
+			}
+		}
+
+		return response.JSON(w, http.StatusOK, response.M{
+			"dry_run":    input.DryRun,
+			"created":    created,
+			"duplicates": duplicates,
+			"failed":     failed,
+			"rows":       objs,
+		})
+	}
+}
+
+// importRowError converts an error of an imported row to its message.
+func importRowError(log *slog.Logger, err error, userID string, row int) string {
+	switch {
+	case errors.Is(err, tasks.ErrInvalidRecurrence), errors.Is(err, tasks.ErrInvalidReminder):
+		return err.Error()
+	case errors.Is(err, projects.ErrNotFound):
+		return "project not found"
+	case errors.Is(err, tasks.ErrForbidden):
+		return "forbidden"
+	default:
+		msg := "internal server error"
+
+		log.Error(msg, sl.Err(err), slog.String("user_id", userID), slog.Int("row", row))
+
+		return msg
+	}
+}
@@ -0,0 +1,379 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/pkg/sl"
+	"github.com/romankravchuk/eldorado/internal/pkg/validator"
+	"github.com/romankravchuk/eldorado/internal/server/http/api"
+	"github.com/romankravchuk/eldorado/internal/server/http/api/response"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
+)
+
+// item is a JSON representation of a task checklist item.
+type item struct {
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	IsCompleted bool    `json:"is_completed"`
+	Position    float64 `json:"position"`
+	CreatedOn   string  `json:"created_at"`
+}
+
+func newItem(i data.TaskItem) item {
+	return item{
+		ID:          i.ID,
+		Title:       i.Title,
+		IsCompleted: i.IsCompleted,
+		Position:    i.Position,
+		CreatedOn:   i.CreatedOn.Format(time.RFC3339),
+	}
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name ItemsLister
+type ItemsLister interface {
+	Items(ctx context.Context, userID, taskID string) ([]data.TaskItem, error)
+}
+
+func HandleGetItems(log *slog.Logger, lister ItemsLister) api.APIFunc {
+	const op = "server.http.handlers.tasks.GetItems"
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 150*time.Millisecond)
+		defer cancel()
+
+		items, err := lister.Items(ctx, userID, chi.URLParam(r, "id"))
+		if err != nil {
+			return itemError(log, err, userID, r)
+		}
+
+		objs := make([]item, len(items))
+		for i, it := range items {
+			objs[i] = newItem(it)
+		}
+
+		return response.JSON(w, http.StatusOK, response.M{
+			"items": objs,
+		})
+	}
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name ItemCreater
+type ItemCreater interface {
+	CreateItem(ctx context.Context, userID, taskID string, item data.TaskItem) (data.TaskItem, error)
+}
+
+func HandleCreateItem(log *slog.Logger, creater ItemCreater) api.APIFunc {
+	const op = "server.http.handlers.tasks.CreateItem"
+
+	type req struct {
+		Title string `json:"title" validate:"required,min=1,max=255"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		input := new(req)
+		if err := json.NewDecoder(r.Body).Decode(input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: msg,
+			}
+		}
+
+		if err := validator.ValidateStruct(*input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: err.Error(),
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 150*time.Millisecond)
+		defer cancel()
+
+		created, err := creater.CreateItem(ctx, userID, chi.URLParam(r, "id"), data.TaskItem{Title: input.Title})
+		if err != nil {
+			return itemError(log, err, userID, r)
+		}
+
+		return response.JSON(w, http.StatusCreated, response.M{
+			"item": newItem(created),
+		})
+	}
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name ItemPatcher
+type ItemPatcher interface {
+	PatchItem(ctx context.Context, userID, taskID, id string, p data.TaskItemPatch) (data.TaskItem, error)
+}
+
+// HandlePatchItem applies a JSON Merge Patch (RFC 7396) to a checklist item.
+func HandlePatchItem(log *slog.Logger, patcher ItemPatcher) api.APIFunc {
+	const op = "server.http.handlers.tasks.PatchItem"
+
+	type req struct {
+		Title       *string `json:"title" validate:"omitempty,min=1,max=255"`
+		IsCompleted *bool   `json:"is_completed"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		input, _, err := api.DecodeMergePatch[req](r)
+		if err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: err.Error(),
+			}
+		}
+
+		if err := validator.ValidateStruct(input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: err.Error(),
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 150*time.Millisecond)
+		defer cancel()
+
+		patched, err := patcher.PatchItem(ctx, userID, chi.URLParam(r, "id"), chi.URLParam(r, "itemID"), data.TaskItemPatch{
+			Title:       input.Title,
+			IsCompleted: input.IsCompleted,
+		})
+		if err != nil {
+			return itemError(log, err, userID, r)
+		}
+
+		return response.JSON(w, http.StatusOK, response.M{
+			"item": newItem(patched),
+		})
+	}
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name ItemDeleter
+type ItemDeleter interface {
+	DeleteItem(ctx context.Context, userID, taskID, id string) error
+}
+
+func HandleDeleteItem(log *slog.Logger, deleter ItemDeleter) api.APIFunc {
+	const op = "server.http.handlers.tasks.DeleteItem"
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 150*time.Millisecond)
+		defer cancel()
+
+		if err := deleter.DeleteItem(ctx, userID, chi.URLParam(r, "id"), chi.URLParam(r, "itemID")); err != nil {
+			return itemError(log, err, userID, r)
+		}
+
+		return response.JSON(w, http.StatusOK, response.M{"message": "ok"})
+	}
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name ItemMover
+type ItemMover interface {
+	MoveItem(ctx context.Context, userID, taskID, id string, m data.TaskMove) (data.TaskItem, error)
+}
+
+// HandleMoveItem places a checklist item between two neighbour items.
+//
+// Omitted after moves the item to the top, omitted before moves it to the bottom.
+func HandleMoveItem(log *slog.Logger, mover ItemMover) api.APIFunc {
+	const op = "server.http.handlers.tasks.MoveItem"
+
+	type req struct {
+		After  string `json:"after" validate:"required_without=Before,omitempty,uuid"`
+		Before string `json:"before" validate:"required_without=After,omitempty,uuid"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		input := new(req)
+		if err := json.NewDecoder(r.Body).Decode(input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: msg,
+			}
+		}
+
+		if err := validator.ValidateStruct(*input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: err.Error(),
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 150*time.Millisecond)
+		defer cancel()
+
+		moved, err := mover.MoveItem(ctx, userID, chi.URLParam(r, "id"), chi.URLParam(r, "itemID"), data.TaskMove{
+			AfterID:  input.After,
+			BeforeID: input.Before,
+		})
+		if err != nil {
+			return itemError(log, err, userID, r)
+		}
+
+		return response.JSON(w, http.StatusOK, response.M{
+			"item": newItem(moved),
+		})
+	}
+}
+
+// itemError maps an error of a checklist operation to an API error.
+func itemError(log *slog.Logger, err error, userID string, r *http.Request) error {
+	switch {
+	case errors.Is(err, tasks.ErrNotFound):
+		log.Error("task not found", sl.Err(err), slog.String("user_id", userID))
+
+		return response.NotFound("task")
+	case errors.Is(err, tasks.ErrItemNotFound):
+		log.Error("task item not found", sl.Err(err), slog.String("user_id", userID))
+
+		return response.NotFound("item")
+	case errors.Is(err, tasks.ErrInvalidMove):
+		msg := "invalid request"
+
+		log.Error(msg, sl.Err(err), slog.String("user_id", userID))
+
+		return response.APIError{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		}
+	case errors.Is(err, tasks.ErrForbidden):
+		msg := "forbidden"
+
+		log.Error(msg, sl.Err(err), slog.String("user_id", userID))
+
+		return response.APIError{
+			Status:  http.StatusForbidden,
+			Message: msg,
+		}
+	default:
+		msg := "internal server error"
+
+		log.Error(msg,
+			sl.Err(err),
+			slog.String("user_id", userID),
+			slog.String("task_id", chi.URLParam(r, "id")),
+			slog.String("item_id", chi.URLParam(r, "itemID")),
+		)
+
+		return response.APIError{
+			Status:  http.StatusInternalServerError,
+			Message: msg,
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/pkg/sl"
+	"github.com/romankravchuk/eldorado/internal/pkg/validator"
+	"github.com/romankravchuk/eldorado/internal/server/http/api"
+	"github.com/romankravchuk/eldorado/internal/server/http/api/response"
+	"github.com/romankravchuk/eldorado/internal/storages/labels"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name LabelAttacher
+type LabelAttacher interface {
+	AttachLabel(ctx context.Context, userID, taskID, id string) (data.Task, error)
+	DetachLabel(ctx context.Context, userID, taskID, id string) (data.Task, error)
+}
+
+// HandleAttachLabel attaches a label given in the request body to a task.
+func HandleAttachLabel(log *slog.Logger, attacher LabelAttacher) api.APIFunc {
+	const op = "server.http.handlers.tasks.AttachLabel"
+
+	type req struct {
+		LabelID string `json:"label_id" validate:"required,uuid"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		input := new(req)
+		if err := json.NewDecoder(r.Body).Decode(input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: msg,
+			}
+		}
+
+		if err := validator.ValidateStruct(*input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: err.Error(),
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 150*time.Millisecond)
+		defer cancel()
+
+		t, err := attacher.AttachLabel(ctx, userID, chi.URLParam(r, "id"), input.LabelID)
+		if err != nil {
+			return labelError(log, err, userID, r)
+		}
+
+		now := time.Now()
+		w.Header().Set(etagHeader, taskETag(t, now))
+
+		return response.JSON(w, http.StatusOK, response.M{
+			"task": newTask(t, now),
+		})
+	}
+}
+
+// HandleDetachLabel detaches a label given in the URL from a task.
+func HandleDetachLabel(log *slog.Logger, attacher LabelAttacher) api.APIFunc {
+	const op = "server.http.handlers.tasks.DetachLabel"
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 150*time.Millisecond)
+		defer cancel()
+
+		t, err := attacher.DetachLabel(ctx, userID, chi.URLParam(r, "id"), chi.URLParam(r, "labelID"))
+		if err != nil {
+			return labelError(log, err, userID, r)
+		}
+
+		now := time.Now()
+		w.Header().Set(etagHeader, taskETag(t, now))
+
+		return response.JSON(w, http.StatusOK, response.M{
+			"task": newTask(t, now),
+		})
+	}
+}
+
+// labelError maps an error of a label operation to an API error.
+func labelError(log *slog.Logger, err error, userID string, r *http.Request) error {
+	switch {
+	case errors.Is(err, tasks.ErrNotFound):
+		log.Error("task not found", sl.Err(err), slog.String("user_id", userID))
+
+		return response.NotFound("task")
+	case errors.Is(err, labels.ErrNotFound):
+		log.Error("label not found", sl.Err(err), slog.String("user_id", userID))
+
+		return response.NotFound("label")
+	case errors.Is(err, labels.ErrAlreadyExists):
+		msg := "label already exists"
+
+		log.Error(msg, sl.Err(err), slog.String("user_id", userID))
+
+		return response.APIError{
+			Status:  http.StatusConflict,
+			Message: err.Error(),
+		}
+	case errors.Is(err, tasks.ErrForbidden):
+		msg := "forbidden"
+
+		log.Error(msg, sl.Err(err), slog.String("user_id", userID))
+
+		return response.APIError{
+			Status:  http.StatusForbidden,
+			Message: msg,
+		}
+	default:
+		msg := "internal server error"
+
+		log.Error(msg,
+			sl.Err(err),
+			slog.String("user_id", userID),
+			slog.String("task_id", chi.URLParam(r, "id")),
+			slog.String("label_id", chi.URLParam(r, "labelID")),
+		)
+
+		return response.APIError{
+			Status:  http.StatusInternalServerError,
+			Message: msg,
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/pkg/sl"
+	"github.com/romankravchuk/eldorado/internal/pkg/validator"
+	"github.com/romankravchuk/eldorado/internal/server/http/api"
+	"github.com/romankravchuk/eldorado/internal/server/http/api/response"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name TaskMover
+type TaskMover interface {
+	Move(ctx context.Context, userID, id string, m data.TaskMove) (data.Task, error)
+}
+
+// HandleMoveTask places a task between two neighbours of the user's list.
+//
+// Omitted after moves the task to the top, omitted before moves it to the bottom.
+func HandleMoveTask(log *slog.Logger, mover TaskMover) api.APIFunc {
+	const op = "server.http.handlers.tasks.MoveTask"
+
+	type req struct {
+		After  string `json:"after" validate:"required_without=Before,omitempty,uuid"`
+		Before string `json:"before" validate:"required_without=After,omitempty,uuid"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		input := new(req)
+		if err := json.NewDecoder(r.Body).Decode(input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: msg,
+			}
+		}
+
+		if err := validator.ValidateStruct(*input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: err.Error(),
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 150*time.Millisecond)
+		defer cancel()
+
+		moved, err := mover.Move(ctx, userID, chi.URLParam(r, "id"), data.TaskMove{
+			AfterID:  input.After,
+			BeforeID: input.Before,
+		})
+		if err != nil {
+			if errors.Is(err, tasks.ErrNotFound) {
+				log.Error("task not found", sl.Err(err), slog.String("user_id", userID))
+
+				return response.NotFound("task")
+			}
+
+			if errors.Is(err, tasks.ErrInvalidMove) {
+				msg := "invalid request"
+
+				log.Error(msg, sl.Err(err), slog.String("user_id", userID))
+
+				return response.APIError{
+					Status:  http.StatusBadRequest,
+					Message: err.Error(),
+				}
+			}
+
+			if errors.Is(err, tasks.ErrForbidden) {
+				msg := "forbidden"
+
+				log.Error(msg, sl.Err(err), slog.String("user_id", userID))
+
+				return response.APIError{
+					Status:  http.StatusForbidden,
+					Message: msg,
+				}
+			}
+
+			msg := "internal server error"
+
+			log.Error(msg,
+				sl.Err(err),
+				slog.String("user_id", userID),
+				slog.String("task_id", chi.URLParam(r, "id")),
+				slog.Any("request body", input),
+			)
+
+			return response.APIError{
+				Status:  http.StatusInternalServerError,
+				Message: msg,
+			}
+		}
+
+		now := time.Now()
+		w.Header().Set(etagHeader, taskETag(moved, now))
+
+		return response.JSON(w, http.StatusOK, response.M{
+			"task": newTask(moved, now),
+		})
+	}
+}
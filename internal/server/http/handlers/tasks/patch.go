@@ -0,0 +1,202 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/pkg/sl"
+	"github.com/romankravchuk/eldorado/internal/pkg/validator"
+	"github.com/romankravchuk/eldorado/internal/server/http/api"
+	"github.com/romankravchuk/eldorado/internal/server/http/api/response"
+	"github.com/romankravchuk/eldorado/internal/storages/projects"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
+)
+
+const mergePatchContentType = "application/merge-patch+json"
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name TaskPatcher
+type TaskPatcher interface {
+	Patch(ctx context.Context, userID, id string, p data.TaskPatch) (data.Task, error)
+}
+
+// HandlePatchTask applies a JSON Merge Patch (RFC 7396) to a task.
+//
+// Members absent from the patch are left unchanged, null due_at, remind_at,
+// project_id and recurrence are cleared, null values of required fields are rejected.
+func HandlePatchTask(log *slog.Logger, patcher TaskPatcher) api.APIFunc {
+	const op = "server.http.handlers.tasks.PatchTask"
+
+	type req struct {
+		Title       *string    `json:"title" validate:"omitempty,min=3,max=100"`
+		Description *string    `json:"description" validate:"omitempty,min=3,max=255"`
+		IsCompleted *bool      `json:"is_completed"`
+		Priority    *string    `json:"priority" validate:"omitempty,oneof=low normal high urgent"`
+		DueAt       *time.Time `json:"due_at"`
+		RemindAt    *time.Time `json:"remind_at"`
+		ProjectID   *string    `json:"project_id" validate:"omitempty,uuid"`
+		Recurrence  *string    `json:"recurrence" validate:"omitempty,max=100"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		if ct := r.Header.Get("Content-Type"); ct != "" {
+			mediaType, _, err := mime.ParseMediaType(ct)
+			if err != nil || (mediaType != mergePatchContentType && mediaType != "application/json") {
+				msg := "unsupported media type"
+
+				log.Error(msg, slog.String("content_type", ct))
+
+				return response.APIError{
+					Status:  http.StatusUnsupportedMediaType,
+					Message: msg,
+				}
+			}
+		}
+
+		input, nulls, err := api.DecodeMergePatch[req](r, "due_at", "remind_at", "project_id", "recurrence")
+		if err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: err.Error(),
+			}
+		}
+
+		if err := validator.ValidateStruct(input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: err.Error(),
+			}
+		}
+
+		version, err := ifMatchVersion(r)
+		if err != nil {
+			log.Error("invalid precondition", sl.Err(err), slog.String("user_id", userID))
+
+			if errors.Is(err, errMultipleETags) {
+				return response.APIError{
+					Status:  http.StatusBadRequest,
+					Message: err.Error(),
+				}
+			}
+
+			return response.PreconditionFailed("task")
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 150*time.Millisecond)
+		defer cancel()
+
+		patched, err := patcher.Patch(ctx, userID, chi.URLParam(r, "id"), data.TaskPatch{
+			Title:           input.Title,
+			Description:     input.Description,
+			IsCompleted:     input.IsCompleted,
+			Priority:        input.Priority,
+			DueAt:           input.DueAt,
+			RemindAt:        input.RemindAt,
+			ProjectID:       input.ProjectID,
+			ClearDueAt:      nulls["due_at"],
+			ClearRemindAt:   nulls["remind_at"],
+			Recurrence:      input.Recurrence,
+			ClearProjectID:  nulls["project_id"],
+			ClearRecurrence: nulls["recurrence"],
+			Version:         version,
+		})
+		if err != nil {
+			if errors.Is(err, tasks.ErrNotFound) {
+				log.Error("task not found", sl.Err(err), slog.String("user_id", userID))
+
+				// If-Match: * fails when there is no task at all.
+				if ifMatchAny(r) {
+					return response.PreconditionFailed("task")
+				}
+
+				return response.NotFound("task")
+			}
+
+			if errors.Is(err, projects.ErrNotFound) {
+				log.Error("project not found", sl.Err(err), slog.String("user_id", userID))
+
+				return response.NotFound("project")
+			}
+
+			if errors.Is(err, tasks.ErrInvalidReminder) || errors.Is(err, tasks.ErrInvalidRecurrence) {
+				msg := "invalid request"
+
+				log.Error(msg, sl.Err(err), slog.String("user_id", userID))
+
+				return response.APIError{
+					Status:  http.StatusBadRequest,
+					Message: err.Error(),
+				}
+			}
+
+			if errors.Is(err, tasks.ErrVersionConflict) {
+				log.Error("task version conflict", sl.Err(err), slog.String("user_id", userID))
+
+				return response.PreconditionFailed("task")
+			}
+
+			if errors.Is(err, tasks.ErrForbidden) {
+				msg := "forbidden"
+
+				log.Error(msg, sl.Err(err), slog.String("user_id", userID))
+
+				return response.APIError{
+					Status:  http.StatusForbidden,
+					Message: msg,
+				}
+			}
+
+			msg := "internal server error"
+
+			log.Error(msg,
+				sl.Err(err),
+				slog.String("user_id", userID),
+				slog.String("task_id", chi.URLParam(r, "id")),
+				slog.Any("request body", input),
+			)
+
+			return response.APIError{
+				Status:  http.StatusInternalServerError,
+				Message: msg,
+			}
+		}
+
+		now := time.Now()
+		w.Header().Set(etagHeader, taskETag(patched, now))
+
+		return response.JSON(w, http.StatusOK, response.M{
+			"task": newTask(patched, now),
+		})
+	}
+}
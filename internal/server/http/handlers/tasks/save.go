@@ -3,6 +3,7 @@ package tasks
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"time"
@@ -12,6 +13,8 @@ import (
 	"github.com/romankravchuk/eldorado/internal/pkg/validator"
 	"github.com/romankravchuk/eldorado/internal/server/http/api"
 	"github.com/romankravchuk/eldorado/internal/server/http/api/response"
+	"github.com/romankravchuk/eldorado/internal/storages/projects"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
 )
 
 //go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name TaskCreater
@@ -23,16 +26,13 @@ func HandleCreateTask(log *slog.Logger, creater TaskCreater) api.APIFunc {
 	const op = "server.http.handlers.CreateTask"
 
 	type req struct {
-		Title       string `json:"title" validate:"required,min=3,max=100"`
-		Description string `json:"description" validate:"required,min=3,max=500"`
-	}
-
-	type task struct {
-		ID          string `json:"id"`
-		Title       string `json:"title"`
-		Description string `json:"description"`
-		IsCompleted bool   `json:"is_completed"`
-		CreatedOn   string `json:"created_on"`
+		Title       string     `json:"title" validate:"required,min=3,max=100"`
+		Description string     `json:"description" validate:"required,min=3,max=500"`
+		Priority    string     `json:"priority" validate:"omitempty,oneof=low normal high urgent"`
+		DueAt       *time.Time `json:"due_at"`
+		RemindAt    *time.Time `json:"remind_at" validate:"omitempty,ltefield=DueAt"`
+		ProjectID   *string    `json:"project_id" validate:"omitempty,uuid"`
+		Recurrence  string     `json:"recurrence" validate:"max=100"`
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) error {
@@ -82,9 +82,45 @@ func HandleCreateTask(log *slog.Logger, creater TaskCreater) api.APIFunc {
 		t, err := creater.Create(
 			ctx,
 			userID,
-			data.Task{Title: input.Title, Description: input.Description},
+			data.Task{
+				Title:       input.Title,
+				Description: input.Description,
+				Priority:    input.Priority,
+				DueAt:       input.DueAt,
+				RemindAt:    input.RemindAt,
+				ProjectID:   input.ProjectID,
+				Recurrence:  input.Recurrence,
+			},
 		)
 		if err != nil {
+			if errors.Is(err, projects.ErrNotFound) {
+				log.Error("project not found", sl.Err(err), slog.String("user_id", userID))
+
+				return response.NotFound("project")
+			}
+
+			if errors.Is(err, tasks.ErrInvalidReminder) || errors.Is(err, tasks.ErrInvalidRecurrence) {
+				msg := "invalid request"
+
+				log.Error(msg, sl.Err(err), slog.String("user_id", userID))
+
+				return response.APIError{
+					Status:  http.StatusBadRequest,
+					Message: err.Error(),
+				}
+			}
+
+			if errors.Is(err, tasks.ErrForbidden) {
+				msg := "forbidden"
+
+				log.Error(msg, sl.Err(err), slog.String("user_id", userID))
+
+				return response.APIError{
+					Status:  http.StatusForbidden,
+					Message: msg,
+				}
+			}
+
 			msg := "internal server error"
 
 			log.Error(msg, sl.Err(err))
@@ -95,14 +131,11 @@ func HandleCreateTask(log *slog.Logger, creater TaskCreater) api.APIFunc {
 			}
 		}
 
+		now := time.Now()
+		w.Header().Set(etagHeader, taskETag(t, now))
+
 		return response.JSON(w, http.StatusCreated, response.M{
-			"task": task{
-				ID:          t.ID,
-				Title:       t.Title,
-				Description: t.Description,
-				IsCompleted: t.IsCompleted,
-				CreatedOn:   t.CreatedOn.Format(time.RFC3339),
-			},
+			"task": newTask(t, now),
 		})
 	}
 }
@@ -0,0 +1,136 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/pkg/sl"
+	"github.com/romankravchuk/eldorado/internal/pkg/validator"
+	"github.com/romankravchuk/eldorado/internal/server/http/api"
+	"github.com/romankravchuk/eldorado/internal/server/http/api/response"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name TasksSearcher
+type TasksSearcher interface {
+	Search(ctx context.Context, q data.TasksSearch) ([]data.TaskHit, error)
+}
+
+// HandleSearchTasks returns tasks matching a full-text query, the most relevant first.
+//
+// Every word of the query matches words starting with it, so a partially typed
+// word finds the tasks too.
+func HandleSearchTasks(log *slog.Logger, searcher TasksSearcher) api.APIFunc {
+	const op = "server.http.handlers.tasks.SearchTasks"
+
+	type req struct {
+		Query string `validate:"required,max=100"`
+		Limit int    `validate:"min=1,max=50"`
+	}
+
+	type hit struct {
+		Task    task    `json:"task"`
+		Rank    float64 `json:"rank"`
+		Title   string  `json:"title"`
+		Snippet string  `json:"snippet"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		input := req{
+			Query: r.URL.Query().Get("q"),
+			Limit: data.SearchDefaultLimit,
+		}
+		if limit := r.URL.Query().Get("limit"); limit != "" {
+			var err error
+			if input.Limit, err = strconv.Atoi(limit); err != nil {
+				msg := "invalid request"
+
+				log.Error(msg, sl.Err(err))
+
+				return response.APIError{
+					Status:  http.StatusBadRequest,
+					Message: "limit must be a number",
+				}
+			}
+		}
+
+		if err := validator.ValidateStruct(input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: err.Error(),
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 500*time.Millisecond)
+		defer cancel()
+
+		hits, err := searcher.Search(ctx, data.TasksSearch{
+			UserID: userID,
+			Query:  input.Query,
+			Limit:  input.Limit,
+		})
+		if err != nil {
+			if errors.Is(err, tasks.ErrInvalidSearch) {
+				msg := "invalid request"
+
+				log.Error(msg, sl.Err(err), slog.String("user_id", userID))
+
+				return response.APIError{
+					Status:  http.StatusBadRequest,
+					Message: err.Error(),
+				}
+			}
+
+			msg := "internal server error"
+
+			log.Error(msg, sl.Err(err), slog.String("user_id", userID))
+
+			return response.APIError{
+				Status:  http.StatusInternalServerError,
+				Message: msg,
+			}
+		}
+
+		now := time.Now()
+		objs := make([]hit, len(hits))
+		for i, h := range hits {
+			objs[i] = hit{
+				Task:    newTask(h.Task, now),
+				Rank:    h.Rank,
+				Title:   h.Title,
+				Snippet: h.Snippet,
+			}
+		}
+
+		return response.JSON(w, http.StatusOK, response.M{
+			"results": objs,
+		})
+	}
+}
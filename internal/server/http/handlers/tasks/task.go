@@ -0,0 +1,86 @@
+package tasks
+
+import (
+	"time"
+
+	"github.com/romankravchuk/eldorado/internal/data"
+)
+
+// task is a JSON representation of a task returned by the handlers.
+type task struct {
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+	CreatedOn   string  `json:"created_at"`
+	DeletedOn   *string `json:"deleted_at"`
+	IsCompleted bool    `json:"is_completed"`
+	Priority    string  `json:"priority"`
+	Position    float64 `json:"position"`
+	DueAt       *string `json:"due_at"`
+	RemindAt    *string `json:"remind_at"`
+	IsOverdue   bool    `json:"is_overdue"`
+	ProjectID   *string `json:"project_id"`
+	AssigneeID  *string `json:"assignee_id"`
+	Recurrence  string  `json:"recurrence"`
+
+	ItemsCount     int `json:"items_count"`
+	ItemsCompleted int `json:"items_completed"`
+	Progress       int `json:"progress"`
+
+	Labels []label `json:"labels"`
+}
+
+// label is a JSON representation of a label.
+type label struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+func newLabel(l data.Label) label {
+	return label{
+		ID:    l.ID,
+		Name:  l.Name,
+		Color: l.Color,
+	}
+}
+
+func newTask(t data.Task, now time.Time) task {
+	labels := make([]label, len(t.Labels))
+	for i, l := range t.Labels {
+		labels[i] = newLabel(l)
+	}
+
+	return task{
+		ID:          t.ID,
+		Title:       t.Title,
+		Description: t.Description,
+		CreatedOn:   t.CreatedOn.Format(time.RFC3339),
+		DeletedOn:   formatTime(t.DeletedOn),
+		IsCompleted: t.IsCompleted,
+		Priority:    t.Priority,
+		Position:    t.Position,
+		DueAt:       formatTime(t.DueAt),
+		RemindAt:    formatTime(t.RemindAt),
+		IsOverdue:   t.IsOverdue(now),
+		ProjectID:   t.ProjectID,
+		AssigneeID:  t.AssigneeID,
+		Recurrence:  t.Recurrence,
+
+		ItemsCount:     t.ItemsCount,
+		ItemsCompleted: t.ItemsCompleted,
+		Progress:       t.Progress(),
+
+		Labels: labels,
+	}
+}
+
+// formatTime formats an optional time in RFC 3339, nil stays nil.
+func formatTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+
+	s := t.Format(time.RFC3339)
+	return &s
+}
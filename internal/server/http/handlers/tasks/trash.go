@@ -0,0 +1,190 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/pkg/sl"
+	"github.com/romankravchuk/eldorado/internal/pkg/validator"
+	"github.com/romankravchuk/eldorado/internal/server/http/api"
+	"github.com/romankravchuk/eldorado/internal/server/http/api/response"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name TrashLister
+type TrashLister interface {
+	Trash(ctx context.Context, q data.TasksQuery) (data.TasksPage, error)
+}
+
+// HandleGetTrash returns a page of deleted tasks, the most recently deleted first by default.
+func HandleGetTrash(log *slog.Logger, lister TrashLister) api.APIFunc {
+	const op = "server.http.handlers.tasks.GetTrash"
+
+	type req struct {
+		Limit  int    `validate:"min=1,max=100"`
+		Cursor string `validate:"omitempty,base64rawurl"`
+		Order  string `validate:"oneof=asc desc"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		input := req{
+			Limit:  data.TasksDefaultLimit,
+			Cursor: r.URL.Query().Get("cursor"),
+			Order:  data.OrderDesc,
+		}
+		if limit := r.URL.Query().Get("limit"); limit != "" {
+			var err error
+			if input.Limit, err = strconv.Atoi(limit); err != nil {
+				msg := "invalid request"
+
+				log.Error(msg, sl.Err(err))
+
+				return response.APIError{
+					Status:  http.StatusBadRequest,
+					Message: "limit must be a number",
+				}
+			}
+		}
+		if order := r.URL.Query().Get("order"); order != "" {
+			input.Order = order
+		}
+
+		if err := validator.ValidateStruct(input); err != nil {
+			msg := "invalid request"
+
+			log.Error(msg, sl.Err(err))
+
+			return response.APIError{
+				Status:  http.StatusBadRequest,
+				Message: err.Error(),
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 150*time.Millisecond)
+		defer cancel()
+
+		page, err := lister.Trash(ctx, data.TasksQuery{
+			UserID: userID,
+			Limit:  input.Limit,
+			Cursor: input.Cursor,
+			Sort:   data.TasksSortDeletedOn,
+			Order:  input.Order,
+		})
+		if err != nil {
+			if errors.Is(err, tasks.ErrInvalidCursor) {
+				msg := "invalid request"
+
+				log.Error(msg, sl.Err(err), slog.String("user_id", userID))
+
+				return response.APIError{
+					Status:  http.StatusBadRequest,
+					Message: err.Error(),
+				}
+			}
+
+			msg := "internal server error"
+
+			log.Error(msg, sl.Err(err), slog.String("user_id", userID))
+
+			return response.APIError{
+				Status:  http.StatusInternalServerError,
+				Message: msg,
+			}
+		}
+
+		now := time.Now()
+		objs := make([]task, len(page.Tasks))
+		for i, t := range page.Tasks {
+			objs[i] = newTask(t, now)
+		}
+
+		return response.JSON(w, http.StatusOK, response.M{
+			"tasks":       objs,
+			"next_cursor": page.NextCursor,
+		})
+	}
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name TaskRestorer
+type TaskRestorer interface {
+	Restore(ctx context.Context, userID, id string) (data.Task, error)
+}
+
+func HandleRestoreTask(log *slog.Logger, restorer TaskRestorer) api.APIFunc {
+	const op = "server.http.handlers.tasks.RestoreTask"
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		userID, ok := r.Context().Value(api.UserIDKey).(string)
+		if !ok {
+			msg := "forbidden"
+
+			log.Error(msg, slog.String("error", "no user id in context"))
+
+			return response.APIError{
+				Status:  http.StatusForbidden,
+				Message: msg,
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 150*time.Millisecond)
+		defer cancel()
+
+		restored, err := restorer.Restore(ctx, userID, chi.URLParam(r, "id"))
+		if err != nil {
+			if errors.Is(err, tasks.ErrNotFound) {
+				log.Error("task not found in trash", sl.Err(err), slog.String("user_id", userID))
+
+				return response.NotFound("task")
+			}
+
+			msg := "internal server error"
+
+			log.Error(msg,
+				sl.Err(err),
+				slog.String("user_id", userID),
+				slog.String("task_id", chi.URLParam(r, "id")),
+			)
+
+			return response.APIError{
+				Status:  http.StatusInternalServerError,
+				Message: msg,
+			}
+		}
+
+		now := time.Now()
+		w.Header().Set(etagHeader, taskETag(restored, now))
+
+		return response.JSON(w, http.StatusOK, response.M{
+			"task": newTask(restored, now),
+		})
+	}
+}
@@ -3,6 +3,7 @@ package tasks
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"time"
@@ -14,29 +15,27 @@ import (
 	"github.com/romankravchuk/eldorado/internal/pkg/validator"
 	"github.com/romankravchuk/eldorado/internal/server/http/api"
 	"github.com/romankravchuk/eldorado/internal/server/http/api/response"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
 )
 
 //go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name TaskUpdater
 type TaskUpdater interface {
-	Update(ctx context.Context, id string, t data.Task) (data.Task, error)
+	Update(ctx context.Context, userID, id string, t data.Task) (data.Task, error)
 }
 
 func HandleUpdateTask(log *slog.Logger, updater TaskUpdater) api.APIFunc {
 	const op = "server.http.handlers.tasks.UpdateTask"
 
 	type req struct {
-		Title       string `json:"title" validate:"required,min=3,max=100"`
-		Description string `json:"description" validate:"required,min=3,max=255"`
-		IsCompleted bool   `json:"is_completed" validate:"boolean"`
+		Title       string     `json:"title" validate:"required,min=3,max=100"`
+		Description string     `json:"description" validate:"required,min=3,max=255"`
+		IsCompleted bool       `json:"is_completed" validate:"boolean"`
+		Priority    string     `json:"priority" validate:"omitempty,oneof=low normal high urgent"`
+		DueAt       *time.Time `json:"due_at"`
+		RemindAt    *time.Time `json:"remind_at" validate:"omitempty,ltefield=DueAt"`
+		Recurrence  string     `json:"recurrence" validate:"max=100"`
 	}
 
-	type task struct {
-		ID          string `json:"id"`
-		Title       string `json:"title"`
-		Description string `json:"description"`
-		CreatedOn   string `json:"created_at"`
-		IsCompleted bool   `json:"is_completed"`
-	}
 	return func(w http.ResponseWriter, r *http.Request) error {
 		log := log.With(
 			slog.String("op", op),
@@ -78,16 +77,73 @@ func HandleUpdateTask(log *slog.Logger, updater TaskUpdater) api.APIFunc {
 			}
 		}
 
+		version, err := ifMatchVersion(r)
+		if err != nil {
+			log.Error("invalid precondition", sl.Err(err), slog.String("user_id", userID))
+
+			if errors.Is(err, errMultipleETags) {
+				return response.APIError{
+					Status:  http.StatusBadRequest,
+					Message: err.Error(),
+				}
+			}
+
+			return response.PreconditionFailed("task")
+		}
+
 		ctx, cancel := context.WithTimeout(r.Context(), 150*time.Millisecond)
 		defer cancel()
 
-		updated, err := updater.Update(ctx, chi.URLParam(r, "id"), data.Task{
-			UserID:      userID,
+		updated, err := updater.Update(ctx, userID, chi.URLParam(r, "id"), data.Task{
 			Title:       input.Title,
 			Description: input.Description,
 			IsCompleted: input.IsCompleted,
+			Priority:    input.Priority,
+			DueAt:       input.DueAt,
+			RemindAt:    input.RemindAt,
+			Recurrence:  input.Recurrence,
+			Version:     version,
 		})
 		if err != nil {
+			if errors.Is(err, tasks.ErrNotFound) {
+				log.Error("task not found", sl.Err(err), slog.String("user_id", userID))
+
+				// If-Match: * fails when there is no task at all.
+				if ifMatchAny(r) {
+					return response.PreconditionFailed("task")
+				}
+
+				return response.NotFound("task")
+			}
+
+			if errors.Is(err, tasks.ErrInvalidReminder) || errors.Is(err, tasks.ErrInvalidRecurrence) {
+				msg := "invalid request"
+
+				log.Error(msg, sl.Err(err), slog.String("user_id", userID))
+
+				return response.APIError{
+					Status:  http.StatusBadRequest,
+					Message: err.Error(),
+				}
+			}
+
+			if errors.Is(err, tasks.ErrVersionConflict) {
+				log.Error("task version conflict", sl.Err(err), slog.String("user_id", userID))
+
+				return response.PreconditionFailed("task")
+			}
+
+			if errors.Is(err, tasks.ErrForbidden) {
+				msg := "forbidden"
+
+				log.Error(msg, sl.Err(err), slog.String("user_id", userID))
+
+				return response.APIError{
+					Status:  http.StatusForbidden,
+					Message: msg,
+				}
+			}
+
 			msg := "internal server error"
 
 			log.Error(msg,
@@ -103,14 +159,11 @@ func HandleUpdateTask(log *slog.Logger, updater TaskUpdater) api.APIFunc {
 			}
 		}
 
+		now := time.Now()
+		w.Header().Set(etagHeader, taskETag(updated, now))
+
 		return response.JSON(w, http.StatusOK, response.M{
-			"task": task{
-				ID:          updated.ID,
-				Title:       updated.Title,
-				Description: updated.Description,
-				CreatedOn:   updated.CreatedOn.Format(time.RFC3339),
-				IsCompleted: updated.IsCompleted,
-			},
+			"task": newTask(updated, now),
 		})
 	}
 }
@@ -0,0 +1,73 @@
+package tasks_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/romankravchuk/eldorado/internal/data"
+	taskssvc "github.com/romankravchuk/eldorado/internal/services/tasks"
+	cachemocks "github.com/romankravchuk/eldorado/internal/storages/cache/mocks"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateTaskRespondsWithStoredTask(t *testing.T) {
+	current := data.Task{
+		ID:          taskID,
+		UserID:      strangerID,
+		Title:       "Pack",
+		Description: "Pack for the trip",
+		Priority:    data.PriorityNormal,
+		Version:     1,
+	}
+
+	stored := current
+	stored.Title, stored.Description = "Pack bags", "Pack bags for the trip"
+	stored.CreatedOn = time.Date(2024, time.May, 1, 9, 0, 0, 0, time.UTC)
+	stored.Version = 2
+	stored.ItemsCount, stored.ItemsCompleted = 4, 1
+	stored.Labels = []data.Label{{ID: "e2d3c4b5-a6f7-4e8d-9c0b-1a2b3c4d5e6f", Name: "travel", Color: "#00aaff"}}
+
+	storage := mocks.NewStorage(t)
+	storage.On("FindByID", mock.Anything, strangerID, taskID).Return(current, nil)
+	storage.On("Update", mock.Anything, mock.AnythingOfType("*data.Task")).
+		Run(func(args mock.Arguments) { *args.Get(1).(*data.Task) = stored }).
+		Return(nil)
+
+	cache := cachemocks.NewCache(t)
+	cache.On("Del", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	router := newStrangerRouter(t, taskssvc.WithTaskStorage(storage), taskssvc.WithCache(cache, time.Minute))
+
+	body := `{"title": "Pack bags", "description": "Pack bags for the trip"}`
+	req := httptest.NewRequest(http.MethodPut, "/api/tasks/"+taskID, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, `"2"`, rec.Header().Get("ETag"))
+
+	var resp struct {
+		Task struct {
+			ItemsCount int `json:"items_count"`
+			Progress   int `json:"progress"`
+			Labels     []struct {
+				Name string `json:"name"`
+			} `json:"labels"`
+			CreatedOn string `json:"created_at"`
+		} `json:"task"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+
+	assert.Equal(t, 4, resp.Task.ItemsCount)
+	assert.Equal(t, 25, resp.Task.Progress)
+	require.Len(t, resp.Task.Labels, 1)
+	assert.Equal(t, "travel", resp.Task.Labels[0].Name)
+	assert.Equal(t, "2024-05-01T09:00:00Z", resp.Task.CreatedOn)
+}
@@ -2,11 +2,14 @@ package middleware
 
 import (
 	"context"
+	"crypto/rsa"
+	"errors"
 	"log/slog"
 	"net/http"
 	"strings"
 
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/romankravchuk/eldorado/internal/pkg/jwt"
 	"github.com/romankravchuk/eldorado/internal/pkg/sl"
 	"github.com/romankravchuk/eldorado/internal/server/http/api"
 	"github.com/romankravchuk/eldorado/internal/server/http/api/response"
@@ -18,36 +21,31 @@ const (
 	tokenCookie  = "access_token"
 )
 
+// KeySource provides the keys verifying access tokens.
+type KeySource interface {
+	Keys(ctx context.Context) ([]*rsa.PublicKey, error)
+	// Refresh gets the keys again, a token could be signed by a key new to the source.
+	Refresh(ctx context.Context) ([]*rsa.PublicKey, error)
+}
+
+// Denylist tells whether access tokens are revoked by their ids.
+type Denylist interface {
+	IsRevoked(ctx context.Context, id string) (bool, error)
+}
+
+// JWT verifies the token of a request by the auth service.
 func JWT(log *slog.Logger, client proto.AuthServiceClient) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
 			log := log.With(
 				slog.String("request_id", middleware.GetReqID(r.Context())),
 			)
-			var token string
-
-			rawToken := r.Header.Get(api.AuthorizationHeader)
-
-			if strings.HasPrefix(rawToken, headerPrefix) {
-				token = strings.TrimPrefix(rawToken, headerPrefix)
-			} else {
-				cookie, err := r.Cookie(tokenCookie)
-				if err != nil {
-					msg := "unauthorized"
-
-					log.Error(msg, sl.Err(err))
-
-					response.JSON(w, http.StatusUnauthorized, response.M{"error": msg})
-
-					return
-				}
-				token = cookie.Value
-			}
 
-			if token == "" {
+			token, err := requestToken(r)
+			if err != nil {
 				msg := "unauthorized"
 
-				log.Error(msg, slog.String("error", "token is empty"))
+				log.Error(msg, sl.Err(err))
 
 				response.JSON(w, http.StatusUnauthorized, response.M{"error": msg})
 
@@ -76,12 +74,115 @@ func JWT(log *slog.Logger, client proto.AuthServiceClient) func(http.Handler) ht
 
 			log.Info("request verified", slog.String("token", token), slog.String("user_id", resp.UserID))
 
-			ctx := context.WithValue(r.Context(), api.UserIDKey, resp.UserID)
-			ctx = context.WithValue(ctx, api.TokenKey, token)
+			next.ServeHTTP(w, r.WithContext(withToken(r.Context(), token, resp.UserID)))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// LocalJWT verifies the token of a request by its signature,
+// the auth service is only asked for the keys and revocation is checked in the denylist.
+//
+// Unlike JWT, it accepts the tokens whose sessions are dropped but not revoked.
+func LocalJWT(log *slog.Logger, keys KeySource, denylist Denylist) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			log := log.With(
+				slog.String("request_id", middleware.GetReqID(r.Context())),
+			)
+
+			token, err := requestToken(r)
+			if err != nil {
+				msg := "unauthorized"
+
+				log.Error(msg, sl.Err(err))
+
+				response.JSON(w, http.StatusUnauthorized, response.M{"error": msg})
+
+				return
+			}
+
+			pubs, err := keys.Keys(r.Context())
+			if err != nil {
+				msg := "internal server error"
+
+				log.Error(msg, sl.Err(err))
+
+				response.JSON(w, http.StatusInternalServerError, response.M{"error": msg})
+
+				return
+			}
+
+			payload, err := jwt.ValidateToken(token, pubs...)
+			if errors.Is(err, jwt.ErrUnknownKey) {
+				if pubs, err = keys.Refresh(r.Context()); err == nil {
+					payload, err = jwt.ValidateToken(token, pubs...)
+				}
+			}
+			if err != nil {
+				msg := "forbidden"
+
+				log.Error(msg, sl.Err(err))
+
+				response.JSON(w, http.StatusForbidden, response.M{"error": msg})
+
+				return
+			}
+
+			revoked, err := denylist.IsRevoked(r.Context(), payload.ID)
+			if err != nil {
+				msg := "internal server error"
 
-			next.ServeHTTP(w, r.WithContext(ctx))
+				log.Error(msg, sl.Err(err), slog.Any("payload", payload))
+
+				response.JSON(w, http.StatusInternalServerError, response.M{"error": msg})
+
+				return
+			}
+			if revoked {
+				msg := "forbidden"
+
+				log.Error(msg, slog.String("error", "access token is revoked"), slog.Any("payload", payload))
+
+				response.JSON(w, http.StatusForbidden, response.M{"error": msg})
+
+				return
+			}
+
+			log.Info("request verified", slog.String("token", token), slog.String("user_id", payload.UserID))
+
+			next.ServeHTTP(w, r.WithContext(withToken(r.Context(), token, payload.UserID)))
 		}
 
 		return http.HandlerFunc(fn)
 	}
 }
+
+// requestToken returns the token from the authorization header or else from the cookie.
+func requestToken(r *http.Request) (string, error) {
+	var token string
+
+	rawToken := r.Header.Get(api.AuthorizationHeader)
+
+	if strings.HasPrefix(rawToken, headerPrefix) {
+		token = strings.TrimPrefix(rawToken, headerPrefix)
+	} else {
+		cookie, err := r.Cookie(tokenCookie)
+		if err != nil {
+			return "", err
+		}
+		token = cookie.Value
+	}
+
+	if token == "" {
+		return "", errors.New("token is empty")
+	}
+
+	return token, nil
+}
+
+func withToken(ctx context.Context, token, userID string) context.Context {
+	ctx = context.WithValue(ctx, api.UserIDKey, userID)
+	return context.WithValue(ctx, api.TokenKey, token)
+}
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/romankravchuk/eldorado/internal/pkg/jwt"
+	"github.com/romankravchuk/eldorado/internal/services/auth/proto"
+)
+
+// retryAfter delays fetching the keys again after a failure or a refresh.
+const retryAfter = 10 * time.Second
+
+var ErrNoKeys = errors.New("no keys to verify tokens")
+
+// Keys caches the keys verifying access tokens fetched from the auth service.
+//
+// The cached keys outlive their ttl while the auth service is unavailable,
+// so tokens are still verified without it.
+type Keys struct {
+	client proto.AuthServiceClient
+	ttl    time.Duration
+
+	mu      sync.Mutex
+	keys    []*rsa.PublicKey
+	expires time.Time
+	fetched time.Time
+}
+
+func NewKeys(client proto.AuthServiceClient, ttl time.Duration) *Keys {
+	return &Keys{client: client, ttl: ttl}
+}
+
+func (k *Keys) Keys(ctx context.Context) ([]*rsa.PublicKey, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if time.Now().Before(k.expires) {
+		return k.keys, nil
+	}
+
+	return k.update(ctx)
+}
+
+// Refresh fetches the keys before their ttl ends, to get a key unknown yet.
+// The keys are fetched at most once in a while, the cached ones are returned meanwhile.
+func (k *Keys) Refresh(ctx context.Context) ([]*rsa.PublicKey, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if time.Since(k.fetched) < retryAfter {
+		return k.keys, nil
+	}
+
+	return k.update(ctx)
+}
+
+func (k *Keys) update(ctx context.Context) ([]*rsa.PublicKey, error) {
+	k.fetched = time.Now()
+
+	keys, err := k.fetch(ctx)
+	if err != nil {
+		if len(k.keys) == 0 {
+			return nil, err
+		}
+
+		k.expires = time.Now().Add(retryAfter)
+		return k.keys, nil
+	}
+
+	k.keys, k.expires = keys, time.Now().Add(k.ttl)
+	return k.keys, nil
+}
+
+func (k *Keys) fetch(ctx context.Context) ([]*rsa.PublicKey, error) {
+	resp, err := k.client.JWKS(ctx, &proto.JWKSRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch keys: %w", err)
+	}
+	if resp.Meta.Error != "" {
+		return nil, fmt.Errorf("failed to fetch keys: %s", resp.Meta.Error)
+	}
+
+	keys := make([]*rsa.PublicKey, 0, len(resp.Keys))
+	for _, key := range resp.Keys {
+		pub, err := jwt.JWK{
+			Kty: key.Kty,
+			Kid: key.Kid,
+			Use: key.Use,
+			Alg: key.Alg,
+			N:   key.N,
+			E:   key.E,
+		}.PublicKey()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode key %q: %w", key.Kid, err)
+		}
+
+		keys = append(keys, pub)
+	}
+	if len(keys) == 0 {
+		return nil, ErrNoKeys
+	}
+
+	return keys, nil
+}
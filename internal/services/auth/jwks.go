@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/romankravchuk/eldorado/internal/pkg/jwt"
+	"github.com/romankravchuk/eldorado/internal/services/auth/proto"
+)
+
+// JWKS returns the keys verifying access tokens, so the clients verify them on their own.
+// The keys scheduled for rotation are included ahead of their activation.
+func (s *Service) JWKS(ctx context.Context, in *proto.JWKSRequest) (*proto.JWKSResponse, error) {
+	pubs := s.access.keys.VerificationKeys()
+
+	keys := make([]*proto.JWK, len(pubs))
+	for i, pub := range pubs {
+		key := jwt.NewJWK(pub)
+
+		keys[i] = &proto.JWK{
+			Kty: key.Kty,
+			Kid: key.Kid,
+			Use: key.Use,
+			Alg: key.Alg,
+			N:   key.N,
+			E:   key.E,
+		}
+	}
+
+	return &proto.JWKSResponse{
+		Meta: &proto.Response{
+			Status: http.StatusOK,
+		},
+		Keys: keys,
+	}, nil
+}
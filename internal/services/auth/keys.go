@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/romankravchuk/eldorado/internal/pkg/jwt"
+)
+
+// Rotation schedules the keys replacing the key of a kind of tokens.
+type Rotation struct {
+	// Overlap is how long a replaced key still verifies tokens,
+	// it is never shorter than the life of the tokens.
+	Overlap time.Duration
+	Keys    []RotationKey
+}
+
+// RotationKey is a base64 encoded private key in PEM signing tokens from ActiveFrom.
+type RotationKey struct {
+	PrivateKey string
+	ActiveFrom time.Time
+}
+
+type credentials struct {
+	keys *jwt.Keyring
+	ttl  time.Duration
+}
+
+// newCredentials makes the key pair the first key of the keyring, the rotation keys replace it in turn.
+func newCredentials(pvKey, pbKey string, ttl time.Duration, rotation Rotation) (credentials, error) {
+	pem, pub, err := jwt.ParseKeyPairs(pvKey, pbKey)
+	if err != nil {
+		return credentials{}, err
+	}
+	if !pub.Equal(&pem.PublicKey) {
+		return credentials{}, errors.New("the public key does not match the private key")
+	}
+
+	keys := jwt.NewKeyring(max(rotation.Overlap, ttl))
+	keys.Add(pem, time.Time{})
+
+	for i, k := range rotation.Keys {
+		key, err := jwt.ParsePrivateKey(k.PrivateKey)
+		if err != nil {
+			return credentials{}, fmt.Errorf("rotation key %d: %w", i, err)
+		}
+
+		keys.Add(key, k.ActiveFrom)
+	}
+
+	return credentials{keys: keys, ttl: ttl}, nil
+}
@@ -0,0 +1,230 @@
+package auth
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/pkg/jwt"
+	"github.com/romankravchuk/eldorado/internal/pkg/sl"
+	"github.com/romankravchuk/eldorado/internal/pkg/validator"
+	"github.com/romankravchuk/eldorado/internal/services/auth/proto"
+	"github.com/romankravchuk/eldorado/internal/storages/sessions"
+)
+
+// Logout ends the session of the access token and of the refresh token, if given.
+func (s *Service) Logout(ctx context.Context, in *proto.LogoutRequest) (*proto.Response, error) {
+	const op = "services.auth.Logout"
+
+	log := s.log.With("op", op)
+
+	if err := validator.ValidateStruct(in); err != nil {
+		msg := "failed to validate request"
+
+		log.Error(msg, sl.Err(err))
+
+		return &proto.Response{
+			Status: http.StatusBadRequest,
+			Error:  err.Error(),
+		}, nil
+	}
+
+	access, err := jwt.ValidateToken(in.GetAccess(), s.access.keys.VerificationKeys()...)
+	if err != nil {
+		msg := "access token is invalid"
+
+		log.Error(msg, sl.Err(err))
+
+		return &proto.Response{
+			Status: http.StatusForbidden,
+			Error:  msg,
+		}, nil
+	}
+
+	// the tokens issued before the sessions were introduced have no family,
+	// revoking them by ids covers those.
+	ids := []string{access.ID}
+	families := []string{access.FamilyID}
+
+	if in.GetRefresh() != "" {
+		refresh, err := jwt.ValidateToken(in.GetRefresh(), s.refresh.keys.VerificationKeys()...)
+		if err != nil {
+			msg := "refresh token is invalid"
+
+			log.Error(msg, sl.Err(err))
+
+			return &proto.Response{
+				Status: http.StatusForbidden,
+				Error:  msg,
+			}, nil
+		}
+		if refresh.UserID != access.UserID {
+			msg := "refresh token belongs to another user"
+
+			log.Error(msg, slog.Any("access", access), slog.Any("refresh", refresh))
+
+			return &proto.Response{
+				Status: http.StatusForbidden,
+				Error:  msg,
+			}, nil
+		}
+
+		ids = append(ids, refresh.ID)
+		if refresh.FamilyID != access.FamilyID {
+			families = append(families, refresh.FamilyID)
+		}
+	}
+
+	if err := s.revoke(ctx, ids...); err != nil {
+		msg := "failed to revoke tokens"
+
+		log.Error(msg, sl.Err(err), slog.Any("payload", access))
+
+		return &proto.Response{
+			Status: http.StatusInternalServerError,
+			Error:  msg,
+		}, nil
+	}
+
+	for _, family := range families {
+		if family == "" {
+			continue
+		}
+
+		if err := s.endSession(ctx, access.UserID, family); err != nil {
+			msg := "failed to end session"
+
+			log.Error(msg, sl.Err(err), slog.Any("payload", access), slog.String("session_id", family))
+
+			return &proto.Response{
+				Status: http.StatusInternalServerError,
+				Error:  msg,
+			}, nil
+		}
+	}
+
+	return &proto.Response{
+		Status: http.StatusOK,
+	}, nil
+}
+
+// LogoutAll ends every session of the owner of a given access token.
+func (s *Service) LogoutAll(ctx context.Context, in *proto.LogoutAllRequest) (*proto.Response, error) {
+	const op = "services.auth.LogoutAll"
+
+	log := s.log.With("op", op)
+
+	if err := validator.ValidateStruct(in); err != nil {
+		msg := "failed to validate request"
+
+		log.Error(msg, sl.Err(err))
+
+		return &proto.Response{
+			Status: http.StatusBadRequest,
+			Error:  err.Error(),
+		}, nil
+	}
+
+	access, resp := s.authenticate(ctx, log, in.GetAccess())
+	if resp != nil {
+		return resp, nil
+	}
+
+	ids, err := s.sessions.Index(ctx, access.UserID)
+	if err != nil {
+		msg := "failed to get user sessions"
+
+		log.Error(msg, sl.Err(err), slog.Any("payload", access))
+
+		return &proto.Response{
+			Status: http.StatusInternalServerError,
+			Error:  msg,
+		}, nil
+	}
+
+	if err := s.revoke(ctx, access.ID); err != nil {
+		msg := "failed to revoke tokens"
+
+		log.Error(msg, sl.Err(err), slog.Any("payload", access))
+
+		return &proto.Response{
+			Status: http.StatusInternalServerError,
+			Error:  msg,
+		}, nil
+	}
+
+	for _, id := range ids {
+		if err := s.endSession(ctx, access.UserID, id); err != nil {
+			msg := "failed to end session"
+
+			log.Error(msg, sl.Err(err), slog.Any("payload", access), slog.String("session_id", id))
+
+			return &proto.Response{
+				Status: http.StatusInternalServerError,
+				Error:  msg,
+			}, nil
+		}
+	}
+
+	return &proto.Response{
+		Status: http.StatusOK,
+	}, nil
+}
+
+// authenticate validates an access token and checks it is not revoked.
+// The response is not nil when the token is not accepted.
+func (s *Service) authenticate(ctx context.Context, log *slog.Logger, token string) (*data.TokenPayload, *proto.Response) {
+	access, err := jwt.ValidateToken(token, s.access.keys.VerificationKeys()...)
+	if err != nil {
+		msg := "access token is invalid"
+
+		log.Error(msg, sl.Err(err))
+
+		return nil, &proto.Response{
+			Status: http.StatusForbidden,
+			Error:  msg,
+		}
+	}
+
+	revoked, err := s.isRevoked(ctx, access.ID)
+	if err != nil {
+		msg := "failed to check token revocation"
+
+		log.Error(msg, sl.Err(err), slog.Any("payload", access))
+
+		return nil, &proto.Response{
+			Status: http.StatusInternalServerError,
+			Error:  msg,
+		}
+	}
+	if revoked {
+		msg := "access token is revoked"
+
+		log.Error(msg, slog.Any("payload", access))
+
+		return nil, &proto.Response{
+			Status: http.StatusForbidden,
+			Error:  msg,
+		}
+	}
+
+	return access, nil
+}
+
+// revoke marks token ids as revoked for the life of the longest token and drops their sessions.
+func (s *Service) revoke(ctx context.Context, ids ...string) error {
+	ttl := max(s.access.ttl, s.refresh.ttl)
+
+	for _, id := range ids {
+		if err := s.sessions.Set(ctx, sessions.RevokedKey(id), nil, ttl); err != nil {
+			return err
+		}
+	}
+
+	return s.sessions.Delete(ctx, ids...)
+}
+
+func (s *Service) isRevoked(ctx context.Context, id string) (bool, error) {
+	return sessions.NewDenylist(s.sessions).IsRevoked(ctx, id)
+}
@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/pkg/jwt"
+	"github.com/romankravchuk/eldorado/internal/pkg/sl"
+	"github.com/romankravchuk/eldorado/internal/services/auth/proto"
+	"github.com/romankravchuk/eldorado/internal/storages/sessions"
+)
+
+const (
+	// spentPrefix prefixes the keys marking the refresh tokens already rotated,
+	// their values are the families of the tokens.
+	spentPrefix = "spent:"
+	// familyPrefix prefixes the ids of the session indexes of token families.
+	familyPrefix = "family:"
+)
+
+type tokenPair struct {
+	access  *data.TokenDetails
+	refresh *data.TokenDetails
+}
+
+func (s *Service) issuePair(ctx context.Context, payload data.TokenPayload) (tokenPair, error) {
+	access, err := s.issue(ctx, payload, s.access)
+	if err != nil {
+		return tokenPair{}, fmt.Errorf("failed to issue access token: %w", err)
+	}
+
+	refresh, err := s.issue(ctx, payload, s.refresh)
+	if err != nil {
+		return tokenPair{}, fmt.Errorf("failed to issue refresh token: %w", err)
+	}
+
+	return tokenPair{access: access, refresh: refresh}, nil
+}
+
+// issue creates a token with a new id and stores it linked to its session.
+//
+// The family index only serves revocation, so failing to update it is logged and ignored.
+func (s *Service) issue(ctx context.Context, payload data.TokenPayload, creds credentials) (*data.TokenDetails, error) {
+	key := creds.keys.SigningKey()
+	if key == nil {
+		return nil, errors.New("no signing key is active")
+	}
+
+	payload.ID = uuid.NewString()
+
+	td, err := jwt.CreateToken(&payload, creds.ttl, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.sessions.Set(ctx, payload.ID, []byte(payload.FamilyID), creds.ttl); err != nil {
+		return nil, fmt.Errorf("failed to store token: %w", err)
+	}
+
+	if err := s.sessions.AddToIndex(ctx, familyPrefix+payload.FamilyID, payload.ID, creds.ttl); err != nil {
+		s.log.Error("failed to add token to family sessions", sl.Err(err), slog.Any("payload", payload))
+	}
+
+	return td, nil
+}
+
+// refreshSpent responds to a refresh with a token without a session.
+// The token is either expired or already rotated, the latter is a reuse.
+func (s *Service) refreshSpent(ctx context.Context, log *slog.Logger, payload *data.TokenPayload) *proto.RefreshResponse {
+	family, err := s.sessions.Get(ctx, spentPrefix+payload.ID)
+	if err != nil {
+		if errors.Is(err, sessions.ErrNotFound) {
+			msg := "refresh token is expired"
+
+			log.Error(msg, slog.Any("payload", payload))
+
+			return &proto.RefreshResponse{
+				Meta: &proto.Response{
+					Status: http.StatusForbidden,
+					Error:  msg,
+				},
+			}
+		}
+
+		msg := "failed to check refresh token rotation"
+
+		log.Error(msg, sl.Err(err), slog.Any("payload", payload))
+
+		return &proto.RefreshResponse{
+			Meta: &proto.Response{
+				Status: http.StatusInternalServerError,
+				Error:  msg,
+			},
+		}
+	}
+
+	log.Warn("security event: refresh token reuse detected, revoking token family",
+		slog.String("event", "refresh_token_reuse"),
+		slog.String("user_id", payload.UserID),
+		slog.String("family_id", string(family)),
+		slog.String("token_id", payload.ID),
+	)
+
+	if err := s.endSession(ctx, payload.UserID, string(family)); err != nil {
+		msg := "failed to end session"
+
+		log.Error(msg, sl.Err(err), slog.Any("payload", payload), slog.String("family_id", string(family)))
+
+		return &proto.RefreshResponse{
+			Meta: &proto.Response{
+				Status: http.StatusInternalServerError,
+				Error:  msg,
+			},
+		}
+	}
+
+	return &proto.RefreshResponse{
+		Meta: &proto.Response{
+			Status: http.StatusForbidden,
+			Error:  "refresh token is reused",
+		},
+	}
+}
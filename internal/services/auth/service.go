@@ -53,34 +53,26 @@ func WithUsersPostgresStorage(url string) Option {
 	}
 }
 
-func WithAccessCreds(pvKey, pbKey string, ttl time.Duration) Option {
+func WithAccessCreds(pvKey, pbKey string, ttl time.Duration, rotation Rotation) Option {
 	return func(s *Service) error {
-		pem, pub, err := jwt.ParseKeyPairs(pvKey, pbKey)
+		creds, err := newCredentials(pvKey, pbKey, ttl, rotation)
 		if err != nil {
 			return err
 		}
 
-		s.access = data.RSACredentials{
-			PrivateKey: pem,
-			PublicKey:  pub,
-			TTL:        ttl,
-		}
+		s.access = creds
 		return nil
 	}
 }
 
-func WithRefreshCreds(pvKey, pbKey string, ttl time.Duration) Option {
+func WithRefreshCreds(pvKey, pbKey string, ttl time.Duration, rotation Rotation) Option {
 	return func(s *Service) error {
-		pem, pub, err := jwt.ParseKeyPairs(pvKey, pbKey)
+		creds, err := newCredentials(pvKey, pbKey, ttl, rotation)
 		if err != nil {
 			return err
 		}
 
-		s.refresh = data.RSACredentials{
-			PrivateKey: pem,
-			PublicKey:  pub,
-			TTL:        ttl,
-		}
+		s.refresh = creds
 		return nil
 	}
 }
@@ -120,8 +112,8 @@ type Service struct {
 
 	log *slog.Logger
 
-	access  data.RSACredentials
-	refresh data.RSACredentials
+	access  credentials
+	refresh credentials
 
 	proto.UnsafeAuthServiceServer
 }
@@ -257,19 +249,20 @@ func (s *Service) Token(ctx context.Context, in *proto.TokenRequest) (*proto.Tok
 		}, nil
 	}
 
-	access, err := jwt.CreateToken(
-		&data.TokenPayload{
-			ID:     uuid.NewString(),
-			UserID: u.ID,
-			Email:  u.Email,
-		},
-		s.access.TTL,
-		s.access.PrivateKey,
-	)
-	if err != nil {
-		msg := "failed to create access token"
+	now := time.Now().UTC()
+	sess := data.Session{
+		ID:         uuid.NewString(),
+		UserID:     u.ID,
+		UserAgent:  in.GetUserAgent(),
+		IP:         in.GetIp(),
+		CreatedAt:  now,
+		LastSeenAt: now,
+	}
 
-		log.Error(msg, sl.Err(err))
+	if err := s.saveSession(ctx, sess); err != nil {
+		msg := "failed to create session"
+
+		log.Error(msg, sl.Err(err), slog.Any("session", sess))
 
 		return &proto.TokenResponse{
 			Meta: &proto.Response{
@@ -279,24 +272,13 @@ func (s *Service) Token(ctx context.Context, in *proto.TokenRequest) (*proto.Tok
 		}, nil
 	}
 
-	err = s.sessions.Set(ctx, access.Payload.ID, []byte(access.Token), s.access.TTL)
-	if err != nil {
-		msg := "failed to add session to storage"
-
-		log.Error(msg, sl.Err(err), slog.Any("access_token", access), slog.Any("request", in))
-	}
-
-	refresh, err := jwt.CreateToken(
-		&data.TokenPayload{
-			ID:     uuid.NewString(),
-			UserID: u.ID,
-			Email:  u.Email,
-		},
-		s.refresh.TTL,
-		s.refresh.PrivateKey,
-	)
+	pair, err := s.issuePair(ctx, data.TokenPayload{
+		UserID:   u.ID,
+		Email:    u.Email,
+		FamilyID: sess.ID,
+	})
 	if err != nil {
-		msg := "failed to create refresh token"
+		msg := "failed to issue tokens"
 
 		log.Error(msg, sl.Err(err))
 
@@ -308,24 +290,21 @@ func (s *Service) Token(ctx context.Context, in *proto.TokenRequest) (*proto.Tok
 		}, nil
 	}
 
-	err = s.sessions.Set(ctx, refresh.Payload.ID, []byte(refresh.Token), s.refresh.TTL)
-	if err != nil {
-		msg := "failed to store refresh token in storage"
-
-		log.Error(msg, sl.Err(err), slog.Any("refresh_token", refresh), slog.Any("request", in))
-	}
-
 	return &proto.TokenResponse{
 		Meta: &proto.Response{
 			Status: http.StatusOK,
 		},
-		AccessToken:  access.Token,
-		RefreshToken: refresh.Token,
+		AccessToken:  pair.access.Token,
+		RefreshToken: pair.refresh.Token,
 	}, nil
 }
 
+// Refresh rotates a refresh token, it returns a new pair of tokens and the given refresh token is spent.
+//
+// A spent refresh token being used again means it has leaked,
+// so all the tokens of its family are revoked.
 func (s *Service) Refresh(ctx context.Context, in *proto.RefreshRequest) (*proto.RefreshResponse, error) {
-	const op = "services.auth.Token"
+	const op = "services.auth.Refresh"
 
 	log := s.log.With("op", op)
 
@@ -342,7 +321,7 @@ func (s *Service) Refresh(ctx context.Context, in *proto.RefreshRequest) (*proto
 		}, nil
 	}
 
-	payload, err := jwt.ValidateToken(in.GetRefresh(), s.refresh.PublicKey)
+	payload, err := jwt.ValidateToken(in.GetRefresh(), s.refresh.keys.VerificationKeys()...)
 	if err != nil {
 		msg := "refresh token is invalid"
 
@@ -356,54 +335,127 @@ func (s *Service) Refresh(ctx context.Context, in *proto.RefreshRequest) (*proto
 		}, nil
 	}
 
-	_, err = s.sessions.Get(ctx, payload.ID)
+	revoked, err := s.isRevoked(ctx, payload.ID)
 	if err != nil {
-		msg := "refresh token is expired"
+		msg := "failed to check refresh token revocation"
 
-		log.Error(msg, sl.Err(err))
+		log.Error(msg, sl.Err(err), slog.Any("payload", payload))
+
+		return &proto.RefreshResponse{
+			Meta: &proto.Response{
+				Status: http.StatusInternalServerError,
+				Error:  msg,
+			},
+		}, nil
+	}
+	if revoked {
+		msg := "refresh token is revoked"
+
+		log.Error(msg, slog.Any("payload", payload))
 
 		return &proto.RefreshResponse{
 			Meta: &proto.Response{
 				Status: http.StatusForbidden,
-				Error:  err.Error(),
+				Error:  msg,
 			},
 		}, nil
 	}
 
-	access, err := jwt.CreateToken(
-		payload,
-		s.access.TTL,
-		s.access.PrivateKey,
-	)
+	family := payload.FamilyID
+	if family == "" {
+		family = payload.ID
+	}
+
+	// the token is spent and marked as spent at once, so its reuse is always detected.
+	err = s.sessions.Swap(ctx, payload.ID, spentPrefix+payload.ID, []byte(family), s.refresh.ttl)
 	if err != nil {
-		msg := "failed to create access token"
+		if !errors.Is(err, sessions.ErrNotFound) {
+			msg := "failed to spend refresh token"
 
-		log.Error(msg, sl.Err(err))
+			log.Error(msg, sl.Err(err), slog.Any("payload", payload))
+
+			return &proto.RefreshResponse{
+				Meta: &proto.Response{
+					Status: http.StatusInternalServerError,
+					Error:  msg,
+				},
+			}, nil
+		}
+
+		return s.refreshSpent(ctx, log, payload), nil
+	}
+
+	// the session record is gone for the tokens issued before the records were introduced,
+	// refreshing such a token starts the record over.
+	now := time.Now().UTC()
+	sess, err := s.findSession(ctx, family)
+	if err != nil {
+		if !errors.Is(err, sessions.ErrNotFound) {
+			msg := "failed to get session"
+
+			log.Error(msg, sl.Err(err), slog.Any("payload", payload))
+
+			return &proto.RefreshResponse{
+				Meta: &proto.Response{
+					Status: http.StatusInternalServerError,
+					Error:  msg,
+				},
+			}, nil
+		}
+
+		sess = data.Session{ID: family, UserID: payload.UserID, CreatedAt: now}
+	}
+
+	sess.LastSeenAt = now
+	if in.GetUserAgent() != "" {
+		sess.UserAgent = in.GetUserAgent()
+	}
+	if in.GetIp() != "" {
+		sess.IP = in.GetIp()
+	}
+
+	if err := s.saveSession(ctx, sess); err != nil {
+		msg := "failed to update session"
+
+		log.Error(msg, sl.Err(err), slog.Any("session", sess))
 
 		return &proto.RefreshResponse{
 			Meta: &proto.Response{
 				Status: http.StatusInternalServerError,
-				Error:  err.Error(),
+				Error:  msg,
 			},
 		}, nil
 	}
 
-	if err = s.sessions.Set(ctx, access.Payload.ID, []byte(access.Token), s.access.TTL); err != nil {
-		msg := "failed to add access token to storage"
+	pair, err := s.issuePair(ctx, data.TokenPayload{
+		UserID:   payload.UserID,
+		Email:    payload.Email,
+		FamilyID: family,
+	})
+	if err != nil {
+		msg := "failed to issue tokens"
 
-		log.Error(msg, sl.Err(err), slog.Any("access_token", access), slog.Any("request", in))
+		log.Error(msg, sl.Err(err))
+
+		return &proto.RefreshResponse{
+			Meta: &proto.Response{
+				Status: http.StatusInternalServerError,
+				Error:  msg,
+			},
+		}, nil
 	}
 
 	return &proto.RefreshResponse{
 		Meta: &proto.Response{
 			Status: http.StatusOK,
 		},
-		AccessToken: access.Token,
+		AccessToken:  pair.access.Token,
+		RefreshToken: pair.refresh.Token,
 	}, nil
 }
 
 func (s *Service) Verify(ctx context.Context, in *proto.VerifyRequest) (*proto.VerifyResponse, error) {
-	payload, err := jwt.ValidateToken(in.Token, s.access.PublicKey)
+	payload, err := jwt.ValidateToken(in.Token, s.access.keys.VerificationKeys()...)
 	if err != nil {
 		msg := "failed to valide request token"
 
@@ -414,6 +466,32 @@ func (s *Service) Verify(ctx context.Context, in *proto.VerifyRequest) (*proto.V
 		}, nil
 	}
 
+	revoked, err := s.isRevoked(ctx, payload.ID)
+	if err != nil {
+		msg := "failed to check token revocation"
+
+		s.log.Error(msg, sl.Err(err), slog.Any("payload", payload))
+
+		return &proto.VerifyResponse{
+			Meta: &proto.Response{
+				Status: http.StatusInternalServerError,
+				Error:  msg,
+			},
+		}, nil
+	}
+	if revoked {
+		msg := "access token is revoked"
+
+		s.log.Error(msg, slog.Any("payload", payload))
+
+		return &proto.VerifyResponse{
+			Meta: &proto.Response{
+				Status: http.StatusForbidden,
+				Error:  msg,
+			},
+		}, nil
+	}
+
 	_, err = s.sessions.Get(ctx, payload.ID)
 	if err != nil {
 		if errors.Is(err, sessions.ErrNotFound) {
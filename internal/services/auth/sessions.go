@@ -0,0 +1,225 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/pkg/sl"
+	"github.com/romankravchuk/eldorado/internal/pkg/validator"
+	"github.com/romankravchuk/eldorado/internal/services/auth/proto"
+	"github.com/romankravchuk/eldorado/internal/storages/sessions"
+)
+
+// sessionPrefix prefixes the keys of session records.
+// The index of a user holds the ids of its sessions.
+const sessionPrefix = "session:"
+
+// ListSessions returns the live sessions of the owner of a given access token, the last seen first.
+func (s *Service) ListSessions(ctx context.Context, in *proto.ListSessionsRequest) (*proto.ListSessionsResponse, error) {
+	const op = "services.auth.ListSessions"
+
+	log := s.log.With("op", op)
+
+	if err := validator.ValidateStruct(in); err != nil {
+		msg := "failed to validate request"
+
+		log.Error(msg, sl.Err(err))
+
+		return &proto.ListSessionsResponse{
+			Meta: &proto.Response{
+				Status: http.StatusBadRequest,
+				Error:  err.Error(),
+			},
+		}, nil
+	}
+
+	access, resp := s.authenticate(ctx, log, in.GetAccess())
+	if resp != nil {
+		return &proto.ListSessionsResponse{Meta: resp}, nil
+	}
+
+	ids, err := s.sessions.Index(ctx, access.UserID)
+	if err != nil {
+		msg := "failed to get user sessions"
+
+		log.Error(msg, sl.Err(err), slog.Any("payload", access))
+
+		return &proto.ListSessionsResponse{
+			Meta: &proto.Response{
+				Status: http.StatusInternalServerError,
+				Error:  msg,
+			},
+		}, nil
+	}
+
+	var (
+		found []data.Session
+		stale []string
+	)
+	for _, id := range ids {
+		sess, err := s.findSession(ctx, id)
+		if err != nil {
+			if errors.Is(err, sessions.ErrNotFound) {
+				stale = append(stale, id)
+				continue
+			}
+
+			msg := "failed to get session"
+
+			log.Error(msg, sl.Err(err), slog.Any("payload", access), slog.String("session_id", id))
+
+			return &proto.ListSessionsResponse{
+				Meta: &proto.Response{
+					Status: http.StatusInternalServerError,
+					Error:  msg,
+				},
+			}, nil
+		}
+
+		found = append(found, sess)
+	}
+
+	if err := s.sessions.RemoveFromIndex(ctx, access.UserID, stale...); err != nil {
+		msg := "failed to remove stale sessions from index"
+
+		log.Error(msg, sl.Err(err), slog.Any("payload", access))
+	}
+
+	slices.SortFunc(found, func(a, b data.Session) int {
+		return b.LastSeenAt.Compare(a.LastSeenAt)
+	})
+
+	out := make([]*proto.Session, len(found))
+	for i, sess := range found {
+		out[i] = &proto.Session{
+			Id:         sess.ID,
+			UserAgent:  sess.UserAgent,
+			Ip:         sess.IP,
+			CreatedAt:  sess.CreatedAt.Format(time.RFC3339),
+			LastSeenAt: sess.LastSeenAt.Format(time.RFC3339),
+			Current:    sess.ID == access.FamilyID,
+		}
+	}
+
+	return &proto.ListSessionsResponse{
+		Meta: &proto.Response{
+			Status: http.StatusOK,
+		},
+		Sessions: out,
+	}, nil
+}
+
+// RevokeSession ends a session of the owner of a given access token.
+func (s *Service) RevokeSession(ctx context.Context, in *proto.RevokeSessionRequest) (*proto.Response, error) {
+	const op = "services.auth.RevokeSession"
+
+	log := s.log.With("op", op)
+
+	if err := validator.ValidateStruct(in); err != nil {
+		msg := "failed to validate request"
+
+		log.Error(msg, sl.Err(err))
+
+		return &proto.Response{
+			Status: http.StatusBadRequest,
+			Error:  err.Error(),
+		}, nil
+	}
+
+	access, resp := s.authenticate(ctx, log, in.GetAccess())
+	if resp != nil {
+		return resp, nil
+	}
+
+	sess, err := s.findSession(ctx, in.GetId())
+	if err != nil && !errors.Is(err, sessions.ErrNotFound) {
+		msg := "failed to get session"
+
+		log.Error(msg, sl.Err(err), slog.Any("payload", access), slog.String("session_id", in.GetId()))
+
+		return &proto.Response{
+			Status: http.StatusInternalServerError,
+			Error:  msg,
+		}, nil
+	}
+	if err != nil || sess.UserID != access.UserID {
+		msg := "session not found"
+
+		log.Error(msg, slog.Any("payload", access), slog.String("session_id", in.GetId()))
+
+		return &proto.Response{
+			Status: http.StatusNotFound,
+			Error:  msg,
+		}, nil
+	}
+
+	if err := s.endSession(ctx, access.UserID, sess.ID); err != nil {
+		msg := "failed to end session"
+
+		log.Error(msg, sl.Err(err), slog.Any("payload", access), slog.String("session_id", sess.ID))
+
+		return &proto.Response{
+			Status: http.StatusInternalServerError,
+			Error:  msg,
+		}, nil
+	}
+
+	return &proto.Response{
+		Status: http.StatusOK,
+	}, nil
+}
+
+// saveSession stores a session record for the life of a refresh token.
+func (s *Service) saveSession(ctx context.Context, sess data.Session) error {
+	raw, err := json.Marshal(sess)
+	if err != nil {
+		return err
+	}
+
+	if err := s.sessions.Set(ctx, sessionPrefix+sess.ID, raw, s.refresh.ttl); err != nil {
+		return err
+	}
+
+	return s.sessions.AddToIndex(ctx, sess.UserID, sess.ID, s.refresh.ttl)
+}
+
+func (s *Service) findSession(ctx context.Context, id string) (data.Session, error) {
+	raw, err := s.sessions.Get(ctx, sessionPrefix+id)
+	if err != nil {
+		return data.Session{}, err
+	}
+
+	var sess data.Session
+	if err := json.Unmarshal(raw, &sess); err != nil {
+		return data.Session{}, err
+	}
+	return sess, nil
+}
+
+// endSession revokes the tokens of a session and drops its record.
+func (s *Service) endSession(ctx context.Context, userID, id string) error {
+	ids, err := s.sessions.Index(ctx, familyPrefix+id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.revoke(ctx, ids...); err != nil {
+		return err
+	}
+
+	if err := s.sessions.RemoveFromIndex(ctx, familyPrefix+id, ids...); err != nil {
+		return err
+	}
+
+	if err := s.sessions.Delete(ctx, sessionPrefix+id); err != nil {
+		return err
+	}
+
+	return s.sessions.RemoveFromIndex(ctx, userID, id)
+}
@@ -14,6 +14,9 @@ import (
 	"github.com/robfig/cron/v3"
 	"github.com/romankravchuk/eldorado/internal/services"
 	"github.com/romankravchuk/eldorado/internal/storages"
+	"github.com/romankravchuk/eldorado/internal/storages/blobs"
+	"github.com/romankravchuk/eldorado/internal/storages/blobs/local"
+	"github.com/romankravchuk/eldorado/internal/storages/blobs/s3"
 	"github.com/romankravchuk/eldorado/internal/storages/tasks"
 	"github.com/romankravchuk/eldorado/internal/storages/tasks/pg"
 )
@@ -92,10 +95,56 @@ func WithPosgresTasksStorage(url string) Option {
 			return err
 		}
 
+		attachments, err := pg.NewAttachments(db)
+		if err != nil {
+			return err
+		}
+
+		if err := WithAttachmentStorage(attachments)(s); err != nil {
+			return err
+		}
+
 		return WithTasksStorage(tasks)(s)
 	}
 }
 
+func WithAttachmentStorage(attachments tasks.AttachmentStorage) Option {
+	return func(s *Service) error {
+		s.attachments = attachments
+		return nil
+	}
+}
+
+// WithBlobStorage makes the trash purge delete the blobs of attachments of the purged tasks.
+func WithBlobStorage(blobs blobs.Storage) Option {
+	return func(s *Service) error {
+		s.blobs = blobs
+		return nil
+	}
+}
+
+func WithLocalBlobStorage(dir string) Option {
+	return func(s *Service) error {
+		blobs, err := local.New(dir)
+		if err != nil {
+			return err
+		}
+
+		return WithBlobStorage(blobs)(s)
+	}
+}
+
+func WithS3BlobStorage(cfg s3.Config) Option {
+	return func(s *Service) error {
+		blobs, err := s3.New(cfg)
+		if err != nil {
+			return err
+		}
+
+		return WithBlobStorage(blobs)(s)
+	}
+}
+
 func WithCron(schedule string) Option {
 	return func(s *Service) error {
 		s.cron = cron.New()
@@ -104,6 +153,19 @@ func WithCron(schedule string) Option {
 	}
 }
 
+// WithTrashPurge schedules a job which permanently deletes the tasks kept in the trash longer than retention.
+func WithTrashPurge(schedule string, retention time.Duration) Option {
+	return func(s *Service) error {
+		if retention <= 0 {
+			return errors.New("trash retention must be positive")
+		}
+
+		s.purgeSchedule = schedule
+		s.retention = retention
+		return nil
+	}
+}
+
 type Service struct {
 	conn *amqp.Connection
 	ch   *amqp.Channel
@@ -114,7 +176,12 @@ type Service struct {
 	cron     *cron.Cron
 	schedule string
 
-	tasks tasks.Storage
+	purgeSchedule string
+	retention     time.Duration
+
+	tasks       tasks.Storage
+	attachments tasks.AttachmentStorage
+	blobs       blobs.Storage
 }
 
 func New(opts ...Option) (*Service, error) {
@@ -143,35 +210,103 @@ func (s *Service) Start() error {
 	if err != nil {
 		return err
 	}
+
+	if s.retention > 0 {
+		_, err = s.cron.AddFunc(s.purgeSchedule, func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			purged, err := s.tasks.PurgeDeleted(ctx, time.Now().Add(-s.retention))
+			if err != nil {
+				s.log.Error("failed to purge trash", slog.String("error", err.Error()))
+				return
+			}
+			s.log.Info("trash purged", slog.Int64("tasks", purged))
+
+			if s.blobs == nil {
+				return
+			}
+
+			attachments, err := tasks.PurgeOrphans(ctx, s.attachments, s.blobs)
+			if err != nil {
+				s.log.Error("failed to purge attachments", slog.String("error", err.Error()))
+				return
+			}
+			s.log.Info("attachments purged", slog.Int("attachments", attachments))
+		})
+		if err != nil {
+			return err
+		}
+	}
+
 	s.cron.Run()
 	return nil
 }
 
 func (s *Service) sendStatistic(ctx context.Context) error {
-	tasks, err := s.tasks.UncompletedStatistic(ctx)
+	uncompleted, err := s.tasks.UncompletedStatistic(ctx)
+	if err != nil {
+		return err
+	}
+
+	overdue, err := s.tasks.OverdueStatistic(ctx)
+	if err != nil {
+		return err
+	}
+
+	projects, err := s.tasks.ProjectStatistic(ctx)
 	if err != nil {
 		return err
 	}
 
-	if len(tasks) == 0 {
+	assigned, err := s.tasks.AssignedStatistic(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(uncompleted) == 0 && len(overdue) == 0 && len(projects) == 0 && len(assigned) == 0 {
 		return nil
 	}
 
 	var buff bytes.Buffer
-	tasksMap := make(map[string][]string, 0)
+	digests := make(map[string]*digest, 0)
+
+	for _, task := range uncompleted {
+		d := digestFor(digests, task.Email)
+		d.Uncompleted = append(d.Uncompleted, task.Title)
+	}
+
+	for _, task := range overdue {
+		d := digestFor(digests, task.Email)
+		d.Overdue = append(d.Overdue, overdueTask{
+			Title: task.Title,
+			DueAt: task.DueAt.Format(time.DateTime),
+		})
+	}
+
+	for _, task := range assigned {
+		d := digestFor(digests, task.Email)
+		d.Assigned = append(d.Assigned, task.Title)
+	}
 
-	for _, task := range tasks {
-		tasksMap[task.Email] = append(tasksMap[task.Email], task.Title)
+	for _, project := range projects {
+		d := digestFor(digests, project.Email)
+		d.Projects = append(d.Projects, projectSummary{
+			Name:      project.Project,
+			Total:     project.Total,
+			Completed: project.Completed,
+			Overdue:   project.Overdue,
+		})
 	}
 
-	for e, tt := range tasksMap {
+	for e, d := range digests {
 		buff.Write([]byte(fmt.Sprintf(
 			headerFormat,
 			e,
 			mimeHeaders,
 		)))
 
-		if err := tmpl.Execute(&buff, tt); err != nil {
+		if err := tmpl.Execute(&buff, d); err != nil {
 			return err
 		}
 	}
@@ -192,3 +327,32 @@ func (s *Service) sendStatistic(ctx context.Context) error {
 
 	return nil
 }
+
+// digest is a statistic of a single user rendered by the email template.
+type digest struct {
+	Uncompleted []string
+	Overdue     []overdueTask
+	Assigned    []string
+	Projects    []projectSummary
+}
+
+type overdueTask struct {
+	Title string
+	DueAt string
+}
+
+type projectSummary struct {
+	Name      string
+	Total     int
+	Completed int
+	Overdue   int
+}
+
+func digestFor(digests map[string]*digest, email string) *digest {
+	d, ok := digests[email]
+	if !ok {
+		d = &digest{}
+		digests[email] = d
+	}
+	return d
+}
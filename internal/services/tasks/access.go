@@ -0,0 +1,136 @@
+package tasks
+
+import (
+	"context"
+
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
+)
+
+// authorize checks that a given user may change a task and returns its current state.
+//
+// Tasks out of projects are changed only by their creators, tasks of a project by
+// its owner and editors.
+// If the task is not visible to the user returns tasks.ErrNotFound, if the user
+// may not change it returns tasks.ErrForbidden.
+func (s *Service) authorize(ctx context.Context, userID, id string) (data.Task, error) {
+	if userID == "" {
+		return data.Task{}, tasks.ErrForbidden
+	}
+
+	t, err := s.tasks.FindByID(ctx, userID, id)
+	if err != nil {
+		return data.Task{}, err
+	}
+
+	if err := s.authorizeTask(ctx, userID, t); err != nil {
+		return data.Task{}, err
+	}
+
+	return t, nil
+}
+
+// authorizeCompletion checks that a given user may complete or reopen a task
+// and returns its current state.
+//
+// Besides the users who may change the task its assignee may complete it.
+func (s *Service) authorizeCompletion(ctx context.Context, userID, id string) (data.Task, error) {
+	if userID == "" {
+		return data.Task{}, tasks.ErrForbidden
+	}
+
+	t, err := s.tasks.FindByID(ctx, userID, id)
+	if err != nil {
+		return data.Task{}, err
+	}
+
+	if t.AssigneeID != nil && *t.AssigneeID == userID {
+		return t, nil
+	}
+
+	if err := s.authorizeTask(ctx, userID, t); err != nil {
+		return data.Task{}, err
+	}
+
+	return t, nil
+}
+
+// authorizeTask checks that a given user may change a visible task.
+func (s *Service) authorizeTask(ctx context.Context, userID string, t data.Task) error {
+	if t.ProjectID == nil {
+		if t.UserID != userID {
+			return tasks.ErrForbidden
+		}
+		return nil
+	}
+
+	return s.authorizeProject(ctx, userID, *t.ProjectID)
+}
+
+// authorizeProject checks that a given user may create and change tasks of a project.
+//
+// If the user is not a member returns projects.ErrNotFound, if the user is a viewer
+// returns tasks.ErrForbidden.
+func (s *Service) authorizeProject(ctx context.Context, userID, projectID string) error {
+	p, err := s.projects.FindByID(ctx, userID, projectID)
+	if err != nil {
+		return err
+	}
+
+	if !data.CanEditTasks(p.Role) {
+		return tasks.ErrForbidden
+	}
+
+	return nil
+}
+
+// authorizeOwner checks that a given user owns a project.
+//
+// If the user is not a member returns projects.ErrNotFound, if the user is not
+// the owner returns tasks.ErrForbidden.
+func (s *Service) authorizeOwner(ctx context.Context, userID, projectID string) error {
+	p, err := s.projects.FindByID(ctx, userID, projectID)
+	if err != nil {
+		return err
+	}
+
+	if p.Role != data.RoleOwner {
+		return tasks.ErrForbidden
+	}
+
+	return nil
+}
+
+// audience returns ids of the users who see tasks of given projects.
+//
+// A nil project stands for the tasks out of projects, they are seen only by
+// a given creator.
+func (s *Service) audience(ctx context.Context, creatorID string, projectIDs ...*string) ([]string, error) {
+	seen := make(map[string]bool)
+	var userIDs []string
+
+	add := func(userID string) {
+		if !seen[userID] {
+			seen[userID] = true
+			userIDs = append(userIDs, userID)
+		}
+	}
+
+	for _, projectID := range projectIDs {
+		if projectID == nil {
+			add(creatorID)
+			continue
+		}
+
+		members, err := s.projects.Members(ctx, *projectID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, m := range members {
+			add(m.UserID)
+		}
+	}
+
+	return userIDs, nil
+}
@@ -0,0 +1,79 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/google/uuid"
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/storages/blobs"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
+)
+
+func (s *Service) Attachments(ctx context.Context, userID, taskID string) ([]data.TaskAttachment, error) {
+	return s.attachments.FindByTaskID(ctx, userID, taskID)
+}
+
+// CreateAttachment stores a.Size bytes of r as a new attachment of a task.
+//
+// The blob is written before the record, a blob without a record is deleted.
+func (s *Service) CreateAttachment(ctx context.Context, userID, taskID string, a data.TaskAttachment, r io.Reader) (data.TaskAttachment, error) {
+	if _, err := s.authorize(ctx, userID, taskID); err != nil {
+		return data.TaskAttachment{}, err
+	}
+
+	a.ID = uuid.NewString()
+	a.TaskID = taskID
+	a.UploaderID = userID
+	a.Key = "attachments/" + a.ID
+
+	if err := s.blobs.Put(ctx, a.Key, r, a.Size, a.ContentType); err != nil {
+		return data.TaskAttachment{}, err
+	}
+
+	if err := s.attachments.Save(ctx, userID, &a); err != nil {
+		if delErr := s.blobs.Delete(ctx, a.Key); delErr != nil {
+			return data.TaskAttachment{}, errors.Join(err, delErr)
+		}
+
+		return data.TaskAttachment{}, err
+	}
+
+	return a, nil
+}
+
+// OpenAttachment returns an attachment of a task and its content, the caller closes the content.
+//
+// If the blob of the attachment is missing returns tasks.ErrAttachmentNotFound.
+func (s *Service) OpenAttachment(ctx context.Context, userID, taskID, id string) (data.TaskAttachment, io.ReadSeekCloser, error) {
+	a, err := s.attachments.FindByID(ctx, userID, taskID, id)
+	if err != nil {
+		return data.TaskAttachment{}, nil, err
+	}
+
+	content, err := s.blobs.Open(ctx, a.Key)
+	if err != nil {
+		if errors.Is(err, blobs.ErrNotFound) {
+			return data.TaskAttachment{}, nil, tasks.ErrAttachmentNotFound
+		}
+
+		return data.TaskAttachment{}, nil, err
+	}
+
+	return a, content, nil
+}
+
+// DeleteAttachment detaches an attachment from its task and deletes its blob.
+func (s *Service) DeleteAttachment(ctx context.Context, userID, taskID, id string) error {
+	if _, err := s.authorize(ctx, userID, taskID); err != nil {
+		return err
+	}
+
+	if err := s.attachments.Detach(ctx, userID, taskID, id); err != nil {
+		return err
+	}
+
+	_, err := tasks.PurgeOrphans(ctx, s.attachments, s.blobs)
+	return err
+}
@@ -0,0 +1,139 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
+)
+
+// Batch executes given operations of a user in a single transaction.
+//
+// Operations are checked like the single ones before the transaction starts.
+// If atomic is set the first failed operation fails the whole batch with *tasks.OpError,
+// otherwise errors are reported in the results of the failed operations.
+// The caches of all affected users are invalidated once after the batch.
+func (s *Service) Batch(ctx context.Context, userID string, ops []data.TaskOp, atomic bool) ([]data.TaskOpResult, error) {
+	if userID == "" {
+		return nil, tasks.ErrForbidden
+	}
+
+	results := make([]data.TaskOpResult, len(ops))
+
+	// checked operations and their indexes in ops.
+	checked := make([]data.TaskOp, 0, len(ops))
+	indexes := make([]int, 0, len(ops))
+
+	for i, op := range ops {
+		if err := s.checkOp(ctx, userID, &op); err != nil {
+			if atomic {
+				return nil, &tasks.OpError{Index: i, Err: err}
+			}
+
+			results[i].Err = err
+			continue
+		}
+
+		checked = append(checked, op)
+		indexes = append(indexes, i)
+	}
+
+	done, err := s.tasks.Batch(ctx, userID, checked, atomic)
+	if err != nil {
+		var opErr *tasks.OpError
+		if errors.As(err, &opErr) {
+			opErr.Index = indexes[opErr.Index]
+		}
+
+		return nil, err
+	}
+
+	for i, r := range done {
+		results[indexes[i]] = r
+	}
+
+	if err := s.invalidateBatch(ctx, results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// checkOp checks a batch operation of a given user and fills its defaults.
+func (s *Service) checkOp(ctx context.Context, userID string, op *data.TaskOp) error {
+	switch op.Kind {
+	case data.TaskOpCreate:
+		if op.Task.Priority == "" {
+			op.Task.Priority = data.PriorityNormal
+		}
+
+		if op.Task.ProjectID != nil {
+			return s.authorizeProject(ctx, userID, *op.Task.ProjectID)
+		}
+	case data.TaskOpUpdate:
+		if op.Patch.ProjectID != nil {
+			return s.authorizeProject(ctx, userID, *op.Patch.ProjectID)
+		}
+	}
+
+	return nil
+}
+
+// invalidateBatch drops the cached tasks changed by a batch and the cached task lists
+// of all users who see them, every user is invalidated once.
+func (s *Service) invalidateBatch(ctx context.Context, results []data.TaskOpResult) error {
+	var (
+		userIDs, ids []string
+		seen         = make(map[string]bool)
+		audiences    = make(map[string][]string)
+	)
+
+	addUser := func(userID string) {
+		if !seen["user:"+userID] {
+			seen["user:"+userID] = true
+			userIDs = append(userIDs, userID)
+		}
+	}
+
+	for _, r := range results {
+		if r.Err != nil {
+			continue
+		}
+
+		for _, t := range []*data.Task{&r.Task, r.Previous} {
+			if t == nil || t.ID == "" {
+				continue
+			}
+
+			if !seen["task:"+t.ID] {
+				seen["task:"+t.ID] = true
+				ids = append(ids, t.ID)
+			}
+
+			if t.AssigneeID != nil {
+				addUser(*t.AssigneeID)
+			}
+
+			if t.ProjectID == nil {
+				addUser(t.UserID)
+				continue
+			}
+
+			audience, ok := audiences[*t.ProjectID]
+			if !ok {
+				var err error
+				if audience, err = s.audience(ctx, t.UserID, t.ProjectID); err != nil {
+					return err
+				}
+				audiences[*t.ProjectID] = audience
+			}
+
+			for _, userID := range audience {
+				addUser(userID)
+			}
+		}
+	}
+
+	return s.invalidateTasks(ctx, userIDs, ids...)
+}
@@ -0,0 +1,85 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/storages/users"
+)
+
+// Comments returns a page of the discussion of a task visible to q.UserID with the authors resolved.
+//
+// Comments are not cached, they are not part of the task representation.
+func (s *Service) Comments(ctx context.Context, q data.TaskCommentsQuery) (data.TaskCommentsPage, error) {
+	page, err := s.comments.FindByTaskID(ctx, q)
+	if err != nil {
+		return data.TaskCommentsPage{}, err
+	}
+
+	if err := s.resolveAuthors(ctx, page.Comments); err != nil {
+		return data.TaskCommentsPage{}, err
+	}
+
+	return page, nil
+}
+
+func (s *Service) CreateComment(ctx context.Context, userID, taskID, content string) (data.TaskComment, error) {
+	c := data.TaskComment{TaskID: taskID, AuthorID: userID, Content: content}
+
+	if err := s.comments.Save(ctx, &c); err != nil {
+		return data.TaskComment{}, err
+	}
+
+	resolved := []data.TaskComment{c}
+	if err := s.resolveAuthors(ctx, resolved); err != nil {
+		return data.TaskComment{}, err
+	}
+
+	return resolved[0], nil
+}
+
+func (s *Service) UpdateComment(ctx context.Context, userID, taskID, id, content string) (data.TaskComment, error) {
+	c, err := s.comments.Update(ctx, userID, taskID, id, content)
+	if err != nil {
+		return data.TaskComment{}, err
+	}
+
+	resolved := []data.TaskComment{c}
+	if err := s.resolveAuthors(ctx, resolved); err != nil {
+		return data.TaskComment{}, err
+	}
+
+	return resolved[0], nil
+}
+
+func (s *Service) DeleteComment(ctx context.Context, userID, taskID, id string) error {
+	return s.comments.Delete(ctx, userID, taskID, id)
+}
+
+// resolveAuthors fills the author metadata of given comments, every author is looked up once.
+//
+// Authors who are not found anymore are left empty.
+func (s *Service) resolveAuthors(ctx context.Context, comments []data.TaskComment) error {
+	authors := make(map[string]data.User)
+
+	for i := range comments {
+		id := comments[i].AuthorID
+		if id == "" {
+			continue
+		}
+
+		u, ok := authors[id]
+		if !ok {
+			var err error
+			if u, err = s.users.FindByID(ctx, id); err != nil && !errors.Is(err, users.ErrNotFound) {
+				return err
+			}
+			authors[id] = u
+		}
+
+		comments[i].AuthorUsername, comments[i].AuthorEmail = u.Username, u.Email
+	}
+
+	return nil
+}
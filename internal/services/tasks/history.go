@@ -0,0 +1,14 @@
+package tasks
+
+import (
+	"context"
+
+	"github.com/romankravchuk/eldorado/internal/data"
+)
+
+// History returns a page of events of a task visible to q.UserID, the newest first.
+//
+// The history is never cached, it grows with every change of the task.
+func (s *Service) History(ctx context.Context, q data.TaskEventsQuery) ([]data.TaskEvent, error) {
+	return s.tasks.History(ctx, q)
+}
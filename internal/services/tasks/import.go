@@ -0,0 +1,131 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/storages/projects"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
+)
+
+// Export calls f with every active task visible to a given user, the oldest first.
+//
+// Tasks are read page by page, so f may stream them. Exports are not cached.
+func (s *Service) Export(ctx context.Context, userID string, f func(t data.Task) error) error {
+	q := data.TasksQuery{
+		UserID: userID,
+		Limit:  data.TasksMaxLimit,
+		Sort:   data.TasksSortCreatedOn,
+		Order:  data.OrderAsc,
+	}
+
+	for {
+		page, err := s.tasks.FindByUserID(ctx, q)
+		if err != nil {
+			return err
+		}
+
+		for _, t := range page.Tasks {
+			if err := f(t); err != nil {
+				return err
+			}
+		}
+
+		if page.NextCursor == "" {
+			return nil
+		}
+		q.Cursor = page.NextCursor
+	}
+}
+
+// Import saves tasks read from a file for a given user in a single transaction.
+//
+// Rows are checked like the created tasks before the transaction starts. A row read
+// with an error or failing the checks is reported in its result and not imported.
+// If dryRun is set nothing is saved, the results show what would be imported.
+func (s *Service) Import(ctx context.Context, userID string, rows []data.TaskImport, dryRun bool) ([]data.TaskImportResult, error) {
+	if userID == "" {
+		return nil, tasks.ErrForbidden
+	}
+
+	results := make([]data.TaskImportResult, len(rows))
+
+	// checked rows and their indexes in rows.
+	checked := make([]data.TaskImport, 0, len(rows))
+	indexes := make([]int, 0, len(rows))
+
+	// authorized maps ids of the projects seen in the file to their check errors.
+	authorized := make(map[string]error)
+
+	for i, row := range rows {
+		if row.Err == nil {
+			err := s.checkImport(ctx, userID, &row.Task, authorized)
+			if err != nil && !isRowError(err) {
+				return nil, err
+			}
+			row.Err = err
+		}
+
+		if row.Err != nil {
+			results[i].Err = row.Err
+			continue
+		}
+
+		checked = append(checked, row)
+		indexes = append(indexes, i)
+	}
+
+	done, err := s.tasks.Import(ctx, userID, checked, dryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	created := make([]data.TaskOpResult, 0, len(done))
+	for i, r := range done {
+		results[indexes[i]] = r
+
+		if r.Err == nil && !r.Duplicate {
+			created = append(created, data.TaskOpResult{Task: r.Task})
+		}
+	}
+
+	if !dryRun {
+		if err := s.invalidateBatch(ctx, created); err != nil {
+			return nil, err
+		}
+	}
+
+	return results, nil
+}
+
+// isRowError reports whether an error of checkImport fails a single row and not the whole import.
+func isRowError(err error) bool {
+	return errors.Is(err, tasks.ErrInvalidRecurrence) || errors.Is(err, tasks.ErrForbidden) || errors.Is(err, projects.ErrNotFound)
+}
+
+// checkImport checks an imported task of a given user and fills its defaults.
+func (s *Service) checkImport(ctx context.Context, userID string, t *data.Task, authorized map[string]error) error {
+	if t.Priority == "" {
+		t.Priority = data.PriorityNormal
+	}
+
+	if err := checkRecurrence(t.Recurrence, t.DueAt); err != nil {
+		return err
+	}
+	if t.Recurrence != "" {
+		t.RecurrenceStart = t.DueAt
+	}
+
+	if t.ProjectID == nil {
+		return nil
+	}
+
+	err, ok := authorized[*t.ProjectID]
+	if !ok {
+		err = s.authorizeProject(ctx, userID, *t.ProjectID)
+		authorized[*t.ProjectID] = err
+	}
+
+	return err
+}
@@ -0,0 +1,79 @@
+package tasks
+
+import (
+	"context"
+
+	"github.com/romankravchuk/eldorado/internal/data"
+)
+
+func (s *Service) Items(ctx context.Context, userID, taskID string) ([]data.TaskItem, error) {
+	return s.items.FindByTaskID(ctx, userID, taskID)
+}
+
+func (s *Service) CreateItem(ctx context.Context, userID, taskID string, item data.TaskItem) (data.TaskItem, error) {
+	t, err := s.authorize(ctx, userID, taskID)
+	if err != nil {
+		return data.TaskItem{}, err
+	}
+
+	item.TaskID = taskID
+
+	if err := s.items.Save(ctx, userID, &item); err != nil {
+		return data.TaskItem{}, err
+	}
+
+	if err := s.invalidateTask(ctx, t); err != nil {
+		return data.TaskItem{}, err
+	}
+
+	return item, nil
+}
+
+func (s *Service) PatchItem(ctx context.Context, userID, taskID, id string, p data.TaskItemPatch) (data.TaskItem, error) {
+	t, err := s.authorize(ctx, userID, taskID)
+	if err != nil {
+		return data.TaskItem{}, err
+	}
+
+	item, err := s.items.Patch(ctx, userID, taskID, id, p)
+	if err != nil {
+		return data.TaskItem{}, err
+	}
+
+	if err := s.invalidateTask(ctx, t); err != nil {
+		return data.TaskItem{}, err
+	}
+
+	return item, nil
+}
+
+func (s *Service) DeleteItem(ctx context.Context, userID, taskID, id string) error {
+	t, err := s.authorize(ctx, userID, taskID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.items.Delete(ctx, userID, taskID, id); err != nil {
+		return err
+	}
+
+	return s.invalidateTask(ctx, t)
+}
+
+func (s *Service) MoveItem(ctx context.Context, userID, taskID, id string, m data.TaskMove) (data.TaskItem, error) {
+	t, err := s.authorize(ctx, userID, taskID)
+	if err != nil {
+		return data.TaskItem{}, err
+	}
+
+	item, err := s.items.Move(ctx, userID, taskID, id, m)
+	if err != nil {
+		return data.TaskItem{}, err
+	}
+
+	if err := s.invalidateTask(ctx, t); err != nil {
+		return data.TaskItem{}, err
+	}
+
+	return item, nil
+}
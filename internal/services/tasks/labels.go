@@ -0,0 +1,119 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
+)
+
+func (s *Service) Labels(ctx context.Context, userID string) ([]data.Label, error) {
+	return s.labels.FindByUserID(ctx, userID)
+}
+
+func (s *Service) CreateLabel(ctx context.Context, userID string, l data.Label) (data.Label, error) {
+	if userID == "" {
+		return data.Label{}, tasks.ErrForbidden
+	}
+
+	l.UserID = userID
+
+	if err := s.labels.Save(ctx, &l); err != nil {
+		return data.Label{}, err
+	}
+
+	return l, nil
+}
+
+func (s *Service) PatchLabel(ctx context.Context, userID, id string, p data.LabelPatch) (data.Label, error) {
+	if userID == "" {
+		return data.Label{}, tasks.ErrForbidden
+	}
+
+	l, taskIDs, err := s.labels.Patch(ctx, userID, id, p)
+	if err != nil {
+		return data.Label{}, err
+	}
+
+	if err := s.invalidateLabeled(ctx, userID, taskIDs); err != nil {
+		return data.Label{}, err
+	}
+
+	return l, nil
+}
+
+func (s *Service) DeleteLabel(ctx context.Context, userID, id string) error {
+	if userID == "" {
+		return tasks.ErrForbidden
+	}
+
+	taskIDs, err := s.labels.Delete(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	return s.invalidateLabeled(ctx, userID, taskIDs)
+}
+
+// AttachLabel attaches a label to a task and returns the updated task.
+func (s *Service) AttachLabel(ctx context.Context, userID, taskID, id string) (data.Task, error) {
+	t, err := s.authorize(ctx, userID, taskID)
+	if err != nil {
+		return data.Task{}, err
+	}
+
+	if err := s.labels.Attach(ctx, userID, taskID, id); err != nil {
+		return data.Task{}, err
+	}
+
+	if err := s.invalidateTask(ctx, t); err != nil {
+		return data.Task{}, err
+	}
+
+	return s.tasks.FindByID(ctx, userID, taskID)
+}
+
+// DetachLabel detaches a label from a task and returns the updated task.
+func (s *Service) DetachLabel(ctx context.Context, userID, taskID, id string) (data.Task, error) {
+	t, err := s.authorize(ctx, userID, taskID)
+	if err != nil {
+		return data.Task{}, err
+	}
+
+	if err := s.labels.Detach(ctx, userID, taskID, id); err != nil {
+		return data.Task{}, err
+	}
+
+	if err := s.invalidateTask(ctx, t); err != nil {
+		return data.Task{}, err
+	}
+
+	return s.tasks.FindByID(ctx, userID, taskID)
+}
+
+// invalidateLabeled drops cached tasks a changed label of a given user is attached to.
+//
+// The tasks may be shared with other members of their projects, so each of them
+// is invalidated for all users who see it.
+func (s *Service) invalidateLabeled(ctx context.Context, userID string, taskIDs []string) error {
+	if err := s.invalidateList(ctx, userID); err != nil {
+		return err
+	}
+
+	for _, id := range taskIDs {
+		t, err := s.tasks.FindByID(ctx, userID, id)
+		if errors.Is(err, tasks.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := s.invalidateTask(ctx, t); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
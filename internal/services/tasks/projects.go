@@ -0,0 +1,157 @@
+package tasks
+
+import (
+	"context"
+
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
+)
+
+func (s *Service) Projects(ctx context.Context, userID string) ([]data.Project, error) {
+	return s.projects.FindByUserID(ctx, userID)
+}
+
+func (s *Service) CreateProject(ctx context.Context, userID string, p data.Project) (data.Project, error) {
+	if userID == "" {
+		return data.Project{}, tasks.ErrForbidden
+	}
+
+	p.UserID = userID
+
+	if err := s.projects.Save(ctx, &p); err != nil {
+		return data.Project{}, err
+	}
+
+	return p, nil
+}
+
+func (s *Service) PatchProject(ctx context.Context, userID, id string, p data.ProjectPatch) (data.Project, error) {
+	if userID == "" {
+		return data.Project{}, tasks.ErrForbidden
+	}
+
+	if err := s.authorizeOwner(ctx, userID, id); err != nil {
+		return data.Project{}, err
+	}
+
+	project, err := s.projects.Patch(ctx, userID, id, p)
+	if err != nil {
+		return data.Project{}, err
+	}
+
+	// archiving hides the project tasks from the default lists of all members.
+	if p.IsArchived != nil {
+		audience, err := s.audience(ctx, userID, &id)
+		if err != nil {
+			return data.Project{}, err
+		}
+
+		if err := s.invalidateTasks(ctx, audience); err != nil {
+			return data.Project{}, err
+		}
+	}
+
+	return project, nil
+}
+
+func (s *Service) DeleteProject(ctx context.Context, userID, id string) error {
+	if userID == "" {
+		return tasks.ErrForbidden
+	}
+
+	if err := s.authorizeOwner(ctx, userID, id); err != nil {
+		return err
+	}
+
+	audience, err := s.audience(ctx, userID, &id)
+	if err != nil {
+		return err
+	}
+
+	taskIDs, err := s.projects.Delete(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	return s.invalidateTasks(ctx, audience, taskIDs...)
+}
+
+// Members returns members of a project visible to a given user.
+func (s *Service) Members(ctx context.Context, userID, projectID string) ([]data.ProjectMember, error) {
+	if _, err := s.projects.FindByID(ctx, userID, projectID); err != nil {
+		return nil, err
+	}
+
+	return s.projects.Members(ctx, projectID)
+}
+
+// InviteMember adds a registered user with a given email to the members of a project.
+//
+// Only the project owner may invite members.
+// If there is no user with the email returns users.ErrNotFound.
+func (s *Service) InviteMember(ctx context.Context, userID, projectID, email, role string) (data.ProjectMember, error) {
+	if userID == "" {
+		return data.ProjectMember{}, tasks.ErrForbidden
+	}
+
+	if err := s.authorizeOwner(ctx, userID, projectID); err != nil {
+		return data.ProjectMember{}, err
+	}
+
+	u, err := s.users.FindByEmail(ctx, email)
+	if err != nil {
+		return data.ProjectMember{}, err
+	}
+
+	m := data.ProjectMember{
+		ProjectID: projectID,
+		UserID:    u.ID,
+		Email:     u.Email,
+		Role:      role,
+	}
+
+	if err := s.projects.AddMember(ctx, &m); err != nil {
+		return data.ProjectMember{}, err
+	}
+
+	if err := s.invalidateList(ctx, u.ID); err != nil {
+		return data.ProjectMember{}, err
+	}
+
+	return m, nil
+}
+
+// UpdateMember changes a role of a project member, only the project owner may do it.
+func (s *Service) UpdateMember(ctx context.Context, userID, projectID, memberID, role string) (data.ProjectMember, error) {
+	if userID == "" {
+		return data.ProjectMember{}, tasks.ErrForbidden
+	}
+
+	if err := s.authorizeOwner(ctx, userID, projectID); err != nil {
+		return data.ProjectMember{}, err
+	}
+
+	return s.projects.UpdateMember(ctx, projectID, memberID, role)
+}
+
+// RemoveMember removes a member from a project.
+//
+// The project owner may remove any other member, other members may only leave the project.
+func (s *Service) RemoveMember(ctx context.Context, userID, projectID, memberID string) error {
+	if userID == "" {
+		return tasks.ErrForbidden
+	}
+
+	if userID != memberID {
+		if err := s.authorizeOwner(ctx, userID, projectID); err != nil {
+			return err
+		}
+	}
+
+	taskIDs, err := s.projects.RemoveMember(ctx, projectID, memberID)
+	if err != nil {
+		return err
+	}
+
+	return s.invalidateTasks(ctx, []string{memberID}, taskIDs...)
+}
@@ -0,0 +1,36 @@
+package tasks
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/romankravchuk/eldorado/internal/pkg/recurrence"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
+)
+
+// checkRecurrence validates a recurrence rule of a task due at a given time.
+//
+// A recurring task must have a due time, the next occurrences are counted from it.
+func checkRecurrence(rule string, dueAt *time.Time) error {
+	if rule == "" {
+		return nil
+	}
+
+	if dueAt == nil {
+		return tasks.ErrInvalidRecurrence
+	}
+
+	if err := recurrence.Validate(rule); err != nil {
+		return fmt.Errorf("%w: %w", tasks.ErrInvalidRecurrence, err)
+	}
+
+	return nil
+}
+
+// sameTime reports whether two optional times are equal.
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
@@ -3,15 +3,27 @@ package tasks
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/romankravchuk/eldorado/internal/data"
-	"github.com/romankravchuk/eldorado/internal/server/http/api"
 	"github.com/romankravchuk/eldorado/internal/storages"
+	"github.com/romankravchuk/eldorado/internal/storages/blobs"
+	"github.com/romankravchuk/eldorado/internal/storages/blobs/local"
+	"github.com/romankravchuk/eldorado/internal/storages/blobs/s3"
 	"github.com/romankravchuk/eldorado/internal/storages/cache"
 	"github.com/romankravchuk/eldorado/internal/storages/cache/redis"
+	"github.com/romankravchuk/eldorado/internal/storages/labels"
+	labelspg "github.com/romankravchuk/eldorado/internal/storages/labels/pg"
+	"github.com/romankravchuk/eldorado/internal/storages/projects"
+	projectspg "github.com/romankravchuk/eldorado/internal/storages/projects/pg"
 	"github.com/romankravchuk/eldorado/internal/storages/tasks"
 	"github.com/romankravchuk/eldorado/internal/storages/tasks/pg"
+	"github.com/romankravchuk/eldorado/internal/storages/users"
+	userspg "github.com/romankravchuk/eldorado/internal/storages/users/pg"
 )
 
 type Option func(*Service) error
@@ -23,6 +35,48 @@ func WithTaskStorage(tasks tasks.Storage) Option {
 	}
 }
 
+func WithItemStorage(items tasks.ItemStorage) Option {
+	return func(s *Service) error {
+		s.items = items
+		return nil
+	}
+}
+
+func WithCommentStorage(comments tasks.CommentStorage) Option {
+	return func(s *Service) error {
+		s.comments = comments
+		return nil
+	}
+}
+
+func WithAttachmentStorage(attachments tasks.AttachmentStorage) Option {
+	return func(s *Service) error {
+		s.attachments = attachments
+		return nil
+	}
+}
+
+func WithLabelStorage(labels labels.Storage) Option {
+	return func(s *Service) error {
+		s.labels = labels
+		return nil
+	}
+}
+
+func WithProjectStorage(projects projects.Storage) Option {
+	return func(s *Service) error {
+		s.projects = projects
+		return nil
+	}
+}
+
+func WithUserStorage(users users.Storage) Option {
+	return func(s *Service) error {
+		s.users = users
+		return nil
+	}
+}
+
 func WithTaskPostgresStorage(url string) Option {
 	return func(s *Service) error {
 		conn, err := storages.NewDBPool("postgres", url)
@@ -35,7 +89,92 @@ func WithTaskPostgresStorage(url string) Option {
 			return err
 		}
 
-		return WithTaskStorage(tasks)(s)
+		items, err := pg.NewItems(conn)
+		if err != nil {
+			return err
+		}
+
+		comments, err := pg.NewComments(conn)
+		if err != nil {
+			return err
+		}
+
+		attachments, err := pg.NewAttachments(conn)
+		if err != nil {
+			return err
+		}
+
+		labels, err := labelspg.New(conn)
+		if err != nil {
+			return err
+		}
+
+		projects, err := projectspg.New(conn)
+		if err != nil {
+			return err
+		}
+
+		users, err := userspg.New(conn)
+		if err != nil {
+			return err
+		}
+
+		if err := WithTaskStorage(tasks)(s); err != nil {
+			return err
+		}
+
+		if err := WithItemStorage(items)(s); err != nil {
+			return err
+		}
+
+		if err := WithCommentStorage(comments)(s); err != nil {
+			return err
+		}
+
+		if err := WithAttachmentStorage(attachments)(s); err != nil {
+			return err
+		}
+
+		if err := WithLabelStorage(labels)(s); err != nil {
+			return err
+		}
+
+		if err := WithProjectStorage(projects)(s); err != nil {
+			return err
+		}
+
+		return WithUserStorage(users)(s)
+	}
+}
+
+func WithBlobStorage(blobs blobs.Storage) Option {
+	return func(s *Service) error {
+		s.blobs = blobs
+		return nil
+	}
+}
+
+// WithLocalBlobStorage keeps attachment contents in files under a given directory.
+func WithLocalBlobStorage(dir string) Option {
+	return func(s *Service) error {
+		blobs, err := local.New(dir)
+		if err != nil {
+			return err
+		}
+
+		return WithBlobStorage(blobs)(s)
+	}
+}
+
+// WithS3BlobStorage keeps attachment contents in a bucket of an S3 compatible storage.
+func WithS3BlobStorage(cfg s3.Config) Option {
+	return func(s *Service) error {
+		blobs, err := s3.New(cfg)
+		if err != nil {
+			return err
+		}
+
+		return WithBlobStorage(blobs)(s)
 	}
 }
 
@@ -59,7 +198,15 @@ func WithRedisCache(url string, ttl time.Duration) Option {
 }
 
 type Service struct {
-	tasks tasks.Storage
+	tasks    tasks.Storage
+	items    tasks.ItemStorage
+	comments tasks.CommentStorage
+
+	attachments tasks.AttachmentStorage
+	blobs       blobs.Storage
+	labels      labels.Storage
+	projects    projects.Storage
+	users       users.Storage
 
 	cache    cache.Cache
 	cacheTTL time.Duration
@@ -75,74 +222,417 @@ func New(opts ...Option) (*Service, error) {
 	return s, nil
 }
 
-func (s *Service) List(ctx context.Context, userID string) ([]data.Task, error) {
-	cache, found, err := s.cache.Get(ctx, userID)
+func (s *Service) List(ctx context.Context, q data.TasksQuery) (data.TasksPage, error) {
+	// overdue tasks depend on the current time, so they are never cached.
+	if q.Overdue != nil {
+		return s.tasks.FindByUserID(ctx, q)
+	}
+
+	gen, err := s.listGeneration(ctx, q.UserID)
+	if err != nil {
+		return data.TasksPage{}, err
+	}
+
+	key := listCacheKey(gen, q)
+
+	cache, found, err := s.cache.Get(ctx, key)
 	if err != nil {
-		return nil, err
+		return data.TasksPage{}, err
 	}
 	if found {
-		tasks := make([]data.Task, 0)
-		if err := json.Unmarshal(cache, &tasks); err != nil {
-			return nil, err
+		var page data.TasksPage
+		if err := json.Unmarshal(cache, &page); err != nil {
+			return data.TasksPage{}, err
 		}
-		return tasks, nil
+		return page, nil
 	}
 
-	tasks, err := s.tasks.FindByUserID(ctx, userID)
+	page, err := s.tasks.FindByUserID(ctx, q)
 	if err != nil {
-		return nil, err
+		return data.TasksPage{}, err
 	}
 
-	data, _ := json.Marshal(tasks)
-	if err := s.cache.Set(ctx, userID, data, s.cacheTTL); err != nil {
-		return nil, err
+	raw, _ := json.Marshal(page)
+	if err := s.cache.Set(ctx, key, raw, s.cacheTTL); err != nil {
+		return data.TasksPage{}, err
 	}
 
-	return tasks, nil
+	return page, nil
+}
+
+// Search returns tasks visible to q.UserID matching a search query.
+//
+// Search results are not cached, every keystroke of a type-ahead makes a new query.
+func (s *Service) Search(ctx context.Context, q data.TasksSearch) ([]data.TaskHit, error) {
+	return s.tasks.Search(ctx, q)
+}
+
+func (s *Service) Get(ctx context.Context, userID, id string) (data.Task, error) {
+	key := taskCacheKey(userID, id)
+
+	cache, found, err := s.cache.Get(ctx, key)
+	if err != nil {
+		return data.Task{}, err
+	}
+	if found {
+		var task data.Task
+		if err := json.Unmarshal(cache, &task); err != nil {
+			return data.Task{}, err
+		}
+		return task, nil
+	}
+
+	task, err := s.tasks.FindByID(ctx, userID, id)
+	if err != nil {
+		return data.Task{}, err
+	}
+
+	raw, _ := json.Marshal(task)
+	if err := s.cache.Set(ctx, key, raw, s.cacheTTL); err != nil {
+		return data.Task{}, err
+	}
+
+	return task, nil
 }
 
 func (s *Service) Create(ctx context.Context, userID string, t data.Task) (data.Task, error) {
+	if userID == "" {
+		return data.Task{}, tasks.ErrForbidden
+	}
+
 	t.UserID = userID
+	if t.Priority == "" {
+		t.Priority = data.PriorityNormal
+	}
+
+	if err := checkRecurrence(t.Recurrence, t.DueAt); err != nil {
+		return data.Task{}, err
+	}
+	if t.Recurrence != "" {
+		t.RecurrenceStart = t.DueAt
+	}
+
+	if t.ProjectID != nil {
+		if err := s.authorizeProject(ctx, userID, *t.ProjectID); err != nil {
+			return data.Task{}, err
+		}
+	}
 
 	if err := s.tasks.Save(ctx, &t); err != nil {
 		return data.Task{}, err
 	}
 
-	if err := s.cache.Del(ctx, userID); err != nil {
+	audience, err := s.audience(ctx, userID, t.ProjectID)
+	if err != nil {
+		return data.Task{}, err
+	}
+
+	if err := s.invalidateTasks(ctx, audience); err != nil {
 		return data.Task{}, err
 	}
 
 	return t, nil
 }
 
-func (s *Service) Delete(ctx context.Context, id string) error {
-	if err := s.tasks.Delete(ctx, id); err != nil {
+func (s *Service) Delete(ctx context.Context, userID, id string, version int) error {
+	t, err := s.authorize(ctx, userID, id)
+	if err != nil {
 		return err
 	}
 
-	userID, ok := ctx.Value(api.UserIDKey).(string)
-	if ok {
-		if err := s.cache.Del(ctx, userID); err != nil {
-			return err
-		}
+	if err := s.tasks.Delete(ctx, userID, id, version); err != nil {
+		return err
 	}
 
-	return nil
+	return s.invalidateTask(ctx, t)
 }
 
-func (s *Service) Update(ctx context.Context, id string, t data.Task) (data.Task, error) {
+func (s *Service) Update(ctx context.Context, userID, id string, t data.Task) (data.Task, error) {
+	current, err := s.authorize(ctx, userID, id)
+	if err != nil {
+		return data.Task{}, err
+	}
+
+	// a rule omitted from a replacement is kept, it is cleared only by a patch.
+	if t.Recurrence == "" {
+		t.Recurrence = current.Recurrence
+	}
+
+	if err := checkRecurrence(t.Recurrence, t.DueAt); err != nil {
+		return data.Task{}, err
+	}
+
+	// the series keeps its start until its rule or due time is changed.
+	if t.Recurrence != "" {
+		t.RecurrenceStart = t.DueAt
+		if t.Recurrence == current.Recurrence && sameTime(t.DueAt, current.DueAt) && current.RecurrenceStart != nil {
+			t.RecurrenceStart = current.RecurrenceStart
+		}
+	}
+
 	t.ID = id
+	t.UserID = userID
 
 	if err := s.tasks.Update(ctx, &t); err != nil {
 		return data.Task{}, err
 	}
 
-	userID, ok := ctx.Value(api.UserIDKey).(string)
-	if ok {
-		if err := s.cache.Del(ctx, userID); err != nil {
+	if err := s.invalidateTask(ctx, current); err != nil {
+		return data.Task{}, err
+	}
+
+	return t, nil
+}
+
+func (s *Service) Patch(ctx context.Context, userID, id string, p data.TaskPatch) (data.Task, error) {
+	if userID == "" {
+		return data.Task{}, tasks.ErrForbidden
+	}
+
+	if p.IsEmpty() {
+		t, err := s.Get(ctx, userID, id)
+		if err != nil {
+			return data.Task{}, err
+		}
+
+		if p.Version != 0 && p.Version != t.Version {
+			return data.Task{}, tasks.ErrVersionConflict
+		}
+
+		return t, nil
+	}
+
+	authorize := s.authorize
+	if p.IsCompletion() {
+		authorize = s.authorizeCompletion
+	}
+
+	current, err := authorize(ctx, userID, id)
+	if err != nil {
+		return data.Task{}, err
+	}
+
+	if p.ProjectID != nil {
+		if err := s.authorizeProject(ctx, userID, *p.ProjectID); err != nil {
+			return data.Task{}, err
+		}
+	}
+
+	if err := patchRecurrence(current, &p); err != nil {
+		return data.Task{}, err
+	}
+
+	t, err := s.tasks.Patch(ctx, userID, id, p)
+	if err != nil {
+		return data.Task{}, err
+	}
+
+	// a task moved between projects leaves the lists of the former project members.
+	if err := s.invalidateTask(ctx, current); err != nil {
+		return data.Task{}, err
+	}
+
+	if err := s.invalidateTask(ctx, t); err != nil {
+		return data.Task{}, err
+	}
+
+	return t, nil
+}
+
+// patchRecurrence validates the recurrence of a task after a given patch
+// and restarts the recurring series when its rule or due time is changed.
+//
+// An empty rule in the patch clears the recurrence.
+func patchRecurrence(current data.Task, p *data.TaskPatch) error {
+	if p.Recurrence != nil && *p.Recurrence == "" {
+		p.Recurrence, p.ClearRecurrence = nil, true
+	}
+
+	rule, dueAt := current.Recurrence, current.DueAt
+	if p.Recurrence != nil {
+		rule = *p.Recurrence
+	} else if p.ClearRecurrence {
+		rule = ""
+	}
+	if p.DueAt != nil {
+		dueAt = p.DueAt
+	} else if p.ClearDueAt {
+		dueAt = nil
+	}
+
+	if err := checkRecurrence(rule, dueAt); err != nil {
+		return err
+	}
+
+	if rule != "" && (p.Recurrence != nil || p.DueAt != nil) {
+		p.RecurrenceStart = dueAt
+	}
+
+	return nil
+}
+
+// Assign assigns a task to a given user, nil assigneeID unassigns the task.
+//
+// The assignee of a project task must be a member of the project, otherwise
+// returns tasks.ErrInvalidAssignee. If there is no such user returns users.ErrNotFound.
+// If version is not 0 the task is assigned only when its version matches.
+func (s *Service) Assign(ctx context.Context, userID, id string, assigneeID *string, version int) (data.Task, error) {
+	current, err := s.authorize(ctx, userID, id)
+	if err != nil {
+		return data.Task{}, err
+	}
+
+	p := data.TaskPatch{
+		AssigneeID:      assigneeID,
+		ClearAssigneeID: assigneeID == nil,
+		Version:         version,
+	}
+
+	if assigneeID != nil {
+		if _, err := s.users.FindByID(ctx, *assigneeID); err != nil {
 			return data.Task{}, err
 		}
+
+		if current.ProjectID != nil {
+			_, err := s.projects.FindByID(ctx, *assigneeID, *current.ProjectID)
+			if errors.Is(err, projects.ErrNotFound) {
+				return data.Task{}, tasks.ErrInvalidAssignee
+			}
+			if err != nil {
+				return data.Task{}, err
+			}
+		}
+	}
+
+	t, err := s.tasks.Patch(ctx, userID, id, p)
+	if err != nil {
+		return data.Task{}, err
+	}
+
+	// the former assignee loses the task from the assigned view.
+	if err := s.invalidateTask(ctx, current); err != nil {
+		return data.Task{}, err
+	}
+
+	if err := s.invalidateTask(ctx, t); err != nil {
+		return data.Task{}, err
+	}
+
+	return t, nil
+}
+
+// Move places a task between its neighbours in the list of the task's project,
+// or in the list of the task creator for a task out of projects.
+func (s *Service) Move(ctx context.Context, userID, id string, m data.TaskMove) (data.Task, error) {
+	current, err := s.authorize(ctx, userID, id)
+	if err != nil {
+		return data.Task{}, err
+	}
+
+	t, err := s.tasks.Move(ctx, userID, id, m)
+	if err != nil {
+		return data.Task{}, err
+	}
+
+	if err := s.invalidateTask(ctx, current); err != nil {
+		return data.Task{}, err
 	}
 
 	return t, nil
 }
+
+// listGeneration returns the current generation of the cached task lists of a given user.
+//
+// Lists are cached per query under a key containing the generation,
+// so all of them are invalidated at once by invalidateList.
+func (s *Service) listGeneration(ctx context.Context, userID string) (string, error) {
+	key := userID + ":tasks:gen"
+
+	gen, found, err := s.cache.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	if found {
+		return string(gen), nil
+	}
+
+	gen = []byte(strconv.FormatInt(time.Now().UnixNano(), 36))
+	if err := s.cache.Set(ctx, key, gen, s.cacheTTL); err != nil {
+		return "", err
+	}
+
+	return string(gen), nil
+}
+
+// invalidateList drops all cached task lists of a given user.
+func (s *Service) invalidateList(ctx context.Context, userID string) error {
+	return s.cache.Del(ctx, userID+":tasks:gen")
+}
+
+// invalidateTask drops a cached task and cached task lists of all users who see it.
+func (s *Service) invalidateTask(ctx context.Context, t data.Task) error {
+	audience, err := s.audience(ctx, t.UserID, t.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	if t.AssigneeID != nil {
+		audience = append(audience, *t.AssigneeID)
+	}
+
+	return s.invalidateTasks(ctx, audience, t.ID)
+}
+
+// invalidateTasks drops given cached tasks and all cached task lists of given users.
+//
+// The keys of every user are dropped at once.
+func (s *Service) invalidateTasks(ctx context.Context, userIDs []string, ids ...string) error {
+	for _, userID := range userIDs {
+		keys := make([]string, 0, len(ids)+1)
+		keys = append(keys, userID+":tasks:gen")
+		for _, id := range ids {
+			keys = append(keys, taskCacheKey(userID, id))
+		}
+
+		if err := s.cache.Del(ctx, keys...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// listCacheKey returns the cache key of a page of tasks described by a given query.
+func listCacheKey(gen string, q data.TasksQuery) string {
+	completed := "any"
+	if q.Completed != nil {
+		completed = strconv.FormatBool(*q.Completed)
+	}
+
+	dueBefore := "any"
+	if q.DueBefore != nil {
+		dueBefore = strconv.FormatInt(q.DueBefore.Unix(), 10)
+	}
+
+	project := "any"
+	if q.ProjectID != "" {
+		project = q.ProjectID
+	}
+
+	assignee := "any"
+	if q.AssigneeID != "" {
+		assignee = q.AssigneeID
+	}
+
+	labels := "any"
+	if len(q.Labels) > 0 {
+		labels = q.LabelsMatch + "=" + strings.Join(q.Labels, ",")
+	}
+
+	return fmt.Sprintf("%s:tasks:%s:%d:%s:%s:%s:%s:%s:%s:%s:%s", q.UserID, gen, q.Limit, q.Sort, q.Order, completed, dueBefore, project, assignee, labels, q.Cursor)
+}
+
+// taskCacheKey returns the cache key of a single task of a given user.
+func taskCacheKey(userID, id string) string {
+	return userID + ":task:" + id
+}
@@ -0,0 +1,45 @@
+package tasks
+
+import (
+	"context"
+
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
+)
+
+// Trash returns a page of deleted tasks editable by q.UserID.
+//
+// The trash is never cached, it is rarely viewed and changes with every deletion.
+func (s *Service) Trash(ctx context.Context, q data.TasksQuery) (data.TasksPage, error) {
+	q.Deleted = true
+	return s.tasks.FindByUserID(ctx, q)
+}
+
+// Restore moves a deleted task out of the trash.
+func (s *Service) Restore(ctx context.Context, userID, id string) (data.Task, error) {
+	t, err := s.tasks.Restore(ctx, userID, id)
+	if err != nil {
+		return data.Task{}, err
+	}
+
+	if err := s.invalidateTask(ctx, t); err != nil {
+		return data.Task{}, err
+	}
+
+	return t, nil
+}
+
+// Purge permanently deletes a task, either active or in the trash, and the blobs of its attachments.
+func (s *Service) Purge(ctx context.Context, userID, id string, version int) error {
+	t, err := s.tasks.Purge(ctx, userID, id, version)
+	if err != nil {
+		return err
+	}
+
+	if err := s.invalidateTask(ctx, t); err != nil {
+		return err
+	}
+
+	_, err = tasks.PurgeOrphans(ctx, s.attachments, s.blobs)
+	return err
+}
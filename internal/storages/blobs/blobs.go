@@ -0,0 +1,53 @@
+package blobs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+var (
+	ErrNotFound   = errors.New("the blob was not found")
+	ErrInvalidKey = errors.New("the blob key is invalid")
+)
+
+// Storage stores binary objects by keys.
+//
+// Put stores size bytes of r under a key replacing an existing blob, Open returns
+// a blob which may be read from any offset, Delete skips missing blobs.
+// Open returns ErrNotFound for a missing blob and every method returns
+// ErrInvalidKey for a key which does not pass ValidateKey.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name Storage
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, error)
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// ValidateKey checks that a key is a slash separated path of non empty segments
+// of ASCII letters, digits, '-', '_' and '.' not starting with a dot.
+//
+// Such keys are safe both as file paths and as object names.
+func ValidateKey(key string) error {
+	if key == "" || len(key) > 255 {
+		return ErrInvalidKey
+	}
+
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment[0] == '.' {
+			return ErrInvalidKey
+		}
+
+		for _, r := range segment {
+			switch {
+			case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			default:
+				return ErrInvalidKey
+			}
+		}
+	}
+
+	return nil
+}
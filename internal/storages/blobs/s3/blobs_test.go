@@ -0,0 +1,96 @@
+package s3_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/romankravchuk/eldorado/internal/storages/blobs"
+	"github.com/romankravchuk/eldorado/internal/storages/blobs/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newStorage returns a storage of the bucket described by the S3_* variables used by the api,
+// e.g. the minio service of docker-compose.yaml with an existing bucket.
+// The test is skipped when S3_ENDPOINT or S3_BUCKET is not set.
+func newStorage(t *testing.T) *s3.Storage {
+	t.Helper()
+
+	cfg := s3.Config{
+		Endpoint:  os.Getenv("S3_ENDPOINT"),
+		Region:    os.Getenv("S3_REGION"),
+		Bucket:    os.Getenv("S3_BUCKET"),
+		AccessKey: os.Getenv("S3_ACCESS_KEY"),
+		SecretKey: os.Getenv("S3_SECRET_KEY"),
+	}
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		t.Skip("S3_ENDPOINT and S3_BUCKET are not set")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+
+	storage, err := s3.New(cfg)
+	require.NoError(t, err)
+
+	return storage
+}
+
+func TestStorageRoundTrip(t *testing.T) {
+	storage := newStorage(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	const content = "attachments are read with range requests"
+	key := "tests/" + strconv.FormatInt(time.Now().UnixNano(), 10) + "/blob.txt"
+
+	require.NoError(t, storage.Put(ctx, key, strings.NewReader(content), int64(len(content)), "text/plain"))
+	t.Cleanup(func() { _ = storage.Delete(context.Background(), key) })
+
+	obj, err := storage.Open(ctx, key)
+	require.NoError(t, err)
+	defer obj.Close()
+
+	got, err := io.ReadAll(obj)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(got))
+
+	_, err = obj.Seek(int64(strings.Index(content, "range")), io.SeekStart)
+	require.NoError(t, err)
+
+	word := make([]byte, len("range"))
+	_, err = io.ReadFull(obj, word)
+	require.NoError(t, err)
+	assert.Equal(t, "range", string(word))
+
+	_, err = obj.Seek(-int64(len("requests")), io.SeekEnd)
+	require.NoError(t, err)
+
+	tail, err := io.ReadAll(obj)
+	require.NoError(t, err)
+	assert.Equal(t, "requests", string(tail))
+
+	require.NoError(t, storage.Delete(ctx, key))
+
+	_, err = storage.Open(ctx, key)
+	assert.ErrorIs(t, err, blobs.ErrNotFound)
+
+	// a missing blob is skipped.
+	assert.NoError(t, storage.Delete(ctx, key))
+}
+
+func TestStorageOpenMissing(t *testing.T) {
+	storage := newStorage(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := storage.Open(ctx, "tests/missing/"+strconv.FormatInt(time.Now().UnixNano(), 10))
+	assert.ErrorIs(t, err, blobs.ErrNotFound)
+}
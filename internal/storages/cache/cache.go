@@ -9,5 +9,5 @@ import (
 type Cache interface {
 	Set(context.Context, string, []byte, time.Duration) error
 	Get(context.Context, string) ([]byte, bool, error)
-	Del(context.Context, string) error
+	Del(context.Context, ...string) error
 }
@@ -35,8 +35,12 @@ func (s *Cache) Get(ctx context.Context, key string) ([]byte, bool, error) {
 	return res, true, nil
 }
 
-func (s *Cache) Del(ctx context.Context, key string) error {
-	if err := s.client.Del(ctx, key).Err(); err != nil {
+func (s *Cache) Del(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	if err := s.client.Del(ctx, keys...).Err(); err != nil {
 		if errors.Is(err, redis.Nil) {
 			return nil
 		}
@@ -0,0 +1,28 @@
+package labels
+
+import (
+	"context"
+	"errors"
+
+	"github.com/romankravchuk/eldorado/internal/data"
+)
+
+var (
+	ErrNotFound      = errors.New("the label was not found")
+	ErrAlreadyExists = errors.New("the label with this name already exists")
+)
+
+// Storage stores user's labels and their attachments to tasks.
+//
+// Patch and Delete return ids of the tasks the label is attached to, because
+// their representation changes together with the label.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name Storage
+type Storage interface {
+	FindByUserID(ctx context.Context, userID string) ([]data.Label, error)
+	Save(ctx context.Context, l *data.Label) error
+	Patch(ctx context.Context, userID, id string, p data.LabelPatch) (data.Label, []string, error)
+	Delete(ctx context.Context, userID, id string) ([]string, error)
+	Attach(ctx context.Context, userID, taskID, id string) error
+	Detach(ctx context.Context, userID, taskID, id string) error
+}
@@ -0,0 +1,158 @@
+// Code generated by mockery v2.20.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	data "github.com/romankravchuk/eldorado/internal/data"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Storage is an autogenerated mock type for the Storage type
+type Storage struct {
+	mock.Mock
+}
+
+// Attach provides a mock function with given fields: ctx, userID, taskID, id
+func (_m *Storage) Attach(ctx context.Context, userID string, taskID string, id string) error {
+	ret := _m.Called(ctx, userID, taskID, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, userID, taskID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Delete provides a mock function with given fields: ctx, userID, id
+func (_m *Storage) Delete(ctx context.Context, userID string, id string) ([]string, error) {
+	ret := _m.Called(ctx, userID, id)
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]string, error)); ok {
+		return rf(ctx, userID, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []string); ok {
+		r0 = rf(ctx, userID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, userID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Detach provides a mock function with given fields: ctx, userID, taskID, id
+func (_m *Storage) Detach(ctx context.Context, userID string, taskID string, id string) error {
+	ret := _m.Called(ctx, userID, taskID, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, userID, taskID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FindByUserID provides a mock function with given fields: ctx, userID
+func (_m *Storage) FindByUserID(ctx context.Context, userID string) ([]data.Label, error) {
+	ret := _m.Called(ctx, userID)
+
+	var r0 []data.Label
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]data.Label, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []data.Label); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]data.Label)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Patch provides a mock function with given fields: ctx, userID, id, p
+func (_m *Storage) Patch(ctx context.Context, userID string, id string, p data.LabelPatch) (data.Label, []string, error) {
+	ret := _m.Called(ctx, userID, id, p)
+
+	var r0 data.Label
+	var r1 []string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, data.LabelPatch) (data.Label, []string, error)); ok {
+		return rf(ctx, userID, id, p)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, data.LabelPatch) data.Label); ok {
+		r0 = rf(ctx, userID, id, p)
+	} else {
+		r0 = ret.Get(0).(data.Label)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, data.LabelPatch) []string); ok {
+		r1 = rf(ctx, userID, id, p)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, data.LabelPatch) error); ok {
+		r2 = rf(ctx, userID, id, p)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Save provides a mock function with given fields: ctx, l
+func (_m *Storage) Save(ctx context.Context, l *data.Label) error {
+	ret := _m.Called(ctx, l)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *data.Label) error); ok {
+		r0 = rf(ctx, l)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewStorage interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewStorage creates a new instance of Storage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewStorage(t mockConstructorTestingTNewStorage) *Storage {
+	mock := &Storage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
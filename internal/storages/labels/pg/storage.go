@@ -0,0 +1,390 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/storages"
+	"github.com/romankravchuk/eldorado/internal/storages/labels"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
+)
+
+// LabelsStorage is a postgres implementation of labels.Storage.
+//
+// Every change of a label bumps versions of the tasks it is attached to,
+// because the task representation includes its labels.
+type LabelsStorage struct {
+	db *sql.DB
+}
+
+// New returns new LabelsStorage instance with postgres db pool.
+//
+// If db is nil returns storages.ErrNilDBPool.
+func New(db *sql.DB) (*LabelsStorage, error) {
+	if db == nil {
+		return nil, storages.ErrNilDBPool
+	}
+
+	return &LabelsStorage{db: db}, nil
+}
+
+// FindByUserID returns all labels of a given user ordered by name.
+func (s *LabelsStorage) FindByUserID(ctx context.Context, userID string) ([]data.Label, error) {
+	const query = "SELECT " + labelColumns + " FROM labels WHERE user_id = $1 ORDER BY name, id"
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := s.db.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var ls []data.Label
+	for rows.Next() {
+		var l data.Label
+		if err = scanLabel(rows, &l); err != nil {
+			break
+		}
+		ls = append(ls, l)
+	}
+
+	if closeErr := rows.Close(); closeErr != nil {
+		return nil, closeErr
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ls, nil
+}
+
+// Save saves a label of l.UserID to the database.
+//
+// An empty l.Color is replaced with data.LabelDefaultColor.
+// If save succeeds ID, Color and CreatedOn fields are filled.
+// If the user already has a label with the same name returns labels.ErrAlreadyExists.
+func (s *LabelsStorage) Save(ctx context.Context, l *data.Label) error {
+	const query = "INSERT INTO labels (user_id, name, color) VALUES ($1, $2, COALESCE($3, $4)) RETURNING " + labelColumns
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := s.db.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	var color any
+	if l.Color != "" {
+		color = l.Color
+	}
+
+	if err = scanLabel(stmt.QueryRowContext(ctx, l.UserID, l.Name, color, data.LabelDefaultColor), l); err != nil {
+		if isUniqueViolation(err) {
+			return labels.ErrAlreadyExists
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// Patch updates only the fields of a label set in a given patch.
+//
+// If patch succeeds returns the updated label and ids of the tasks it is attached to.
+// If the label does not exist or belongs to another user returns labels.ErrNotFound.
+// If the user already has a label with the new name returns labels.ErrAlreadyExists.
+func (s *LabelsStorage) Patch(ctx context.Context, userID, id string, p data.LabelPatch) (data.Label, []string, error) {
+	var (
+		sets []string
+		args []any
+	)
+
+	if p.Name != nil {
+		args = append(args, *p.Name)
+		sets = append(sets, fmt.Sprintf("name = $%d", len(args)))
+	}
+	if p.Color != nil {
+		args = append(args, *p.Color)
+		sets = append(sets, fmt.Sprintf("color = $%d", len(args)))
+	}
+	if len(sets) == 0 {
+		sets = append(sets, "name = name")
+	}
+
+	args = append(args, id, userID)
+	query := fmt.Sprintf(
+		"UPDATE labels SET %s WHERE id = $%d AND user_id = $%d RETURNING %s",
+		strings.Join(sets, ", "), len(args)-1, len(args), labelColumns,
+	)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return data.Label{}, nil, err
+	}
+	defer tx.Rollback()
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := tx.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return data.Label{}, nil, err
+	}
+	defer stmt.Close()
+
+	var l data.Label
+	if err = scanLabel(stmt.QueryRowContext(ctx, args...), &l); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return data.Label{}, nil, labels.ErrNotFound
+		}
+
+		if isUniqueViolation(err) {
+			return data.Label{}, nil, labels.ErrAlreadyExists
+		}
+
+		return data.Label{}, nil, err
+	}
+
+	taskIDs, err := touchTasks(ctx, tx, id)
+	if err != nil {
+		return data.Label{}, nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return data.Label{}, nil, err
+	}
+
+	return l, taskIDs, nil
+}
+
+// Delete deletes a label owned by a given user and detaches it from all tasks.
+//
+// If delete succeeds returns ids of the tasks the label was attached to.
+// If the label does not exist or belongs to another user returns labels.ErrNotFound.
+func (s *LabelsStorage) Delete(ctx context.Context, userID, id string) ([]string, error) {
+	const query = "DELETE FROM labels WHERE id = $1 AND user_id = $2"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// tasks are touched before the delete cascades to their attachments.
+	taskIDs, err := touchTasks(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := tx.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	if count != 1 {
+		return nil, labels.ErrNotFound
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return taskIDs, nil
+}
+
+// Attach attaches a label owned by a given user to a task editable by the user.
+//
+// Attaching an already attached label changes nothing.
+// If the task does not exist or is not editable by the user returns tasks.ErrNotFound.
+// If the label does not exist or belongs to another user returns labels.ErrNotFound.
+func (s *LabelsStorage) Attach(ctx context.Context, userID, taskID, id string) error {
+	const query = "INSERT INTO task_labels (task_id, label_id) SELECT $1, id FROM labels WHERE id = $2 AND user_id = $3 ON CONFLICT DO NOTHING"
+
+	return s.changeAttachment(ctx, query, userID, taskID, id)
+}
+
+// Detach detaches a label owned by a given user from a task editable by the user.
+//
+// Detaching a label which is not attached changes nothing.
+// If the task does not exist or is not editable by the user returns tasks.ErrNotFound.
+// If the label does not exist or belongs to another user returns labels.ErrNotFound.
+func (s *LabelsStorage) Detach(ctx context.Context, userID, taskID, id string) error {
+	const query = "DELETE FROM task_labels tl USING labels l WHERE tl.task_id = $1 AND tl.label_id = $2 AND l.id = tl.label_id AND l.user_id = $3"
+
+	return s.changeAttachment(ctx, query, userID, taskID, id)
+}
+
+// changeAttachment executes a given attach or detach query with task id, label id
+// and user id arguments and bumps the task version if the query changed anything.
+func (s *LabelsStorage) changeAttachment(ctx context.Context, query, userID, taskID, id string) error {
+	const (
+		lockQuery  = "SELECT 1 FROM tasks WHERE id = $1 AND ((project_id IS NULL AND user_id = $2) OR assignee_id = $2 OR project_id IN (SELECT project_id FROM project_members WHERE user_id = $2 AND role <> 'viewer')) AND is_deleted = false FOR UPDATE"
+		labelQuery = "SELECT 1 FROM labels WHERE id = $1 AND user_id = $2"
+		touchQuery = "UPDATE tasks SET updated_on = CURRENT_TIMESTAMP, version = version + 1 WHERE id = $1"
+	)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = selectOne(ctx, tx, lockQuery, taskID, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return tasks.ErrNotFound
+		}
+
+		return err
+	}
+
+	if err = selectOne(ctx, tx, labelQuery, id, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return labels.ErrNotFound
+		}
+
+		return err
+	}
+
+	count, err := exec(ctx, tx, query, taskID, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return nil
+	}
+
+	if _, err = exec(ctx, tx, touchQuery, taskID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// touchTasks bumps versions of the tasks a given label is attached to and returns their ids.
+func touchTasks(ctx context.Context, tx *sql.Tx, id string) ([]string, error) {
+	const query = "UPDATE tasks SET updated_on = CURRENT_TIMESTAMP, version = version + 1 WHERE id IN (SELECT task_id FROM task_labels WHERE label_id = $1) RETURNING id"
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := tx.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for rows.Next() {
+		var taskID string
+		if err = rows.Scan(&taskID); err != nil {
+			break
+		}
+		ids = append(ids, taskID)
+	}
+
+	if closeErr := rows.Close(); closeErr != nil {
+		return nil, closeErr
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// selectOne executes a query selecting a single row in a transaction.
+//
+// If there is no such row returns sql.ErrNoRows.
+func selectOne(ctx context.Context, tx *sql.Tx, query string, args ...any) error {
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := tx.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	var one int
+	return stmt.QueryRowContext(ctx, args...).Scan(&one)
+}
+
+// exec executes a query in a transaction and returns count of affected rows.
+func exec(ctx context.Context, tx *sql.Tx, query string, args ...any) (int64, error) {
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := tx.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+const labelColumns = "id, user_id, name, color, created_on"
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanLabel scans a row selected with labelColumns into a given label.
+func scanLabel(row scanner, l *data.Label) error {
+	return row.Scan(&l.ID, &l.UserID, &l.Name, &l.Color, &l.CreatedOn)
+}
+
+func isUniqueViolation(err error) bool {
+	psqlErr, ok := err.(*pq.Error)
+	return ok && psqlErr.Code == storages.UniqueViolationCode
+}
@@ -6,7 +6,10 @@ import (
 	_ "github.com/lib/pq"
 )
 
-const UniqueViolationCode = "23505"
+const (
+	UniqueViolationCode = "23505"
+	CheckViolationCode  = "23514"
+)
 
 // NewDBPool returns a connection pool for databsae.
 func NewDBPool(driver, url string) (*sql.DB, error) {
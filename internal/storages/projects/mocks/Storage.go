@@ -0,0 +1,234 @@
+// Code generated by mockery v2.20.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	data "github.com/romankravchuk/eldorado/internal/data"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Storage is an autogenerated mock type for the Storage type
+type Storage struct {
+	mock.Mock
+}
+
+// AddMember provides a mock function with given fields: ctx, m
+func (_m *Storage) AddMember(ctx context.Context, m *data.ProjectMember) error {
+	ret := _m.Called(ctx, m)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *data.ProjectMember) error); ok {
+		r0 = rf(ctx, m)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Delete provides a mock function with given fields: ctx, userID, id
+func (_m *Storage) Delete(ctx context.Context, userID string, id string) ([]string, error) {
+	ret := _m.Called(ctx, userID, id)
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]string, error)); ok {
+		return rf(ctx, userID, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []string); ok {
+		r0 = rf(ctx, userID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, userID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindByID provides a mock function with given fields: ctx, userID, id
+func (_m *Storage) FindByID(ctx context.Context, userID string, id string) (data.Project, error) {
+	ret := _m.Called(ctx, userID, id)
+
+	var r0 data.Project
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (data.Project, error)); ok {
+		return rf(ctx, userID, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) data.Project); ok {
+		r0 = rf(ctx, userID, id)
+	} else {
+		r0 = ret.Get(0).(data.Project)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, userID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindByUserID provides a mock function with given fields: ctx, userID
+func (_m *Storage) FindByUserID(ctx context.Context, userID string) ([]data.Project, error) {
+	ret := _m.Called(ctx, userID)
+
+	var r0 []data.Project
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]data.Project, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []data.Project); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]data.Project)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Members provides a mock function with given fields: ctx, id
+func (_m *Storage) Members(ctx context.Context, id string) ([]data.ProjectMember, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 []data.ProjectMember
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]data.ProjectMember, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []data.ProjectMember); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]data.ProjectMember)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Patch provides a mock function with given fields: ctx, userID, id, p
+func (_m *Storage) Patch(ctx context.Context, userID string, id string, p data.ProjectPatch) (data.Project, error) {
+	ret := _m.Called(ctx, userID, id, p)
+
+	var r0 data.Project
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, data.ProjectPatch) (data.Project, error)); ok {
+		return rf(ctx, userID, id, p)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, data.ProjectPatch) data.Project); ok {
+		r0 = rf(ctx, userID, id, p)
+	} else {
+		r0 = ret.Get(0).(data.Project)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, data.ProjectPatch) error); ok {
+		r1 = rf(ctx, userID, id, p)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveMember provides a mock function with given fields: ctx, id, userID
+func (_m *Storage) RemoveMember(ctx context.Context, id string, userID string) ([]string, error) {
+	ret := _m.Called(ctx, id, userID)
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]string, error)); ok {
+		return rf(ctx, id, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []string); ok {
+		r0 = rf(ctx, id, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, id, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Save provides a mock function with given fields: ctx, p
+func (_m *Storage) Save(ctx context.Context, p *data.Project) error {
+	ret := _m.Called(ctx, p)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *data.Project) error); ok {
+		r0 = rf(ctx, p)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateMember provides a mock function with given fields: ctx, id, userID, role
+func (_m *Storage) UpdateMember(ctx context.Context, id string, userID string, role string) (data.ProjectMember, error) {
+	ret := _m.Called(ctx, id, userID, role)
+
+	var r0 data.ProjectMember
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (data.ProjectMember, error)); ok {
+		return rf(ctx, id, userID, role)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) data.ProjectMember); ok {
+		r0 = rf(ctx, id, userID, role)
+	} else {
+		r0 = ret.Get(0).(data.ProjectMember)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, id, userID, role)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewStorage interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewStorage creates a new instance of Storage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewStorage(t mockConstructorTestingTNewStorage) *Storage {
+	mock := &Storage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,467 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/storages"
+	"github.com/romankravchuk/eldorado/internal/storages/projects"
+)
+
+// ProjectsStorage is a postgres implementation of projects.Storage.
+type ProjectsStorage struct {
+	db *sql.DB
+}
+
+// New returns new ProjectsStorage instance with postgres db pool.
+//
+// If db is nil returns storages.ErrNilDBPool.
+func New(db *sql.DB) (*ProjectsStorage, error) {
+	if db == nil {
+		return nil, storages.ErrNilDBPool
+	}
+
+	return &ProjectsStorage{db: db}, nil
+}
+
+// FindByUserID returns all projects a given user is a member of ordered by name.
+func (s *ProjectsStorage) FindByUserID(ctx context.Context, userID string) ([]data.Project, error) {
+	const query = "SELECT " + projectColumns + " FROM projects p JOIN project_members m ON m.project_id = p.id WHERE m.user_id = $1 ORDER BY p.name, p.id"
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := s.db.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var ps []data.Project
+	for rows.Next() {
+		var p data.Project
+		if err = scanProject(rows, &p); err != nil {
+			break
+		}
+		ps = append(ps, p)
+	}
+
+	if closeErr := rows.Close(); closeErr != nil {
+		return nil, closeErr
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ps, nil
+}
+
+// FindByID returns a project by given id with the role of a given user in it.
+//
+// If the project does not exist or the user is not its member returns projects.ErrNotFound.
+func (s *ProjectsStorage) FindByID(ctx context.Context, userID, id string) (data.Project, error) {
+	const query = "SELECT " + projectColumns + " FROM projects p JOIN project_members m ON m.project_id = p.id WHERE p.id = $1 AND m.user_id = $2"
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := s.db.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return data.Project{}, err
+	}
+	defer stmt.Close()
+
+	var p data.Project
+	if err = scanProject(stmt.QueryRowContext(ctx, id, userID), &p); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return data.Project{}, projects.ErrNotFound
+		}
+
+		return data.Project{}, err
+	}
+
+	return p, nil
+}
+
+// Save saves a project of p.UserID to the database and makes the user its owner.
+//
+// An empty p.Color is replaced with data.ProjectDefaultColor.
+// If save succeeds ID, Color, IsArchived, CreatedOn, UpdatedOn and Role fields are filled.
+func (s *ProjectsStorage) Save(ctx context.Context, p *data.Project) error {
+	const (
+		projectQuery = "INSERT INTO projects (user_id, name, color) VALUES ($1, $2, COALESCE($3, $4)) RETURNING id, color, is_archived, created_on, updated_on"
+		memberQuery  = "INSERT INTO project_members (project_id, user_id, role) VALUES ($1, $2, $3)"
+	)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	projectStmt, err := tx.PrepareContext(prepareCtx, projectQuery)
+	if err != nil {
+		return err
+	}
+	defer projectStmt.Close()
+
+	memberStmt, err := tx.PrepareContext(prepareCtx, memberQuery)
+	if err != nil {
+		return err
+	}
+	defer memberStmt.Close()
+
+	var color any
+	if p.Color != "" {
+		color = p.Color
+	}
+
+	err = projectStmt.QueryRowContext(ctx, p.UserID, p.Name, color, data.ProjectDefaultColor).
+		Scan(&p.ID, &p.Color, &p.IsArchived, &p.CreatedOn, &p.UpdatedOn)
+	if err != nil {
+		return err
+	}
+
+	if _, err = memberStmt.ExecContext(ctx, p.ID, p.UserID, data.RoleOwner); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	p.Role = data.RoleOwner
+
+	return nil
+}
+
+// Patch updates only the fields of a project set in a given patch.
+//
+// If the project does not exist or a given user is not its owner returns projects.ErrNotFound.
+func (s *ProjectsStorage) Patch(ctx context.Context, userID, id string, p data.ProjectPatch) (data.Project, error) {
+	var (
+		sets []string
+		args []any
+	)
+
+	if p.Name != nil {
+		args = append(args, *p.Name)
+		sets = append(sets, fmt.Sprintf("name = $%d", len(args)))
+	}
+	if p.Color != nil {
+		args = append(args, *p.Color)
+		sets = append(sets, fmt.Sprintf("color = $%d", len(args)))
+	}
+	if p.IsArchived != nil {
+		args = append(args, *p.IsArchived)
+		sets = append(sets, fmt.Sprintf("is_archived = $%d", len(args)))
+	}
+	sets = append(sets, "updated_on = CURRENT_TIMESTAMP")
+
+	args = append(args, id, userID)
+	query := fmt.Sprintf(
+		"UPDATE projects p SET %s FROM project_members m WHERE p.id = $%d AND m.project_id = p.id AND m.user_id = $%d AND m.role = 'owner' RETURNING %s",
+		strings.Join(sets, ", "), len(args)-1, len(args), projectColumns,
+	)
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := s.db.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return data.Project{}, err
+	}
+	defer stmt.Close()
+
+	var project data.Project
+	if err = scanProject(stmt.QueryRowContext(ctx, args...), &project); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return data.Project{}, projects.ErrNotFound
+		}
+
+		return data.Project{}, err
+	}
+
+	return project, nil
+}
+
+// Delete deletes a project owned by a given user.
+//
+// Tasks of the project are not deleted but moved out of it.
+// If delete succeeds returns ids of the moved tasks.
+// If the project does not exist or a given user is not its owner returns projects.ErrNotFound.
+func (s *ProjectsStorage) Delete(ctx context.Context, userID, id string) ([]string, error) {
+	const (
+		releaseQuery = "UPDATE tasks SET project_id = NULL, updated_on = CURRENT_TIMESTAMP, version = version + 1 WHERE project_id = $1 AND EXISTS (SELECT 1 FROM project_members m WHERE m.project_id = $1 AND m.user_id = $2 AND m.role = 'owner') RETURNING id"
+		deleteQuery  = "DELETE FROM projects p USING project_members m WHERE p.id = $1 AND m.project_id = p.id AND m.user_id = $2 AND m.role = 'owner'"
+	)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	deleteStmt, err := tx.PrepareContext(prepareCtx, deleteQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer deleteStmt.Close()
+
+	releaseStmt, err := tx.PrepareContext(prepareCtx, releaseQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseStmt.Close()
+
+	taskIDs, err := queryIDs(ctx, releaseStmt, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := deleteStmt.ExecContext(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	if count != 1 {
+		return nil, projects.ErrNotFound
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return taskIDs, nil
+}
+
+// Members returns members of a given project, the owner goes first.
+func (s *ProjectsStorage) Members(ctx context.Context, id string) ([]data.ProjectMember, error) {
+	const query = "SELECT " + memberColumns + " FROM project_members m JOIN users u ON u.id = m.user_id WHERE m.project_id = $1 ORDER BY m.role, u.email"
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := s.db.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var ms []data.ProjectMember
+	for rows.Next() {
+		var m data.ProjectMember
+		if err = scanMember(rows, &m); err != nil {
+			break
+		}
+		ms = append(ms, m)
+	}
+
+	if closeErr := rows.Close(); closeErr != nil {
+		return nil, closeErr
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ms, nil
+}
+
+// AddMember adds m.UserID to the members of m.ProjectID with m.Role.
+//
+// If add succeeds CreatedOn field is filled.
+// If the user is already a member returns projects.ErrMemberExists.
+func (s *ProjectsStorage) AddMember(ctx context.Context, m *data.ProjectMember) error {
+	const query = "INSERT INTO project_members (project_id, user_id, role) VALUES ($1, $2, $3) RETURNING created_on"
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := s.db.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	if err = stmt.QueryRowContext(ctx, m.ProjectID, m.UserID, m.Role).Scan(&m.CreatedOn); err != nil {
+		if psqlErr, ok := err.(*pq.Error); ok && psqlErr.Code == storages.UniqueViolationCode {
+			return projects.ErrMemberExists
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// UpdateMember changes a role of a project member.
+//
+// The role of the project owner could not be changed.
+// If there is no such member except the owner returns projects.ErrMemberNotFound.
+func (s *ProjectsStorage) UpdateMember(ctx context.Context, id, userID, role string) (data.ProjectMember, error) {
+	const query = "UPDATE project_members m SET role = $3 FROM users u WHERE m.project_id = $1 AND m.user_id = $2 AND m.role <> 'owner' AND u.id = m.user_id RETURNING " + memberColumns
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := s.db.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return data.ProjectMember{}, err
+	}
+	defer stmt.Close()
+
+	var m data.ProjectMember
+	if err = scanMember(stmt.QueryRowContext(ctx, id, userID, role), &m); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return data.ProjectMember{}, projects.ErrMemberNotFound
+		}
+
+		return data.ProjectMember{}, err
+	}
+
+	return m, nil
+}
+
+// RemoveMember removes a user from the members of a project.
+//
+// The project owner could not be removed.
+// If remove succeeds returns ids of the project tasks.
+// If there is no such member except the owner returns projects.ErrMemberNotFound.
+func (s *ProjectsStorage) RemoveMember(ctx context.Context, id, userID string) ([]string, error) {
+	const (
+		removeQuery = "DELETE FROM project_members WHERE project_id = $1 AND user_id = $2 AND role <> 'owner'"
+		tasksQuery  = "SELECT id FROM tasks WHERE project_id = $1 AND is_deleted = false"
+	)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	removeStmt, err := tx.PrepareContext(prepareCtx, removeQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer removeStmt.Close()
+
+	tasksStmt, err := tx.PrepareContext(prepareCtx, tasksQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer tasksStmt.Close()
+
+	res, err := removeStmt.ExecContext(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	if count != 1 {
+		return nil, projects.ErrMemberNotFound
+	}
+
+	taskIDs, err := queryIDs(ctx, tasksStmt, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return taskIDs, nil
+}
+
+// queryIDs executes a given statement selecting a single id column.
+func queryIDs(ctx context.Context, stmt *sql.Stmt, args ...any) ([]string, error) {
+	rows, err := stmt.QueryContext(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			break
+		}
+		ids = append(ids, id)
+	}
+
+	if closeErr := rows.Close(); closeErr != nil {
+		return nil, closeErr
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+const (
+	projectColumns = "p.id, p.user_id, p.name, p.color, p.is_archived, p.created_on, p.updated_on, m.role"
+	memberColumns  = "m.project_id, m.user_id, u.email, m.role, m.created_on"
+)
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanProject scans a row selected with projectColumns into a given project.
+func scanProject(row scanner, p *data.Project) error {
+	return row.Scan(&p.ID, &p.UserID, &p.Name, &p.Color, &p.IsArchived, &p.CreatedOn, &p.UpdatedOn, &p.Role)
+}
+
+// scanMember scans a row selected with memberColumns into a given member.
+func scanMember(row scanner, m *data.ProjectMember) error {
+	return row.Scan(&m.ProjectID, &m.UserID, &m.Email, &m.Role, &m.CreatedOn)
+}
@@ -0,0 +1,33 @@
+package projects
+
+import (
+	"context"
+	"errors"
+
+	"github.com/romankravchuk/eldorado/internal/data"
+)
+
+var (
+	ErrNotFound       = errors.New("the project was not found")
+	ErrMemberNotFound = errors.New("the project member was not found")
+	ErrMemberExists   = errors.New("the user is already a member of the project")
+)
+
+// Storage stores projects and their members.
+//
+// A project is visible only to its members, Patch and Delete are allowed only to its owner.
+// Delete and RemoveMember return ids of the project tasks, because they are
+// moved out of the project or become invisible to the removed member.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name Storage
+type Storage interface {
+	FindByUserID(ctx context.Context, userID string) ([]data.Project, error)
+	FindByID(ctx context.Context, userID, id string) (data.Project, error)
+	Save(ctx context.Context, p *data.Project) error
+	Patch(ctx context.Context, userID, id string, p data.ProjectPatch) (data.Project, error)
+	Delete(ctx context.Context, userID, id string) ([]string, error)
+	Members(ctx context.Context, id string) ([]data.ProjectMember, error)
+	AddMember(ctx context.Context, m *data.ProjectMember) error
+	UpdateMember(ctx context.Context, id, userID, role string) (data.ProjectMember, error)
+	RemoveMember(ctx context.Context, id, userID string) ([]string, error)
+}
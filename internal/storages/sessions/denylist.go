@@ -0,0 +1,37 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+)
+
+// revokedPrefix prefixes the keys marking revoked token ids.
+const revokedPrefix = "revoked:"
+
+// RevokedKey returns the key marking a token id as revoked.
+//
+// Dropping the stored token is not enough to revoke it, a concurrent refresh could store it again,
+// and the tokens verified without the auth service are checked against the marks only.
+func RevokedKey(id string) string {
+	return revokedPrefix + id
+}
+
+// Denylist tells whether token ids are revoked.
+type Denylist struct {
+	storage Storage
+}
+
+func NewDenylist(storage Storage) *Denylist {
+	return &Denylist{storage: storage}
+}
+
+func (d *Denylist) IsRevoked(ctx context.Context, id string) (bool, error) {
+	_, err := d.storage.Get(ctx, RevokedKey(id))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
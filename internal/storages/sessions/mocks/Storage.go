@@ -15,6 +15,41 @@ type Storage struct {
 	mock.Mock
 }
 
+// AddToIndex provides a mock function with given fields: ctx, id, key, ttl
+func (_m *Storage) AddToIndex(ctx context.Context, id string, key string, ttl time.Duration) error {
+	ret := _m.Called(ctx, id, key, ttl)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Duration) error); ok {
+		r0 = rf(ctx, id, key, ttl)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Delete provides a mock function with given fields: ctx, keys
+func (_m *Storage) Delete(ctx context.Context, keys ...string) error {
+	_va := make([]interface{}, len(keys))
+	for _i := range keys {
+		_va[_i] = keys[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, ...string) error); ok {
+		r0 = rf(ctx, keys...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Get provides a mock function with given fields: ctx, key
 func (_m *Storage) Get(ctx context.Context, key string) ([]byte, error) {
 	ret := _m.Called(ctx, key)
@@ -41,6 +76,53 @@ func (_m *Storage) Get(ctx context.Context, key string) ([]byte, error) {
 	return r0, r1
 }
 
+// Index provides a mock function with given fields: ctx, id
+func (_m *Storage) Index(ctx context.Context, id string) ([]string, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]string, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []string); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveFromIndex provides a mock function with given fields: ctx, id, keys
+func (_m *Storage) RemoveFromIndex(ctx context.Context, id string, keys ...string) error {
+	_va := make([]interface{}, len(keys))
+	for _i := range keys {
+		_va[_i] = keys[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, id)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...string) error); ok {
+		r0 = rf(ctx, id, keys...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Set provides a mock function with given fields: ctx, key, value, ttl
 func (_m *Storage) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
 	ret := _m.Called(ctx, key, value, ttl)
@@ -55,6 +137,20 @@ func (_m *Storage) Set(ctx context.Context, key string, value []byte, ttl time.D
 	return r0
 }
 
+// Swap provides a mock function with given fields: ctx, key, newKey, value, ttl
+func (_m *Storage) Swap(ctx context.Context, key string, newKey string, value []byte, ttl time.Duration) error {
+	ret := _m.Called(ctx, key, newKey, value, ttl)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, []byte, time.Duration) error); ok {
+		r0 = rf(ctx, key, newKey, value, ttl)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 type mockConstructorTestingTNewStorage interface {
 	mock.TestingT
 	Cleanup(func())
@@ -3,12 +3,17 @@ package redis
 import (
 	"context"
 	"errors"
+	"strconv"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/romankravchuk/eldorado/internal/storages/sessions"
 )
 
+// indexPrefix prefixes the keys of sorted sets indexing sessions.
+// Members of the sets are the keys of sessions scored by their expiration time.
+const indexPrefix = "sessions:"
+
 type Storage struct {
 	client *redis.Client
 }
@@ -34,3 +39,86 @@ func (s *Storage) Get(ctx context.Context, key string) ([]byte, error) {
 	}
 	return res, nil
 }
+
+// swapScript deletes KEYS[1] and, only if it existed, sets KEYS[2] to ARGV[1] living for ARGV[2] milliseconds.
+var swapScript = redis.NewScript(`
+if not redis.call('GETDEL', KEYS[1]) then
+	return 0
+end
+redis.call('SET', KEYS[2], ARGV[1], 'PX', ARGV[2])
+return 1
+`)
+
+func (s *Storage) Swap(ctx context.Context, key, newKey string, value []byte, ttl time.Duration) error {
+	swapped, err := swapScript.Run(ctx, s.client, []string{key, newKey}, value, ttl.Milliseconds()).Int()
+	if err != nil {
+		return err
+	}
+	if swapped == 0 {
+		return sessions.ErrNotFound
+	}
+	return nil
+}
+
+func (s *Storage) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	if err := s.client.Del(ctx, keys...).Err(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *Storage) Index(ctx context.Context, id string) ([]string, error) {
+	res, err := s.client.ZRangeByScore(ctx, indexPrefix+id, &redis.ZRangeBy{
+		Min: strconv.FormatInt(time.Now().Unix(), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// AddToIndex never shortens the life of a key already in the index.
+// It also drops the expired keys and keeps the index alive as long as its longest living session.
+func (s *Storage) AddToIndex(ctx context.Context, id, key string, ttl time.Duration) error {
+	index := indexPrefix + id
+	now := time.Now()
+
+	var last *redis.ZSliceCmd
+	_, err := s.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.ZAddGT(ctx, index, redis.Z{Score: float64(now.Add(ttl).Unix()), Member: key})
+		p.ZRemRangeByScore(ctx, index, "-inf", "("+strconv.FormatInt(now.Unix(), 10))
+		last = p.ZRevRangeWithScores(ctx, index, 0, 0)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if zs := last.Val(); len(zs) > 0 {
+		if err := s.client.ExpireAt(ctx, index, time.Unix(int64(zs[0].Score), 0)).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Storage) RemoveFromIndex(ctx context.Context, id string, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	members := make([]any, len(keys))
+	for i, k := range keys {
+		members[i] = k
+	}
+
+	if err := s.client.ZRem(ctx, indexPrefix+id, members...).Err(); err != nil {
+		return err
+	}
+	return nil
+}
@@ -12,4 +12,17 @@ var ErrNotFound = errors.New("the value in sessions storage not found")
 type Storage interface {
 	Get(ctx context.Context, key string) ([]byte, error)
 	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Swap deletes a key and sets another key to a value living for ttl at once,
+	// so only one of concurrent callers swaps the key.
+	// If the key does not exist returns ErrNotFound and sets nothing.
+	Swap(ctx context.Context, key, newKey string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+
+	// Indexes group the keys of sessions by an id, of a user or of a token family.
+
+	// Index returns the keys of the live sessions in the index.
+	Index(ctx context.Context, id string) ([]string, error)
+	// AddToIndex adds the key of a session living for ttl to the index.
+	AddToIndex(ctx context.Context, id, key string, ttl time.Duration) error
+	RemoveFromIndex(ctx context.Context, id string, keys ...string) error
 }
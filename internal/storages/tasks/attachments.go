@@ -0,0 +1,64 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/storages/blobs"
+)
+
+var ErrAttachmentNotFound = errors.New("the task attachment not found")
+
+// orphansBatch is the count of orphaned attachments deleted at once by PurgeOrphans.
+const orphansBatch = 100
+
+// AttachmentStorage stores records of files attached to tasks.
+//
+// Every method except Orphans and DeleteOrphans is scoped to an active task visible
+// to a given user and returns ErrNotFound if there is no such task.
+// An attachment detached from its task, either explicitly or because the task was purged,
+// becomes an orphan until its blob is deleted.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name AttachmentStorage
+type AttachmentStorage interface {
+	FindByTaskID(ctx context.Context, userID, taskID string) ([]data.TaskAttachment, error)
+	FindByID(ctx context.Context, userID, taskID, id string) (data.TaskAttachment, error)
+	Save(ctx context.Context, userID string, a *data.TaskAttachment) error
+	Detach(ctx context.Context, userID, taskID, id string) error
+	Orphans(ctx context.Context, limit int) ([]data.TaskAttachment, error)
+	DeleteOrphans(ctx context.Context, ids ...string) error
+}
+
+// PurgeOrphans deletes the blobs of orphaned attachments and then their records.
+//
+// A failed run leaves the records, so the blobs are deleted by the next one.
+// Returns the count of purged attachments.
+func PurgeOrphans(ctx context.Context, attachments AttachmentStorage, blobs blobs.Storage) (int, error) {
+	var purged int
+	for {
+		orphans, err := attachments.Orphans(ctx, orphansBatch)
+		if err != nil {
+			return purged, err
+		}
+
+		keys := make([]string, len(orphans))
+		ids := make([]string, len(orphans))
+		for i, a := range orphans {
+			keys[i], ids[i] = a.Key, a.ID
+		}
+
+		if err := blobs.Delete(ctx, keys...); err != nil {
+			return purged, err
+		}
+
+		if err := attachments.DeleteOrphans(ctx, ids...); err != nil {
+			return purged, err
+		}
+
+		purged += len(orphans)
+		if len(orphans) < orphansBatch {
+			return purged, nil
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+
+	"github.com/romankravchuk/eldorado/internal/data"
+)
+
+var ErrCommentNotFound = errors.New("the task comment not found")
+
+// CommentStorage stores discussion comments of tasks.
+//
+// Every method is scoped to an active task visible to a given user and returns ErrNotFound
+// if there is no such task. Only the author may edit or delete a comment,
+// a comment of another user yields ErrForbidden.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name CommentStorage
+type CommentStorage interface {
+	FindByTaskID(ctx context.Context, q data.TaskCommentsQuery) (data.TaskCommentsPage, error)
+	Save(ctx context.Context, c *data.TaskComment) error
+	Update(ctx context.Context, userID, taskID, id, content string) (data.TaskComment, error)
+	Delete(ctx context.Context, userID, taskID, id string) error
+}
@@ -0,0 +1,48 @@
+package tasks
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidCursor = errors.New("the cursor is invalid")
+
+// Cursor is a keyset position in a sorted list of tasks.
+//
+// Value is a value of the sort column of the last task in a page,
+// ID is its id which breaks ties between equal values.
+type Cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// EncodeCursor returns an opaque string representation of a cursor.
+func EncodeCursor(c Cursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor parses a cursor encoded by EncodeCursor.
+//
+// If the cursor is malformed returns ErrInvalidCursor.
+func DecodeCursor(s string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	if _, err := uuid.Parse(c.ID); err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	return c, nil
+}
@@ -0,0 +1,24 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+
+	"github.com/romankravchuk/eldorado/internal/data"
+)
+
+var ErrItemNotFound = errors.New("the task item not found")
+
+// ItemStorage stores checklist items of tasks.
+//
+// Every method is scoped to a task visible to a given user and returns ErrNotFound
+// if there is no such task.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name ItemStorage
+type ItemStorage interface {
+	FindByTaskID(ctx context.Context, userID, taskID string) ([]data.TaskItem, error)
+	Save(ctx context.Context, userID string, item *data.TaskItem) error
+	Patch(ctx context.Context, userID, taskID, id string, p data.TaskItemPatch) (data.TaskItem, error)
+	Delete(ctx context.Context, userID, taskID, id string) error
+	Move(ctx context.Context, userID, taskID, id string, m data.TaskMove) (data.TaskItem, error)
+}
@@ -0,0 +1,155 @@
+// Code generated by mockery v2.20.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	data "github.com/romankravchuk/eldorado/internal/data"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// AttachmentStorage is an autogenerated mock type for the AttachmentStorage type
+type AttachmentStorage struct {
+	mock.Mock
+}
+
+// DeleteOrphans provides a mock function with given fields: ctx, ids
+func (_m *AttachmentStorage) DeleteOrphans(ctx context.Context, ids ...string) error {
+	_va := make([]interface{}, len(ids))
+	for _i := range ids {
+		_va[_i] = ids[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, ...string) error); ok {
+		r0 = rf(ctx, ids...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Detach provides a mock function with given fields: ctx, userID, taskID, id
+func (_m *AttachmentStorage) Detach(ctx context.Context, userID string, taskID string, id string) error {
+	ret := _m.Called(ctx, userID, taskID, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, userID, taskID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FindByID provides a mock function with given fields: ctx, userID, taskID, id
+func (_m *AttachmentStorage) FindByID(ctx context.Context, userID string, taskID string, id string) (data.TaskAttachment, error) {
+	ret := _m.Called(ctx, userID, taskID, id)
+
+	var r0 data.TaskAttachment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (data.TaskAttachment, error)); ok {
+		return rf(ctx, userID, taskID, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) data.TaskAttachment); ok {
+		r0 = rf(ctx, userID, taskID, id)
+	} else {
+		r0 = ret.Get(0).(data.TaskAttachment)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, userID, taskID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindByTaskID provides a mock function with given fields: ctx, userID, taskID
+func (_m *AttachmentStorage) FindByTaskID(ctx context.Context, userID string, taskID string) ([]data.TaskAttachment, error) {
+	ret := _m.Called(ctx, userID, taskID)
+
+	var r0 []data.TaskAttachment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]data.TaskAttachment, error)); ok {
+		return rf(ctx, userID, taskID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []data.TaskAttachment); ok {
+		r0 = rf(ctx, userID, taskID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]data.TaskAttachment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, userID, taskID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Orphans provides a mock function with given fields: ctx, limit
+func (_m *AttachmentStorage) Orphans(ctx context.Context, limit int) ([]data.TaskAttachment, error) {
+	ret := _m.Called(ctx, limit)
+
+	var r0 []data.TaskAttachment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]data.TaskAttachment, error)); ok {
+		return rf(ctx, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []data.TaskAttachment); ok {
+		r0 = rf(ctx, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]data.TaskAttachment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Save provides a mock function with given fields: ctx, userID, a
+func (_m *AttachmentStorage) Save(ctx context.Context, userID string, a *data.TaskAttachment) error {
+	ret := _m.Called(ctx, userID, a)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *data.TaskAttachment) error); ok {
+		r0 = rf(ctx, userID, a)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewAttachmentStorage interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewAttachmentStorage creates a new instance of AttachmentStorage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewAttachmentStorage(t mockConstructorTestingTNewAttachmentStorage) *AttachmentStorage {
+	mock := &AttachmentStorage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
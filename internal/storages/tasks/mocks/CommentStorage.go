@@ -0,0 +1,106 @@
+// Code generated by mockery v2.20.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	data "github.com/romankravchuk/eldorado/internal/data"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// CommentStorage is an autogenerated mock type for the CommentStorage type
+type CommentStorage struct {
+	mock.Mock
+}
+
+// Delete provides a mock function with given fields: ctx, userID, taskID, id
+func (_m *CommentStorage) Delete(ctx context.Context, userID string, taskID string, id string) error {
+	ret := _m.Called(ctx, userID, taskID, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, userID, taskID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FindByTaskID provides a mock function with given fields: ctx, q
+func (_m *CommentStorage) FindByTaskID(ctx context.Context, q data.TaskCommentsQuery) (data.TaskCommentsPage, error) {
+	ret := _m.Called(ctx, q)
+
+	var r0 data.TaskCommentsPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, data.TaskCommentsQuery) (data.TaskCommentsPage, error)); ok {
+		return rf(ctx, q)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, data.TaskCommentsQuery) data.TaskCommentsPage); ok {
+		r0 = rf(ctx, q)
+	} else {
+		r0 = ret.Get(0).(data.TaskCommentsPage)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, data.TaskCommentsQuery) error); ok {
+		r1 = rf(ctx, q)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Save provides a mock function with given fields: ctx, c
+func (_m *CommentStorage) Save(ctx context.Context, c *data.TaskComment) error {
+	ret := _m.Called(ctx, c)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *data.TaskComment) error); ok {
+		r0 = rf(ctx, c)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: ctx, userID, taskID, id, content
+func (_m *CommentStorage) Update(ctx context.Context, userID string, taskID string, id string, content string) (data.TaskComment, error) {
+	ret := _m.Called(ctx, userID, taskID, id, content)
+
+	var r0 data.TaskComment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string) (data.TaskComment, error)); ok {
+		return rf(ctx, userID, taskID, id, content)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string) data.TaskComment); ok {
+		r0 = rf(ctx, userID, taskID, id, content)
+	} else {
+		r0 = ret.Get(0).(data.TaskComment)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, string) error); ok {
+		r1 = rf(ctx, userID, taskID, id, content)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewCommentStorage interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewCommentStorage creates a new instance of CommentStorage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewCommentStorage(t mockConstructorTestingTNewCommentStorage) *CommentStorage {
+	mock := &CommentStorage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,132 @@
+// Code generated by mockery v2.20.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	data "github.com/romankravchuk/eldorado/internal/data"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ItemStorage is an autogenerated mock type for the ItemStorage type
+type ItemStorage struct {
+	mock.Mock
+}
+
+// Delete provides a mock function with given fields: ctx, userID, taskID, id
+func (_m *ItemStorage) Delete(ctx context.Context, userID string, taskID string, id string) error {
+	ret := _m.Called(ctx, userID, taskID, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, userID, taskID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FindByTaskID provides a mock function with given fields: ctx, userID, taskID
+func (_m *ItemStorage) FindByTaskID(ctx context.Context, userID string, taskID string) ([]data.TaskItem, error) {
+	ret := _m.Called(ctx, userID, taskID)
+
+	var r0 []data.TaskItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]data.TaskItem, error)); ok {
+		return rf(ctx, userID, taskID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []data.TaskItem); ok {
+		r0 = rf(ctx, userID, taskID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]data.TaskItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, userID, taskID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Move provides a mock function with given fields: ctx, userID, taskID, id, m
+func (_m *ItemStorage) Move(ctx context.Context, userID string, taskID string, id string, m data.TaskMove) (data.TaskItem, error) {
+	ret := _m.Called(ctx, userID, taskID, id, m)
+
+	var r0 data.TaskItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, data.TaskMove) (data.TaskItem, error)); ok {
+		return rf(ctx, userID, taskID, id, m)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, data.TaskMove) data.TaskItem); ok {
+		r0 = rf(ctx, userID, taskID, id, m)
+	} else {
+		r0 = ret.Get(0).(data.TaskItem)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, data.TaskMove) error); ok {
+		r1 = rf(ctx, userID, taskID, id, m)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Patch provides a mock function with given fields: ctx, userID, taskID, id, p
+func (_m *ItemStorage) Patch(ctx context.Context, userID string, taskID string, id string, p data.TaskItemPatch) (data.TaskItem, error) {
+	ret := _m.Called(ctx, userID, taskID, id, p)
+
+	var r0 data.TaskItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, data.TaskItemPatch) (data.TaskItem, error)); ok {
+		return rf(ctx, userID, taskID, id, p)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, data.TaskItemPatch) data.TaskItem); ok {
+		r0 = rf(ctx, userID, taskID, id, p)
+	} else {
+		r0 = ret.Get(0).(data.TaskItem)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, data.TaskItemPatch) error); ok {
+		r1 = rf(ctx, userID, taskID, id, p)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Save provides a mock function with given fields: ctx, userID, item
+func (_m *ItemStorage) Save(ctx context.Context, userID string, item *data.TaskItem) error {
+	ret := _m.Called(ctx, userID, item)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *data.TaskItem) error); ok {
+		r0 = rf(ctx, userID, item)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewItemStorage interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewItemStorage creates a new instance of ItemStorage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewItemStorage(t mockConstructorTestingTNewItemStorage) *ItemStorage {
+	mock := &ItemStorage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -7,6 +7,8 @@ import (
 
 	data "github.com/romankravchuk/eldorado/internal/data"
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // Storage is an autogenerated mock type for the Storage type
@@ -14,13 +16,65 @@ type Storage struct {
 	mock.Mock
 }
 
-// Delete provides a mock function with given fields: ctx, id
-func (_m *Storage) Delete(ctx context.Context, id string) error {
-	ret := _m.Called(ctx, id)
+// AssignedStatistic provides a mock function with given fields: ctx
+func (_m *Storage) AssignedStatistic(ctx context.Context) ([]data.StatisticTask, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []data.StatisticTask
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]data.StatisticTask, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []data.StatisticTask); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]data.StatisticTask)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Batch provides a mock function with given fields: ctx, userID, ops, atomic
+func (_m *Storage) Batch(ctx context.Context, userID string, ops []data.TaskOp, atomic bool) ([]data.TaskOpResult, error) {
+	ret := _m.Called(ctx, userID, ops, atomic)
+
+	var r0 []data.TaskOpResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []data.TaskOp, bool) ([]data.TaskOpResult, error)); ok {
+		return rf(ctx, userID, ops, atomic)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []data.TaskOp, bool) []data.TaskOpResult); ok {
+		r0 = rf(ctx, userID, ops, atomic)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]data.TaskOpResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []data.TaskOp, bool) error); ok {
+		r1 = rf(ctx, userID, ops, atomic)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: ctx, userID, id, version
+func (_m *Storage) Delete(ctx context.Context, userID string, id string, version int) error {
+	ret := _m.Called(ctx, userID, id, version)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
-		r0 = rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) error); ok {
+		r0 = rf(ctx, userID, id, version)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -28,25 +82,271 @@ func (_m *Storage) Delete(ctx context.Context, id string) error {
 	return r0
 }
 
-// FindByUserID provides a mock function with given fields: ctx, userID
-func (_m *Storage) FindByUserID(ctx context.Context, userID string) ([]data.Task, error) {
-	ret := _m.Called(ctx, userID)
+// FindByID provides a mock function with given fields: ctx, userID, id
+func (_m *Storage) FindByID(ctx context.Context, userID string, id string) (data.Task, error) {
+	ret := _m.Called(ctx, userID, id)
 
-	var r0 []data.Task
+	var r0 data.Task
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) ([]data.Task, error)); ok {
-		return rf(ctx, userID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (data.Task, error)); ok {
+		return rf(ctx, userID, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) data.Task); ok {
+		r0 = rf(ctx, userID, id)
+	} else {
+		r0 = ret.Get(0).(data.Task)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) []data.Task); ok {
-		r0 = rf(ctx, userID)
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, userID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindByUserID provides a mock function with given fields: ctx, q
+func (_m *Storage) FindByUserID(ctx context.Context, q data.TasksQuery) (data.TasksPage, error) {
+	ret := _m.Called(ctx, q)
+
+	var r0 data.TasksPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, data.TasksQuery) (data.TasksPage, error)); ok {
+		return rf(ctx, q)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, data.TasksQuery) data.TasksPage); ok {
+		r0 = rf(ctx, q)
+	} else {
+		r0 = ret.Get(0).(data.TasksPage)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, data.TasksQuery) error); ok {
+		r1 = rf(ctx, q)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// History provides a mock function with given fields: ctx, q
+func (_m *Storage) History(ctx context.Context, q data.TaskEventsQuery) ([]data.TaskEvent, error) {
+	ret := _m.Called(ctx, q)
+
+	var r0 []data.TaskEvent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, data.TaskEventsQuery) ([]data.TaskEvent, error)); ok {
+		return rf(ctx, q)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, data.TaskEventsQuery) []data.TaskEvent); ok {
+		r0 = rf(ctx, q)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]data.Task)
+			r0 = ret.Get(0).([]data.TaskEvent)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, userID)
+	if rf, ok := ret.Get(1).(func(context.Context, data.TaskEventsQuery) error); ok {
+		r1 = rf(ctx, q)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Import provides a mock function with given fields: ctx, userID, rows, dryRun
+func (_m *Storage) Import(ctx context.Context, userID string, rows []data.TaskImport, dryRun bool) ([]data.TaskImportResult, error) {
+	ret := _m.Called(ctx, userID, rows, dryRun)
+
+	var r0 []data.TaskImportResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []data.TaskImport, bool) ([]data.TaskImportResult, error)); ok {
+		return rf(ctx, userID, rows, dryRun)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []data.TaskImport, bool) []data.TaskImportResult); ok {
+		r0 = rf(ctx, userID, rows, dryRun)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]data.TaskImportResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []data.TaskImport, bool) error); ok {
+		r1 = rf(ctx, userID, rows, dryRun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Move provides a mock function with given fields: ctx, userID, id, m
+func (_m *Storage) Move(ctx context.Context, userID string, id string, m data.TaskMove) (data.Task, error) {
+	ret := _m.Called(ctx, userID, id, m)
+
+	var r0 data.Task
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, data.TaskMove) (data.Task, error)); ok {
+		return rf(ctx, userID, id, m)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, data.TaskMove) data.Task); ok {
+		r0 = rf(ctx, userID, id, m)
+	} else {
+		r0 = ret.Get(0).(data.Task)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, data.TaskMove) error); ok {
+		r1 = rf(ctx, userID, id, m)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// OverdueStatistic provides a mock function with given fields: ctx
+func (_m *Storage) OverdueStatistic(ctx context.Context) ([]data.StatisticTask, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []data.StatisticTask
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]data.StatisticTask, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []data.StatisticTask); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]data.StatisticTask)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Patch provides a mock function with given fields: ctx, userID, id, p
+func (_m *Storage) Patch(ctx context.Context, userID string, id string, p data.TaskPatch) (data.Task, error) {
+	ret := _m.Called(ctx, userID, id, p)
+
+	var r0 data.Task
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, data.TaskPatch) (data.Task, error)); ok {
+		return rf(ctx, userID, id, p)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, data.TaskPatch) data.Task); ok {
+		r0 = rf(ctx, userID, id, p)
+	} else {
+		r0 = ret.Get(0).(data.Task)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, data.TaskPatch) error); ok {
+		r1 = rf(ctx, userID, id, p)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ProjectStatistic provides a mock function with given fields: ctx
+func (_m *Storage) ProjectStatistic(ctx context.Context) ([]data.ProjectStatistic, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []data.ProjectStatistic
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]data.ProjectStatistic, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []data.ProjectStatistic); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]data.ProjectStatistic)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Purge provides a mock function with given fields: ctx, userID, id, version
+func (_m *Storage) Purge(ctx context.Context, userID string, id string, version int) (data.Task, error) {
+	ret := _m.Called(ctx, userID, id, version)
+
+	var r0 data.Task
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) (data.Task, error)); ok {
+		return rf(ctx, userID, id, version)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) data.Task); ok {
+		r0 = rf(ctx, userID, id, version)
+	} else {
+		r0 = ret.Get(0).(data.Task)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int) error); ok {
+		r1 = rf(ctx, userID, id, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PurgeDeleted provides a mock function with given fields: ctx, before
+func (_m *Storage) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	ret := _m.Called(ctx, before)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (int64, error)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Restore provides a mock function with given fields: ctx, userID, id
+func (_m *Storage) Restore(ctx context.Context, userID string, id string) (data.Task, error) {
+	ret := _m.Called(ctx, userID, id)
+
+	var r0 data.Task
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (data.Task, error)); ok {
+		return rf(ctx, userID, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) data.Task); ok {
+		r0 = rf(ctx, userID, id)
+	} else {
+		r0 = ret.Get(0).(data.Task)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, userID, id)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -68,6 +368,32 @@ func (_m *Storage) Save(ctx context.Context, task *data.Task) error {
 	return r0
 }
 
+// Search provides a mock function with given fields: ctx, q
+func (_m *Storage) Search(ctx context.Context, q data.TasksSearch) ([]data.TaskHit, error) {
+	ret := _m.Called(ctx, q)
+
+	var r0 []data.TaskHit
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, data.TasksSearch) ([]data.TaskHit, error)); ok {
+		return rf(ctx, q)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, data.TasksSearch) []data.TaskHit); ok {
+		r0 = rf(ctx, q)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]data.TaskHit)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, data.TasksSearch) error); ok {
+		r1 = rf(ctx, q)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UncompletedStatistic provides a mock function with given fields: ctx
 func (_m *Storage) UncompletedStatistic(ctx context.Context) ([]data.StatisticTask, error) {
 	ret := _m.Called(ctx)
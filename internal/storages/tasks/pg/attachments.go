@@ -0,0 +1,233 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/storages"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
+)
+
+// AttachmentsStorage is a postgres implementation of tasks.AttachmentStorage.
+//
+// Records of a purged task lose their task_id and are kept as orphans
+// until their blobs are deleted.
+type AttachmentsStorage struct {
+	db *sql.DB
+}
+
+// NewAttachments returns new AttachmentsStorage instance with postgres db pool.
+//
+// If db is nil returns storages.ErrNilDBPool.
+func NewAttachments(db *sql.DB) (*AttachmentsStorage, error) {
+	if db == nil {
+		return nil, storages.ErrNilDBPool
+	}
+
+	return &AttachmentsStorage{db: db}, nil
+}
+
+// FindByTaskID returns attachments of a given task, the oldest first.
+//
+// If the task does not exist or is not visible to the user returns tasks.ErrNotFound.
+func (s *AttachmentsStorage) FindByTaskID(ctx context.Context, userID, taskID string) ([]data.TaskAttachment, error) {
+	const query = "SELECT " + attachmentColumns + " FROM task_attachments a WHERE a.task_id = $1 ORDER BY a.created_on, a.id"
+
+	if err := taskExists(ctx, s.db, userID, taskID); err != nil {
+		return nil, err
+	}
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := s.db.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanAttachments(rows)
+}
+
+// FindByID returns an attachment of a given task.
+//
+// If the task does not exist or is not visible to the user returns tasks.ErrNotFound.
+// If the attachment does not exist returns tasks.ErrAttachmentNotFound.
+func (s *AttachmentsStorage) FindByID(ctx context.Context, userID, taskID, id string) (data.TaskAttachment, error) {
+	const query = "SELECT " + attachmentColumns + " FROM task_attachments a WHERE a.id = $1 AND a.task_id = $2"
+
+	if err := taskExists(ctx, s.db, userID, taskID); err != nil {
+		return data.TaskAttachment{}, err
+	}
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := s.db.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return data.TaskAttachment{}, err
+	}
+	defer stmt.Close()
+
+	var a data.TaskAttachment
+	if err = scanAttachment(stmt.QueryRowContext(ctx, id, taskID), &a); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return data.TaskAttachment{}, tasks.ErrAttachmentNotFound
+		}
+
+		return data.TaskAttachment{}, err
+	}
+
+	return a, nil
+}
+
+// Save records an attachment with a given id of a.TaskID.
+//
+// If save succeeds CreatedOn field is filled.
+// If the task does not exist or is not editable by the user returns tasks.ErrNotFound.
+func (s *AttachmentsStorage) Save(ctx context.Context, userID string, a *data.TaskAttachment) error {
+	query := "INSERT INTO task_attachments (id, task_id, uploader_id, filename, content_type, size, blob_key) SELECT $1, tasks.id, $3, $4, $5, $6, $7 FROM tasks WHERE tasks.id = $2 AND " + writableBy(3) + " AND tasks.is_deleted = false RETURNING created_on"
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := s.db.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	err = stmt.QueryRowContext(ctx, a.ID, a.TaskID, userID, a.Filename, a.ContentType, a.Size, a.Key).Scan(&a.CreatedOn)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return tasks.ErrNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// Detach turns an attachment of a given task into an orphan.
+//
+// If the task does not exist or is not editable by the user returns tasks.ErrNotFound.
+// If the attachment does not exist returns tasks.ErrAttachmentNotFound.
+func (s *AttachmentsStorage) Detach(ctx context.Context, userID, taskID, id string) error {
+	query := "UPDATE task_attachments a SET task_id = NULL WHERE a.id = $1 AND a.task_id = $2 AND EXISTS (SELECT 1 FROM tasks WHERE tasks.id = a.task_id AND " + writableBy(3) + " AND tasks.is_deleted = false)"
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := s.db.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, id, taskID, userID)
+	if err != nil {
+		return err
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count == 1 {
+		return nil
+	}
+
+	if err := taskExists(ctx, s.db, userID, taskID); err != nil {
+		return err
+	}
+
+	return tasks.ErrAttachmentNotFound
+}
+
+// Orphans returns up to limit attachments detached from their tasks, the oldest first.
+func (s *AttachmentsStorage) Orphans(ctx context.Context, limit int) ([]data.TaskAttachment, error) {
+	const query = "SELECT " + attachmentColumns + " FROM task_attachments a WHERE a.task_id IS NULL ORDER BY a.created_on, a.id LIMIT $1"
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := s.db.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanAttachments(rows)
+}
+
+// DeleteOrphans deletes records of orphaned attachments, attached ones are kept.
+func (s *AttachmentsStorage) DeleteOrphans(ctx context.Context, ids ...string) error {
+	const query = "DELETE FROM task_attachments WHERE id = ANY($1) AND task_id IS NULL"
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := s.db.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, pq.Array(ids))
+	return err
+}
+
+const attachmentColumns = "a.id, COALESCE(a.task_id::text, ''), COALESCE(a.uploader_id::text, ''), a.filename, a.content_type, a.size, a.blob_key, a.created_on"
+
+// scanAttachment scans a row selected with attachmentColumns into a given attachment.
+func scanAttachment(row scanner, a *data.TaskAttachment) error {
+	return row.Scan(&a.ID, &a.TaskID, &a.UploaderID, &a.Filename, &a.ContentType, &a.Size, &a.Key, &a.CreatedOn)
+}
+
+// scanAttachments scans and closes rows selected with attachmentColumns.
+func scanAttachments(rows *sql.Rows) ([]data.TaskAttachment, error) {
+	var (
+		attachments []data.TaskAttachment
+		err         error
+	)
+	for rows.Next() {
+		var a data.TaskAttachment
+		if err = scanAttachment(rows, &a); err != nil {
+			break
+		}
+		attachments = append(attachments, a)
+	}
+
+	if closeErr := rows.Close(); closeErr != nil {
+		return nil, closeErr
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
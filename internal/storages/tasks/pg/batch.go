@@ -0,0 +1,130 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
+)
+
+// Batch executes given operations of a user in a single transaction.
+//
+// Results are in the order of the operations. If atomic is set the first failed
+// operation rolls back the whole batch and its error is returned as *tasks.OpError.
+// Otherwise every operation runs in its own savepoint, a failed operation
+// is rolled back alone and its error is reported in its result.
+func (s *TasksStorage) Batch(ctx context.Context, userID string, ops []data.TaskOp, atomic bool) ([]data.TaskOpResult, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	results := make([]data.TaskOpResult, len(ops))
+	for i, op := range ops {
+		if atomic {
+			if results[i], err = execOp(ctx, tx, userID, op); err != nil {
+				return nil, &tasks.OpError{Index: i, Err: err}
+			}
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, "SAVEPOINT batch_op"); err != nil {
+			return nil, err
+		}
+
+		results[i], err = execOp(ctx, tx, userID, op)
+		if err != nil {
+			results[i] = data.TaskOpResult{Err: err}
+
+			if _, err := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT batch_op"); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT batch_op"); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// execOp executes a single batch operation in a given transaction.
+func execOp(ctx context.Context, tx *sql.Tx, userID string, op data.TaskOp) (data.TaskOpResult, error) {
+	if op.Kind == data.TaskOpCreate {
+		t := op.Task
+		t.UserID = userID
+		if err := save(ctx, tx, &t); err != nil {
+			return data.TaskOpResult{}, err
+		}
+
+		if err := recordEvent(ctx, tx, userID, data.TaskEventCreated, nil, t); err != nil {
+			return data.TaskOpResult{}, err
+		}
+
+		return data.TaskOpResult{Task: t}, nil
+	}
+
+	prev, err := lockTask(ctx, tx, userID, op.ID)
+	if err != nil {
+		return data.TaskOpResult{}, err
+	}
+
+	switch op.Kind {
+	case data.TaskOpUpdate:
+		p := op.Patch
+		p.Version = op.Version
+
+		t, err := patch(ctx, tx, userID, op.ID, p)
+		if errors.Is(err, sql.ErrNoRows) {
+			return data.TaskOpResult{}, conflictOrForbidden(prev, op.Version)
+		}
+		if err != nil {
+			return data.TaskOpResult{}, err
+		}
+
+		if err := recordEvent(ctx, tx, userID, data.TaskEventUpdated, &prev, t); err != nil {
+			return data.TaskOpResult{}, err
+		}
+
+		if err := spawnNext(ctx, tx, userID, prev, &t); err != nil {
+			return data.TaskOpResult{}, err
+		}
+
+		return data.TaskOpResult{Task: t, Previous: &prev}, nil
+	case data.TaskOpDelete:
+		deleted, err := softDelete(ctx, tx, userID, op.ID, op.Version)
+		if err != nil {
+			return data.TaskOpResult{}, err
+		}
+		if !deleted {
+			return data.TaskOpResult{}, conflictOrForbidden(prev, op.Version)
+		}
+
+		if err := recordEvent(ctx, tx, userID, data.TaskEventDeleted, nil, prev); err != nil {
+			return data.TaskOpResult{}, err
+		}
+
+		return data.TaskOpResult{Previous: &prev}, nil
+	default:
+		return data.TaskOpResult{}, fmt.Errorf("unknown batch operation %q", op.Kind)
+	}
+}
+
+// conflictOrForbidden explains why a write of a task visible to the user matched no rows.
+func conflictOrForbidden(prev data.Task, version int) error {
+	if version != 0 && version != prev.Version {
+		return tasks.ErrVersionConflict
+	}
+
+	return tasks.ErrForbidden
+}
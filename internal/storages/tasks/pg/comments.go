@@ -0,0 +1,249 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/storages"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
+)
+
+// commentsCursorSort is the sort of comment cursors, comments are always sorted by creation.
+const commentsCursorSort = "comments"
+
+// CommentsStorage is a postgres implementation of tasks.CommentStorage.
+//
+// Deleted comments are kept with is_deleted flag like the deleted tasks,
+// they are removed with their task only.
+type CommentsStorage struct {
+	db *sql.DB
+}
+
+// NewComments returns new CommentsStorage instance with postgres db pool.
+//
+// If db is nil returns storages.ErrNilDBPool.
+func NewComments(db *sql.DB) (*CommentsStorage, error) {
+	if db == nil {
+		return nil, storages.ErrNilDBPool
+	}
+
+	return &CommentsStorage{db: db}, nil
+}
+
+// FindByTaskID returns a page of comments of a given task, the oldest first.
+//
+// If the task does not exist or is not visible to the user returns tasks.ErrNotFound.
+// If q.Cursor is malformed returns tasks.ErrInvalidCursor.
+func (s *CommentsStorage) FindByTaskID(ctx context.Context, q data.TaskCommentsQuery) (data.TaskCommentsPage, error) {
+	const query = "SELECT " + commentColumns + " FROM task_comments c WHERE c.task_id = $1 AND c.is_deleted = false AND ($3::timestamp IS NULL OR (c.created_on, c.id) > ($3, $4)) ORDER BY c.created_on, c.id LIMIT $2"
+
+	limit := q.Limit
+	if limit <= 0 || limit > data.CommentsMaxLimit {
+		limit = data.CommentsDefaultLimit
+	}
+
+	var (
+		after   *time.Time
+		afterID *string
+	)
+	if q.Cursor != "" {
+		c, err := tasks.DecodeCursor(q.Cursor)
+		if err != nil {
+			return data.TaskCommentsPage{}, err
+		}
+
+		createdOn, err := time.Parse(time.RFC3339Nano, c.Value)
+		if c.Sort != commentsCursorSort || err != nil {
+			return data.TaskCommentsPage{}, tasks.ErrInvalidCursor
+		}
+
+		after, afterID = &createdOn, &c.ID
+	}
+
+	if err := taskExists(ctx, s.db, q.UserID, q.TaskID); err != nil {
+		return data.TaskCommentsPage{}, err
+	}
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := s.db.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return data.TaskCommentsPage{}, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, q.TaskID, limit+1, after, afterID)
+	if err != nil {
+		return data.TaskCommentsPage{}, err
+	}
+
+	var comments []data.TaskComment
+	for rows.Next() {
+		var c data.TaskComment
+		if err = scanComment(rows, &c); err != nil {
+			break
+		}
+		comments = append(comments, c)
+	}
+
+	if closeErr := rows.Close(); closeErr != nil {
+		return data.TaskCommentsPage{}, closeErr
+	}
+
+	if err != nil {
+		return data.TaskCommentsPage{}, err
+	}
+
+	if err := rows.Err(); err != nil {
+		return data.TaskCommentsPage{}, err
+	}
+
+	page := data.TaskCommentsPage{Comments: comments}
+	if len(comments) > limit {
+		page.Comments = comments[:limit]
+
+		last := page.Comments[limit-1]
+		page.NextCursor = tasks.EncodeCursor(tasks.Cursor{
+			Sort:  commentsCursorSort,
+			Value: last.CreatedOn.Format(time.RFC3339Nano),
+			ID:    last.ID,
+		})
+	}
+
+	return page, nil
+}
+
+// Save adds a comment of c.AuthorID to the discussion of c.TaskID.
+//
+// If save succeeds ID, CreatedOn and UpdatedOn fields are filled.
+// If the task does not exist or is not visible to the author returns tasks.ErrNotFound.
+func (s *CommentsStorage) Save(ctx context.Context, c *data.TaskComment) error {
+	query := "INSERT INTO task_comments (task_id, author_id, content) SELECT tasks.id, $2, $3 FROM tasks WHERE tasks.id = $1 AND " + readableBy(2) + " AND tasks.is_deleted = false RETURNING id, created_on, updated_on"
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := s.db.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	err = stmt.QueryRowContext(ctx, c.TaskID, c.AuthorID, c.Content).Scan(&c.ID, &c.CreatedOn, &c.UpdatedOn)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return tasks.ErrNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// Update replaces the content of a comment written by a given user.
+//
+// If the task does not exist or is not visible to the user returns tasks.ErrNotFound.
+// If the comment does not exist returns tasks.ErrCommentNotFound,
+// if it is written by another user returns tasks.ErrForbidden.
+func (s *CommentsStorage) Update(ctx context.Context, userID, taskID, id, content string) (data.TaskComment, error) {
+	query := "UPDATE task_comments c SET content = $1, updated_on = CURRENT_TIMESTAMP WHERE c.id = $2 AND c.task_id = $3 AND c.author_id = $4 AND c.is_deleted = false AND EXISTS (SELECT 1 FROM tasks WHERE tasks.id = c.task_id AND " + readableBy(4) + " AND tasks.is_deleted = false) RETURNING " + commentColumns
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := s.db.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return data.TaskComment{}, err
+	}
+	defer stmt.Close()
+
+	var c data.TaskComment
+	if err = scanComment(stmt.QueryRowContext(ctx, content, id, taskID, userID), &c); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return data.TaskComment{}, s.notFoundOrForbidden(ctx, userID, taskID, id)
+		}
+
+		return data.TaskComment{}, err
+	}
+
+	return c, nil
+}
+
+// Delete moves a comment written by a given user out of the discussion.
+//
+// If the task does not exist or is not visible to the user returns tasks.ErrNotFound.
+// If the comment does not exist returns tasks.ErrCommentNotFound,
+// if it is written by another user returns tasks.ErrForbidden.
+func (s *CommentsStorage) Delete(ctx context.Context, userID, taskID, id string) error {
+	query := "UPDATE task_comments c SET is_deleted = true, deleted_on = CURRENT_TIMESTAMP WHERE c.id = $1 AND c.task_id = $2 AND c.author_id = $3 AND c.is_deleted = false AND EXISTS (SELECT 1 FROM tasks WHERE tasks.id = c.task_id AND " + readableBy(3) + " AND tasks.is_deleted = false)"
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := s.db.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, id, taskID, userID)
+	if err != nil {
+		return err
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count != 1 {
+		return s.notFoundOrForbidden(ctx, userID, taskID, id)
+	}
+
+	return nil
+}
+
+// notFoundOrForbidden explains why a write of a comment matched no rows.
+func (s *CommentsStorage) notFoundOrForbidden(ctx context.Context, userID, taskID, id string) error {
+	const query = "SELECT COALESCE(author_id::text, '') FROM task_comments WHERE id = $1 AND task_id = $2 AND is_deleted = false"
+
+	if err := taskExists(ctx, s.db, userID, taskID); err != nil {
+		return err
+	}
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := s.db.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	var authorID string
+	if err = stmt.QueryRowContext(ctx, id, taskID).Scan(&authorID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return tasks.ErrCommentNotFound
+		}
+
+		return err
+	}
+
+	if authorID != userID {
+		return tasks.ErrForbidden
+	}
+
+	return tasks.ErrCommentNotFound
+}
+
+const commentColumns = "c.id, c.task_id, COALESCE(c.author_id::text, ''), c.content, c.created_on, c.updated_on, c.deleted_on"
+
+// scanComment scans a row selected with commentColumns into a given comment.
+func scanComment(row scanner, c *data.TaskComment) error {
+	return row.Scan(&c.ID, &c.TaskID, &c.AuthorID, &c.Content, &c.CreatedOn, &c.UpdatedOn, &c.DeletedOn)
+}
@@ -0,0 +1,232 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/storages"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
+)
+
+// History returns a page of events of a task visible to a given user, the newest first.
+//
+// The history of a task in the trash is visible too.
+// If the task does not exist or is not visible to the user returns tasks.ErrNotFound.
+func (s *TasksStorage) History(ctx context.Context, q data.TaskEventsQuery) ([]data.TaskEvent, error) {
+	limit := q.Limit
+	if limit <= 0 || limit > data.TaskEventsMaxLimit {
+		limit = data.TaskEventsDefaultLimit
+	}
+
+	visible, err := s.visible(ctx, q.UserID, q.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	if !visible {
+		return nil, tasks.ErrNotFound
+	}
+
+	const query = "SELECT e.id, e.task_id, COALESCE(e.actor_id::text, ''), COALESCE(u.email, ''), e.kind, e.changes, e.created_on FROM task_events e LEFT JOIN users u ON u.id = e.actor_id WHERE e.task_id = $1 AND ($2 = 0 OR e.id < $2) ORDER BY e.id DESC LIMIT $3"
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := s.db.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, q.TaskID, q.Before, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []data.TaskEvent
+	for rows.Next() {
+		var (
+			e       data.TaskEvent
+			changes []byte
+		)
+		if err = rows.Scan(&e.ID, &e.TaskID, &e.ActorID, &e.ActorEmail, &e.Kind, &changes, &e.CreatedOn); err != nil {
+			break
+		}
+		if err = json.Unmarshal(changes, &e.Changes); err != nil {
+			break
+		}
+		events = append(events, e)
+	}
+
+	if closeErr := rows.Close(); closeErr != nil {
+		return nil, closeErr
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// visible reports whether a task, either active or in the trash, is visible to a given user.
+func (s *TasksStorage) visible(ctx context.Context, userID, id string) (bool, error) {
+	query := "SELECT EXISTS (SELECT 1 FROM tasks WHERE id = $1 AND " + readableBy(2) + ")"
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := s.db.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return false, err
+	}
+	defer stmt.Close()
+
+	var visible bool
+	if err = stmt.QueryRowContext(ctx, id, userID).Scan(&visible); err != nil {
+		return false, err
+	}
+
+	return visible, nil
+}
+
+// inTx runs f in a transaction which is committed if f succeeds.
+func (s *TasksStorage) inTx(ctx context.Context, f func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := f(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// lockTask selects an active task visible to a given user and locks it until the end of the transaction.
+//
+// If the task does not exist or is not visible to the user returns tasks.ErrNotFound.
+func lockTask(ctx context.Context, tx *sql.Tx, userID, id string) (data.Task, error) {
+	query := "SELECT " + taskColumns + " FROM tasks WHERE id = $1 AND " + readableBy(2) + " AND is_deleted = false FOR UPDATE"
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := tx.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return data.Task{}, err
+	}
+	defer stmt.Close()
+
+	var task data.Task
+	if err = scanTask(stmt.QueryRowContext(ctx, id, userID), &task); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return data.Task{}, tasks.ErrNotFound
+		}
+
+		return data.Task{}, err
+	}
+
+	return task, nil
+}
+
+// recordEvent appends an event of a task changed by a given actor to the task history.
+//
+// Changes of created and updated tasks are the fields of next differing from prev,
+// a nil prev stands for a created task. An update which changed nothing is not recorded.
+// Other events have no changes, only next.ID is used.
+func recordEvent(ctx context.Context, tx *sql.Tx, actorID, kind string, prev *data.Task, next data.Task) error {
+	changes := map[string]data.TaskChange{}
+	switch kind {
+	case data.TaskEventCreated, data.TaskEventUpdated:
+		changes = diffTasks(prev, next)
+	}
+
+	if kind == data.TaskEventUpdated && len(changes) == 0 {
+		return nil
+	}
+
+	raw, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	const query = "INSERT INTO task_events (task_id, actor_id, kind, changes) VALUES ($1, $2, $3, $4)"
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := tx.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, next.ID, actorID, kind, raw)
+	return err
+}
+
+// diffTasks returns the recorded fields of next differing from prev.
+//
+// A nil prev yields all non empty fields of next.
+func diffTasks(prev *data.Task, next data.Task) map[string]data.TaskChange {
+	fields := func(t data.Task) map[string]any {
+		return map[string]any{
+			"title":        t.Title,
+			"description":  t.Description,
+			"is_completed": t.IsCompleted,
+			"priority":     t.Priority,
+			"due_at":       timeValue(t.DueAt),
+			"remind_at":    timeValue(t.RemindAt),
+			"project_id":   stringValue(t.ProjectID),
+			"assignee_id":  stringValue(t.AssigneeID),
+			"recurrence":   t.Recurrence,
+		}
+	}
+
+	changes := make(map[string]data.TaskChange)
+	to := fields(next)
+
+	if prev == nil {
+		for name, value := range to {
+			if value != nil && value != "" && value != false {
+				changes[name] = data.TaskChange{To: value}
+			}
+		}
+		return changes
+	}
+
+	from := fields(*prev)
+	for name, value := range to {
+		if from[name] != value {
+			changes[name] = data.TaskChange{From: from[name], To: value}
+		}
+	}
+
+	return changes
+}
+
+// timeValue returns an optional time as a comparable RFC 3339 string or nil.
+func timeValue(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// stringValue returns an optional string as a comparable value or nil.
+func stringValue(s *string) any {
+	if s == nil {
+		return nil
+	}
+	return *s
+}
@@ -0,0 +1,101 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/storages"
+)
+
+// Import saves tasks read from a file for a given user in a single transaction.
+//
+// Every row runs in its own savepoint, a failed row is rolled back alone and its error
+// is reported in its result. Duplicates are skipped, a row is compared with the tasks
+// visible to the user including the ones imported before it.
+// If dryRun is set the whole transaction is rolled back, so results show what would be imported.
+func (s *TasksStorage) Import(ctx context.Context, userID string, rows []data.TaskImport, dryRun bool) ([]data.TaskImportResult, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	results := make([]data.TaskImportResult, len(rows))
+	for i, row := range rows {
+		if _, err := tx.ExecContext(ctx, "SAVEPOINT import_row"); err != nil {
+			return nil, err
+		}
+
+		results[i], err = importRow(ctx, tx, userID, row)
+		if err != nil {
+			results[i] = data.TaskImportResult{Err: err}
+
+			if _, err := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT import_row"); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT import_row"); err != nil {
+			return nil, err
+		}
+	}
+
+	if dryRun {
+		return results, nil
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// importRow saves a task of a row unless it duplicates a task visible to the user.
+func importRow(ctx context.Context, tx *sql.Tx, userID string, row data.TaskImport) (data.TaskImportResult, error) {
+	t := row.Task
+	t.UserID = userID
+
+	duplicate, err := isDuplicate(ctx, tx, userID, row.ExternalID, t)
+	if err != nil {
+		return data.TaskImportResult{}, err
+	}
+
+	if duplicate {
+		return data.TaskImportResult{Task: t, Duplicate: true}, nil
+	}
+
+	if err := save(ctx, tx, &t); err != nil {
+		return data.TaskImportResult{}, err
+	}
+
+	if err := recordEvent(ctx, tx, userID, data.TaskEventCreated, nil, t); err != nil {
+		return data.TaskImportResult{}, err
+	}
+
+	return data.TaskImportResult{Task: t}, nil
+}
+
+// isDuplicate reports whether an active task visible to the user has a given external id
+// or the title (ignoring case) and the due time of a given task.
+func isDuplicate(ctx context.Context, tx *sql.Tx, userID, externalID string, t data.Task) (bool, error) {
+	query := "SELECT EXISTS (SELECT 1 FROM tasks WHERE " + readableBy(1) + " AND is_deleted = false AND (id::text = $2 OR (lower(title) = lower($3) AND due_at IS NOT DISTINCT FROM $4)))"
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := tx.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return false, err
+	}
+	defer stmt.Close()
+
+	var duplicate bool
+	if err = stmt.QueryRowContext(ctx, userID, externalID, t.Title, utc(t.DueAt)).Scan(&duplicate); err != nil {
+		return false, err
+	}
+
+	return duplicate, nil
+}
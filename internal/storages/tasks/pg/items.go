@@ -0,0 +1,336 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/storages"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
+)
+
+// ItemsStorage is a postgres implementation of tasks.ItemStorage.
+//
+// Every change of an item bumps the version of its task, because the task
+// representation includes the checklist progress.
+type ItemsStorage struct {
+	db *sql.DB
+}
+
+// NewItems returns new ItemsStorage instance with postgres db pool.
+//
+// If db is nil returns storages.ErrNilDBPool.
+func NewItems(db *sql.DB) (*ItemsStorage, error) {
+	if db == nil {
+		return nil, storages.ErrNilDBPool
+	}
+
+	return &ItemsStorage{db: db}, nil
+}
+
+// FindByTaskID returns items of a given task ordered by their positions.
+//
+// If the task does not exist or is not visible to the user returns tasks.ErrNotFound.
+func (s *ItemsStorage) FindByTaskID(ctx context.Context, userID, taskID string) ([]data.TaskItem, error) {
+	const query = "SELECT " + itemColumns + " FROM task_items i WHERE i.task_id = $1 ORDER BY i.position, i.id"
+
+	if err := taskExists(ctx, s.db, userID, taskID); err != nil {
+		return nil, err
+	}
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := s.db.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []data.TaskItem
+	for rows.Next() {
+		var item data.TaskItem
+		if err = scanItem(rows, &item); err != nil {
+			break
+		}
+		items = append(items, item)
+	}
+
+	if closeErr := rows.Close(); closeErr != nil {
+		return nil, closeErr
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// Save adds an item to the bottom of the checklist of item.TaskID.
+//
+// If save succeeds ID, IsCompleted, Position, CreatedOn and UpdatedOn fields are filled.
+// If the task does not exist or is not editable by the user returns tasks.ErrNotFound.
+func (s *ItemsStorage) Save(ctx context.Context, userID string, item *data.TaskItem) error {
+	const query = "INSERT INTO task_items (task_id, title, position) VALUES ($1, $2, (SELECT COALESCE(MAX(position), 0) + 1 FROM task_items WHERE task_id = $1)) RETURNING " + itemColumns
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := touchTask(ctx, tx, userID, item.TaskID); err != nil {
+		return err
+	}
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := tx.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	if err = scanItem(stmt.QueryRowContext(ctx, item.TaskID, item.Title), item); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Patch updates only the fields of an item set in a given patch.
+//
+// If the task does not exist or is not editable by the user returns tasks.ErrNotFound.
+// If the item does not exist returns tasks.ErrItemNotFound.
+func (s *ItemsStorage) Patch(ctx context.Context, userID, taskID, id string, p data.TaskItemPatch) (data.TaskItem, error) {
+	var (
+		sets []string
+		args []any
+	)
+
+	if p.Title != nil {
+		args = append(args, *p.Title)
+		sets = append(sets, fmt.Sprintf("title = $%d", len(args)))
+	}
+	if p.IsCompleted != nil {
+		args = append(args, *p.IsCompleted)
+		sets = append(sets, fmt.Sprintf("is_completed = $%d", len(args)))
+	}
+	sets = append(sets, "updated_on = CURRENT_TIMESTAMP")
+
+	args = append(args, id, taskID)
+	query := fmt.Sprintf(
+		"UPDATE task_items i SET %s WHERE i.id = $%d AND i.task_id = $%d RETURNING %s",
+		strings.Join(sets, ", "), len(args)-1, len(args), itemColumns,
+	)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return data.TaskItem{}, err
+	}
+	defer tx.Rollback()
+
+	if err := touchTask(ctx, tx, userID, taskID); err != nil {
+		return data.TaskItem{}, err
+	}
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := tx.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return data.TaskItem{}, err
+	}
+	defer stmt.Close()
+
+	var item data.TaskItem
+	if err = scanItem(stmt.QueryRowContext(ctx, args...), &item); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return data.TaskItem{}, tasks.ErrItemNotFound
+		}
+
+		return data.TaskItem{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return data.TaskItem{}, err
+	}
+
+	return item, nil
+}
+
+// Delete removes an item from the checklist of a given task.
+//
+// If the task does not exist or is not editable by the user returns tasks.ErrNotFound.
+// If the item does not exist returns tasks.ErrItemNotFound.
+func (s *ItemsStorage) Delete(ctx context.Context, userID, taskID, id string) error {
+	const query = "DELETE FROM task_items WHERE id = $1 AND task_id = $2"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := touchTask(ctx, tx, userID, taskID); err != nil {
+		return err
+	}
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := tx.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, id, taskID)
+	if err != nil {
+		return err
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count != 1 {
+		return tasks.ErrItemNotFound
+	}
+
+	return tx.Commit()
+}
+
+// Move places an item between its new neighbours in the checklist.
+//
+// If the task does not exist or is not editable by the user returns tasks.ErrNotFound.
+// If the item or one of the neighbours does not exist returns tasks.ErrItemNotFound.
+// If the neighbours are not in order or include the item itself returns tasks.ErrInvalidMove.
+func (s *ItemsStorage) Move(ctx context.Context, userID, taskID, id string, m data.TaskMove) (data.TaskItem, error) {
+	const query = "UPDATE task_items i SET position = $1, updated_on = CURRENT_TIMESTAMP WHERE i.id = $2 AND i.task_id = $3 RETURNING " + itemColumns
+
+	if id == m.AfterID || id == m.BeforeID || (m.AfterID == "" && m.BeforeID == "") {
+		return data.TaskItem{}, tasks.ErrInvalidMove
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return data.TaskItem{}, err
+	}
+	defer tx.Rollback()
+
+	if err := touchTask(ctx, tx, userID, taskID); err != nil {
+		return data.TaskItem{}, err
+	}
+
+	if _, err := itemPositions.lock(ctx, tx, taskID, id); err != nil {
+		return data.TaskItem{}, err
+	}
+
+	position, err := itemPositions.between(ctx, tx, taskID, id, m)
+	if err != nil {
+		return data.TaskItem{}, err
+	}
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := tx.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return data.TaskItem{}, err
+	}
+	defer stmt.Close()
+
+	var item data.TaskItem
+	if err = scanItem(stmt.QueryRowContext(ctx, position, id, taskID), &item); err != nil {
+		return data.TaskItem{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return data.TaskItem{}, err
+	}
+
+	return item, nil
+}
+
+// taskExists checks that an active task is visible to a given user.
+//
+// If the task does not exist returns tasks.ErrNotFound.
+func taskExists(ctx context.Context, db preparer, userID, taskID string) error {
+	query := "SELECT 1 FROM tasks WHERE id = $1 AND " + readableBy(2) + " AND is_deleted = false"
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := db.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	var exists int
+	if err = stmt.QueryRowContext(ctx, taskID, userID).Scan(&exists); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return tasks.ErrNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// touchTask bumps the version of a task editable by a given user and locks it
+// until the end of the transaction.
+//
+// If the task does not exist returns tasks.ErrNotFound.
+func touchTask(ctx context.Context, tx *sql.Tx, userID, taskID string) error {
+	query := "UPDATE tasks SET updated_on = CURRENT_TIMESTAMP, version = version + 1 WHERE id = $1 AND " + writableBy(2) + " AND is_deleted = false"
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := tx.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, taskID, userID)
+	if err != nil {
+		return err
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count != 1 {
+		return tasks.ErrNotFound
+	}
+
+	return nil
+}
+
+const itemColumns = "i.id, i.task_id, i.title, i.is_completed, i.position, i.created_on, i.updated_on"
+
+// scanItem scans a row selected with itemColumns into a given item.
+func scanItem(row scanner, i *data.TaskItem) error {
+	return row.Scan(&i.ID, &i.TaskID, &i.Title, &i.IsCompleted, &i.Position, &i.CreatedOn, &i.UpdatedOn)
+}
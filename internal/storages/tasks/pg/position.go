@@ -0,0 +1,195 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/storages"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
+)
+
+var errPositionsExhausted = errors.New("no position left between the neighbours")
+
+// positions manages fractional positions of rows in ordered lists.
+//
+// Each list is identified by its owner: a project for tasks of the project,
+// a user for the user's tasks out of projects and a task for task items.
+type positions struct {
+	// lockQuery selects and locks a position of a row by $1 id and $2 owner.
+	lockQuery string
+	// previousQuery selects and locks a position of the row right before $3 position and $4 id
+	// in the list of $1 owner, the moved row $2 is skipped.
+	previousQuery string
+	// nextQuery selects and locks a position of the row right after $3 position and $4 id
+	// in the list of $1 owner, the moved row $2 is skipped.
+	nextQuery string
+	// renumberQuery spreads positions of all rows of $1 owner to consecutive integers.
+	renumberQuery string
+	// notFound is returned when a row is not in the owner's list.
+	notFound error
+}
+
+var taskPositions = positions{
+	lockQuery:     "SELECT position FROM tasks WHERE id = $1 AND " + taskList(2) + " AND is_deleted = false FOR UPDATE",
+	previousQuery: "SELECT position FROM tasks WHERE " + taskList(1) + " AND is_deleted = false AND id <> $2 AND (position, id) < ($3, $4) ORDER BY position DESC, id DESC LIMIT 1 FOR UPDATE",
+	nextQuery:     "SELECT position FROM tasks WHERE " + taskList(1) + " AND is_deleted = false AND id <> $2 AND (position, id) > ($3, $4) ORDER BY position, id LIMIT 1 FOR UPDATE",
+	renumberQuery: "UPDATE tasks t SET position = r.rank FROM (SELECT id, ROW_NUMBER() OVER (ORDER BY position, id) AS rank FROM tasks WHERE " + taskList(1) + ") r WHERE t.id = r.id",
+	notFound:      tasks.ErrNotFound,
+}
+
+// taskList returns a condition matching tasks of the list owned by a given parameter:
+// the tasks of the project or the user's tasks out of projects.
+func taskList(param int) string {
+	return fmt.Sprintf("(tasks.project_id = $%d OR (tasks.project_id IS NULL AND tasks.user_id = $%d))", param, param)
+}
+
+var itemPositions = positions{
+	lockQuery:     "SELECT position FROM task_items WHERE id = $1 AND task_id = $2 FOR UPDATE",
+	previousQuery: "SELECT position FROM task_items WHERE task_id = $1 AND id <> $2 AND (position, id) < ($3, $4) ORDER BY position DESC, id DESC LIMIT 1 FOR UPDATE",
+	nextQuery:     "SELECT position FROM task_items WHERE task_id = $1 AND id <> $2 AND (position, id) > ($3, $4) ORDER BY position, id LIMIT 1 FOR UPDATE",
+	renumberQuery: "UPDATE task_items i SET position = r.rank FROM (SELECT id, ROW_NUMBER() OVER (ORDER BY position, id) AS rank FROM task_items WHERE task_id = $1) r WHERE i.id = r.id",
+	notFound:      tasks.ErrItemNotFound,
+}
+
+// between returns a position of a row with a given id between the neighbours described by a given move.
+//
+// The position is in the middle of the neighbours positions, so other rows are
+// not rewritten. A move giving only one neighbour is placed in the middle between
+// it and the row on its other side, the start or the end of the list is used only
+// when there is no such row. Only when the gap between the neighbours is exhausted
+// positions of the whole list are renumbered.
+// If the neighbours are not in order returns tasks.ErrInvalidMove.
+func (p positions) between(ctx context.Context, tx *sql.Tx, owner, id string, m data.TaskMove) (float64, error) {
+	position, err := p.middle(ctx, tx, owner, id, m)
+	if errors.Is(err, errPositionsExhausted) {
+		if err = p.renumber(ctx, tx, owner); err != nil {
+			return 0, err
+		}
+
+		position, err = p.middle(ctx, tx, owner, id, m)
+	}
+
+	return position, err
+}
+
+func (p positions) middle(ctx context.Context, tx *sql.Tx, owner, id string, m data.TaskMove) (float64, error) {
+	var after, before *float64
+
+	if m.AfterID != "" {
+		position, err := p.lock(ctx, tx, owner, m.AfterID)
+		if err != nil {
+			return 0, err
+		}
+		after = &position
+	}
+
+	if m.BeforeID != "" {
+		position, err := p.lock(ctx, tx, owner, m.BeforeID)
+		if err != nil {
+			return 0, err
+		}
+		before = &position
+	}
+
+	var err error
+	switch {
+	case after != nil && before == nil:
+		before, err = p.adjacent(ctx, tx, p.nextQuery, owner, id, m.AfterID, *after)
+	case after == nil && before != nil:
+		after, err = p.adjacent(ctx, tx, p.previousQuery, owner, id, m.BeforeID, *before)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return positionBetween(after, before)
+}
+
+// adjacent returns a position of the row next to a given neighbour selected by a given query
+// and locks it until the end of the transaction, nil means there is no such row.
+func (p positions) adjacent(ctx context.Context, tx *sql.Tx, query, owner, id, neighbourID string, neighbour float64) (*float64, error) {
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := tx.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	var position float64
+	if err = stmt.QueryRowContext(ctx, owner, id, neighbour, neighbourID).Scan(&position); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &position, nil
+}
+
+// positionBetween returns a position between given neighbours positions,
+// a nil neighbour means there is no row on that side, i.e. the start or the end of the list.
+//
+// If the neighbours are not in order returns tasks.ErrInvalidMove, if no float
+// fits between them, including neighbours tied at the same position, returns errPositionsExhausted.
+func positionBetween(after, before *float64) (float64, error) {
+	switch {
+	case after == nil && before == nil:
+		return 0, tasks.ErrInvalidMove
+	case after == nil:
+		return *before - 1, nil
+	case before == nil:
+		return *after + 1, nil
+	case *after > *before:
+		return 0, tasks.ErrInvalidMove
+	}
+
+	position := *after + (*before-*after)/2
+	if position <= *after || position >= *before {
+		return 0, errPositionsExhausted
+	}
+
+	return position, nil
+}
+
+// lock returns a position of a row and locks it until the end of the transaction.
+func (p positions) lock(ctx context.Context, tx *sql.Tx, owner, id string) (float64, error) {
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := tx.PrepareContext(prepareCtx, p.lockQuery)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	var position float64
+	if err = stmt.QueryRowContext(ctx, id, owner).Scan(&position); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, p.notFound
+		}
+
+		return 0, err
+	}
+
+	return position, nil
+}
+
+func (p positions) renumber(ctx context.Context, tx *sql.Tx, owner string) error {
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := tx.PrepareContext(prepareCtx, p.renumberQuery)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, owner)
+	return err
+}
@@ -0,0 +1,76 @@
+package pg
+
+import (
+	"testing"
+
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPositionBetween(t *testing.T) {
+	tests := []struct {
+		name    string
+		after   *float64
+		before  *float64
+		want    float64
+		wantErr error
+	}{
+		// a nil neighbour means no row on that side, not a neighbour left out of the move.
+		{name: "start of list", before: position(1), want: 0},
+		{name: "start of list before negative", before: position(-2.5), want: -3.5},
+		{name: "end of list", after: position(7), want: 8},
+		{name: "adjacent", after: position(1), before: position(2), want: 1.5},
+		{name: "adjacent fractions", after: position(1.5), before: position(1.75), want: 1.625},
+		{name: "tied neighbours", after: position(3), before: position(3), wantErr: errPositionsExhausted},
+		{name: "reversed neighbours", after: position(4), before: position(3), wantErr: tasks.ErrInvalidMove},
+		{name: "no neighbours", wantErr: tasks.ErrInvalidMove},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := positionBetween(tt.after, tt.before)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPositionBetweenExhaustsPrecision(t *testing.T) {
+	// moving a row again and again right after the first row halves the same gap.
+	after, before := 1.0, 2.0
+
+	moves := 0
+	for {
+		p, err := positionBetween(&after, &before)
+		if err != nil {
+			require.ErrorIs(t, err, errPositionsExhausted)
+			break
+		}
+
+		require.Greater(t, p, after)
+		require.Less(t, p, before)
+
+		before = p
+		moves++
+		require.Less(t, moves, 100, "the gap is never exhausted")
+	}
+
+	// a float64 has 52 bits of mantissa to split between 1 and 2.
+	assert.Equal(t, 52, moves)
+
+	// renumbering spreads the rows to consecutive integers, which makes room again.
+	after, before = 1, 2
+	p, err := positionBetween(&after, &before)
+	require.NoError(t, err)
+	assert.Equal(t, 1.5, p)
+}
+
+func position(v float64) *float64 {
+	return &v
+}
@@ -0,0 +1,99 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/pkg/recurrence"
+	"github.com/romankravchuk/eldorado/internal/storages"
+)
+
+// spawnNext creates the next occurrence of a recurring task completed by a write from prev to t
+// in the transaction of the write, so a completed task is never left without its successor.
+//
+// The recurrence passes to the next occurrence: it is cleared on the completed task and in t,
+// so reopening and completing the task again does not create one more occurrence.
+// The creation is recorded in the task history on behalf of a given actor.
+// If the write does not complete a recurring task or its rule has no next occurrence nothing is created.
+func spawnNext(ctx context.Context, tx *sql.Tx, actorID string, prev data.Task, t *data.Task) error {
+	if prev.IsCompleted || !t.IsCompleted || t.Recurrence == "" || t.DueAt == nil {
+		return nil
+	}
+
+	next, ok, err := nextOccurrence(*t, time.Now())
+	if err != nil || !ok {
+		return err
+	}
+
+	if err := save(ctx, tx, &next); err != nil {
+		return err
+	}
+
+	if err := clearRecurrence(ctx, tx, t.ID); err != nil {
+		return err
+	}
+	t.Recurrence, t.RecurrenceStart = "", nil
+
+	return recordEvent(ctx, tx, actorID, data.TaskEventCreated, nil, next)
+}
+
+// clearRecurrence clears the recurrence of a task with a given id.
+//
+// The task version is left as is, the recurrence is cleared by the write that completed the task.
+func clearRecurrence(ctx context.Context, tx *sql.Tx, id string) error {
+	const query = "UPDATE tasks SET recurrence = NULL, recurrence_start = NULL WHERE id = $1"
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := tx.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, id)
+	return err
+}
+
+// nextOccurrence returns the next occurrence of a recurring task after now.
+//
+// The occurrence is a copy of the task due at the next time of its rule,
+// the reminder keeps its offset from the due time. Checklist items and labels are not copied.
+// If the rule has no next occurrence returns false.
+func nextOccurrence(t data.Task, now time.Time) (data.Task, bool, error) {
+	var start time.Time
+	if t.RecurrenceStart != nil {
+		start = *t.RecurrenceStart
+	}
+
+	dueAt, err := recurrence.Next(t.Recurrence, start, *t.DueAt, now)
+	if errors.Is(err, recurrence.ErrNoNext) {
+		return data.Task{}, false, nil
+	}
+	if err != nil {
+		return data.Task{}, false, err
+	}
+
+	next := data.Task{
+		UserID:          t.UserID,
+		Title:           t.Title,
+		Description:     t.Description,
+		Priority:        t.Priority,
+		DueAt:           &dueAt,
+		ProjectID:       t.ProjectID,
+		AssigneeID:      t.AssigneeID,
+		Recurrence:      t.Recurrence,
+		RecurrenceStart: t.RecurrenceStart,
+	}
+
+	if t.RemindAt != nil {
+		remindAt := dueAt.Add(t.RemindAt.Sub(*t.DueAt))
+		next.RemindAt = &remindAt
+	}
+
+	return next, true, nil
+}
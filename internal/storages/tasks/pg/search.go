@@ -0,0 +1,107 @@
+package pg
+
+import (
+	"context"
+	"html"
+	"strings"
+	"unicode"
+
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/storages"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
+)
+
+// highlight markers are put around matched words by ts_headline
+// and replaced with <mark> elements after the text is HTML escaped.
+// The invisible separator makes them unlikely to appear in a task.
+const (
+	highlightStart = "\u2063["
+	highlightStop  = "]\u2063"
+
+	headlineOptions = "StartSel=" + highlightStart + ", StopSel=" + highlightStop + ", HighlightAll=true"
+	snippetOptions  = "StartSel=" + highlightStart + ", StopSel=" + highlightStop + ", MaxWords=20, MinWords=5, MaxFragments=2"
+)
+
+// Search returns active tasks visible to a given user matching a search query,
+// the most relevant first.
+//
+// Words of the title weigh more than words of the description.
+// If the query has no words returns tasks.ErrInvalidSearch.
+func (s *TasksStorage) Search(ctx context.Context, q data.TasksSearch) ([]data.TaskHit, error) {
+	tsquery := prefixQuery(q.Query)
+	if tsquery == "" {
+		return nil, tasks.ErrInvalidSearch
+	}
+
+	limit := q.Limit
+	if limit <= 0 || limit > data.SearchMaxLimit {
+		limit = data.SearchDefaultLimit
+	}
+
+	query := "SELECT " + taskColumns + ", ts_rank_cd(search, tsq) AS rank, " +
+		"ts_headline('simple', title, tsq, '" + headlineOptions + "'), " +
+		"ts_headline('simple', description, tsq, '" + snippetOptions + "') " +
+		"FROM tasks, to_tsquery('simple', $2) tsq WHERE search @@ tsq AND " + readableBy(1) + " AND is_deleted = false " +
+		"ORDER BY rank DESC, created_on DESC, id LIMIT $3"
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := s.db.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, q.UserID, tsquery, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var hits []data.TaskHit
+	for rows.Next() {
+		var hit data.TaskHit
+		if err = scanTask(rows, &hit.Task, &hit.Rank, &hit.Title, &hit.Snippet); err != nil {
+			break
+		}
+		hit.Title, hit.Snippet = markHighlights(hit.Title), markHighlights(hit.Snippet)
+		hits = append(hits, hit)
+	}
+
+	if closeErr := rows.Close(); closeErr != nil {
+		return nil, closeErr
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return hits, nil
+}
+
+// prefixQuery converts a user query to a tsquery matching words starting
+// with every word of the query, e.g. "weekly rep" becomes "weekly:* & rep:*".
+//
+// Only letters and digits are kept, so the result is always a valid tsquery.
+func prefixQuery(s string) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, w := range words {
+		words[i] = w + ":*"
+	}
+
+	return strings.Join(words, " & ")
+}
+
+// markHighlights escapes a headline and replaces highlight markers with <mark> elements.
+func markHighlights(s string) string {
+	s = html.EscapeString(s)
+	s = strings.ReplaceAll(s, highlightStart, "<mark>")
+	return strings.ReplaceAll(s, highlightStop, "</mark>")
+}
@@ -3,7 +3,14 @@ package pg
 import (
 	"context"
 	"database/sql"
-
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
 	"github.com/romankravchuk/eldorado/internal/data"
 	"github.com/romankravchuk/eldorado/internal/storages"
 	"github.com/romankravchuk/eldorado/internal/storages/tasks"
@@ -67,9 +74,9 @@ func (s *TasksStorage) UncompletedStatistic(ctx context.Context) ([]data.Statist
 	return tasks, nil
 }
 
-// FindByUserID returns a list of tasks for a given user.
-func (s *TasksStorage) FindByUserID(ctx context.Context, userID string) ([]data.Task, error) {
-	const query = "SELECT id, user_id, title, description, is_completed, created_on FROM tasks WHERE user_id = $1 AND is_deleted = false"
+// OverdueStatistic returns uncompleted tasks which due time has passed for each user.
+func (s *TasksStorage) OverdueStatistic(ctx context.Context) ([]data.StatisticTask, error) {
+	const query = "SELECT u.email, t.title, t.created_on, t.due_at FROM users u JOIN tasks t ON t.user_id = u.id WHERE t.is_completed = false AND t.is_deleted = false AND t.due_at < $1 ORDER BY u.email, t.due_at"
 
 	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
 	defer cancel()
@@ -80,18 +87,18 @@ func (s *TasksStorage) FindByUserID(ctx context.Context, userID string) ([]data.
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.QueryContext(ctx, userID)
+	rows, err := stmt.QueryContext(ctx, time.Now().UTC())
 	if err != nil {
 		return nil, err
 	}
 
-	var tasks []data.Task
+	var tasks []data.StatisticTask
 	for rows.Next() {
-		var task data.Task
-		if err = rows.Scan(&task.ID, &task.UserID, &task.Title, &task.Description, &task.IsCompleted, &task.CreatedOn); err != nil {
+		var st data.StatisticTask
+		if err = rows.Scan(&st.Email, &st.Title, &st.CreatedOn, &st.DueAt); err != nil {
 			break
 		}
-		tasks = append(tasks, task)
+		tasks = append(tasks, st)
 	}
 
 	if closeErr := rows.Close(); closeErr != nil {
@@ -102,98 +109,896 @@ func (s *TasksStorage) FindByUserID(ctx context.Context, userID string) ([]data.
 		return nil, err
 	}
 
-	if err := rows.Err(); err != nil {
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// AssignedStatistic returns uncompleted tasks for each assignee.
+//
+// Email of a statistic task is the email of the assignee, not of the task creator.
+func (s *TasksStorage) AssignedStatistic(ctx context.Context) ([]data.StatisticTask, error) {
+	const query = "SELECT u.email, t.title, t.created_on, t.due_at FROM users u JOIN tasks t ON t.assignee_id = u.id WHERE t.is_completed = false AND t.is_deleted = false ORDER BY u.email, t.due_at NULLS LAST, t.created_on"
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := s.db.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []data.StatisticTask
+	for rows.Next() {
+		var st data.StatisticTask
+		if err = rows.Scan(&st.Email, &st.Title, &st.CreatedOn, &st.DueAt); err != nil {
+			break
+		}
+		tasks = append(tasks, st)
+	}
+
+	if closeErr := rows.Close(); closeErr != nil {
+		return nil, closeErr
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = rows.Err(); err != nil {
 		return nil, err
 	}
 
 	return tasks, nil
 }
 
+// ProjectStatistic returns counts of total, completed and overdue tasks of every not archived project.
+func (s *TasksStorage) ProjectStatistic(ctx context.Context) ([]data.ProjectStatistic, error) {
+	const query = "SELECT u.email, p.name, COUNT(t.id), COUNT(t.id) FILTER (WHERE t.is_completed = true), COUNT(t.id) FILTER (WHERE t.is_completed = false AND t.due_at < $1) FROM users u JOIN projects p ON p.user_id = u.id LEFT JOIN tasks t ON t.project_id = p.id AND t.is_deleted = false WHERE p.is_archived = false GROUP BY u.email, p.id, p.name ORDER BY u.email, p.name"
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := s.db.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	var stats []data.ProjectStatistic
+	for rows.Next() {
+		var ps data.ProjectStatistic
+		if err = rows.Scan(&ps.Email, &ps.Project, &ps.Total, &ps.Completed, &ps.Overdue); err != nil {
+			break
+		}
+		stats = append(stats, ps)
+	}
+
+	if closeErr := rows.Close(); closeErr != nil {
+		return nil, closeErr
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// FindByUserID returns a page of tasks visible to a given user.
+//
+// If q.Deleted is set the page consists of the tasks in the trash editable by the user.
+// Unless q.ProjectID or q.Deleted is set tasks of archived projects are skipped.
+// Tasks are sorted by q.Sort column and id, the page continues after q.Cursor.
+// Label names in q.Labels must be unique, a task has to match all or any of them according to q.LabelsMatch.
+// If q.Cursor is malformed or was issued for another sort returns tasks.ErrInvalidCursor.
+func (s *TasksStorage) FindByUserID(ctx context.Context, q data.TasksQuery) (data.TasksPage, error) {
+	column, ok := sortColumns[q.Sort]
+	if !ok {
+		q.Sort, column = data.TasksSortCreatedOn, sortColumns[data.TasksSortCreatedOn]
+	}
+
+	direction, cmp := "ASC", ">"
+	if q.Order == data.OrderDesc {
+		direction, cmp = "DESC", "<"
+	}
+
+	limit := q.Limit
+	if limit <= 0 || limit > data.TasksMaxLimit {
+		limit = data.TasksDefaultLimit
+	}
+
+	conds := []string{readableBy(1), "is_deleted = false"}
+	if q.Deleted {
+		conds = []string{writableBy(1), "is_deleted = true"}
+	}
+	args := []any{q.UserID}
+
+	if q.Completed != nil {
+		args = append(args, *q.Completed)
+		conds = append(conds, fmt.Sprintf("is_completed = $%d", len(args)))
+	}
+
+	if q.DueBefore != nil {
+		args = append(args, q.DueBefore.UTC())
+		conds = append(conds, fmt.Sprintf("due_at < $%d", len(args)))
+	}
+
+	if q.Overdue != nil {
+		args = append(args, time.Now().UTC())
+		if *q.Overdue {
+			conds = append(conds, fmt.Sprintf("is_completed = false AND due_at < $%d", len(args)))
+		} else {
+			conds = append(conds, fmt.Sprintf("(is_completed = true OR due_at IS NULL OR due_at >= $%d)", len(args)))
+		}
+	}
+
+	if q.AssigneeID != "" {
+		args = append(args, q.AssigneeID)
+		conds = append(conds, fmt.Sprintf("assignee_id = $%d", len(args)))
+	}
+
+	if q.ProjectID != "" {
+		args = append(args, q.ProjectID)
+		conds = append(conds, fmt.Sprintf("project_id = $%d", len(args)))
+	} else if !q.Deleted {
+		conds = append(conds, "NOT EXISTS (SELECT 1 FROM projects p WHERE p.id = tasks.project_id AND p.is_archived = true)")
+	}
+
+	if len(q.Labels) > 0 {
+		args = append(args, pq.Array(q.Labels))
+		labeled := fmt.Sprintf("SELECT tl.task_id FROM task_labels tl JOIN labels l ON l.id = tl.label_id WHERE l.user_id = $1 AND l.name = ANY($%d)", len(args))
+		if q.LabelsMatch != data.LabelsMatchAny {
+			args = append(args, len(q.Labels))
+			labeled += fmt.Sprintf(" GROUP BY tl.task_id HAVING COUNT(*) = $%d", len(args))
+		}
+		conds = append(conds, fmt.Sprintf("id IN (%s)", labeled))
+	}
+
+	if q.Cursor != "" {
+		value, id, err := decodeCursor(q.Cursor, q.Sort)
+		if err != nil {
+			return data.TasksPage{}, err
+		}
+
+		args = append(args, value, id)
+		conds = append(conds, fmt.Sprintf("(%s, id) %s ($%d, $%d)", column, cmp, len(args)-1, len(args)))
+	}
+
+	args = append(args, limit+1)
+	query := fmt.Sprintf(
+		"SELECT %s FROM tasks WHERE %s ORDER BY %s %s, id %s LIMIT $%d",
+		taskColumns, strings.Join(conds, " AND "), column, direction, direction, len(args),
+	)
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := s.db.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return data.TasksPage{}, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, args...)
+	if err != nil {
+		return data.TasksPage{}, err
+	}
+
+	var tasks []data.Task
+	for rows.Next() {
+		var task data.Task
+		if err = scanTask(rows, &task); err != nil {
+			break
+		}
+		tasks = append(tasks, task)
+	}
+
+	if closeErr := rows.Close(); closeErr != nil {
+		return data.TasksPage{}, closeErr
+	}
+
+	if err != nil {
+		return data.TasksPage{}, err
+	}
+
+	if err := rows.Err(); err != nil {
+		return data.TasksPage{}, err
+	}
+
+	page := data.TasksPage{Tasks: tasks}
+	if len(tasks) > limit {
+		page.Tasks = tasks[:limit]
+		page.NextCursor = encodeCursor(page.Tasks[limit-1], q.Sort)
+	}
+
+	return page, nil
+}
+
+// FindByID returns a task by given id visible to a given user.
+//
+// If the task does not exist or is not visible to the user returns tasks.ErrNotFound.
+func (s *TasksStorage) FindByID(ctx context.Context, userID, id string) (data.Task, error) {
+	return findByID(ctx, s.db, userID, id)
+}
+
+// findByID selects a task visible to a given user on a db pool or in a transaction.
+func findByID(ctx context.Context, db preparer, userID, id string) (data.Task, error) {
+	query := "SELECT " + taskColumns + " FROM tasks WHERE id = $1 AND " + readableBy(2) + " AND is_deleted = false"
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := db.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return data.Task{}, err
+	}
+	defer stmt.Close()
+
+	var task data.Task
+	if err = scanTask(stmt.QueryRowContext(ctx, id, userID), &task); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return data.Task{}, tasks.ErrNotFound
+		}
+
+		return data.Task{}, err
+	}
+
+	return task, nil
+}
+
 // Save saves a tasks to the database.
 //
-// If save succeeds ID, IsCompleted and CreatedOn fields are filled.
+// The task is placed at the bottom of the user's list.
+// If save succeeds ID, IsCompleted, CreatedOn, UpdatedOn, Version and Position fields are filled.
+// If the reminder is later than the due time returns tasks.ErrInvalidReminder.
+// The creation is recorded in the task history.
 func (s *TasksStorage) Save(ctx context.Context, t *data.Task) error {
-	const query = "INSERT INTO tasks (user_id, title, description) VALUES ($1, $2, $3) RETURNING id, is_completed, created_on"
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		if err := save(ctx, tx, t); err != nil {
+			return err
+		}
+
+		return recordEvent(ctx, tx, t.UserID, data.TaskEventCreated, nil, *t)
+	})
+}
+
+// save inserts a task on a db pool or in a transaction.
+func save(ctx context.Context, db preparer, t *data.Task) error {
+	const query = "INSERT INTO tasks (user_id, title, description, due_at, remind_at, priority, project_id, assignee_id, recurrence, recurrence_start, position) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, (SELECT COALESCE(MAX(position), 0) + 1 FROM tasks WHERE project_id = $7 OR (project_id IS NULL AND $7 IS NULL AND user_id = $1))) RETURNING id, is_completed, created_on, updated_on, version, position"
 
 	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
 	defer cancel()
 
-	stmt, err := s.db.PrepareContext(prepareCtx, query)
+	stmt, err := db.PrepareContext(prepareCtx, query)
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
-	err = stmt.QueryRowContext(ctx, t.UserID, t.Title, t.Description).
-		Scan(&t.ID, &t.IsCompleted, &t.CreatedOn)
+	err = stmt.QueryRowContext(ctx, t.UserID, t.Title, t.Description, utc(t.DueAt), utc(t.RemindAt), t.Priority, t.ProjectID, t.AssigneeID, nullIfEmpty(t.Recurrence), utc(t.RecurrenceStart)).
+		Scan(&t.ID, &t.IsCompleted, &t.CreatedOn, &t.UpdatedOn, &t.Version, &t.Position)
 	if err != nil {
+		if isCheckViolation(err) {
+			return tasks.ErrInvalidReminder
+		}
+
 		return err
 	}
 
 	return nil
 }
 
-// Delete deletes a task from the database.
+// Delete deletes a task editable by a given user from the database.
 //
-// Actually set is_delete = true.
+// Actually set is_delete = true and moves the task to the trash, see Restore and Purge.
+// If version is not 0 and does not match the task version returns tasks.ErrVersionConflict.
 // If count of affected rows is not 1 returns tasks.ErrNotFound.
-func (s *TasksStorage) Delete(ctx context.Context, id string) error {
-	const query = "UPDATE tasks SET is_deleted = true WHERE id = $1"
+// The deletion is recorded in the task history.
+func (s *TasksStorage) Delete(ctx context.Context, userID, id string, version int) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		deleted, err := softDelete(ctx, tx, userID, id, version)
+		if err != nil {
+			return err
+		}
+
+		if !deleted {
+			return s.notFoundOrConflict(ctx, userID, id, version)
+		}
+
+		return recordEvent(ctx, tx, userID, data.TaskEventDeleted, nil, data.Task{ID: id})
+	})
+}
+
+// softDelete moves a task to the trash and reports whether the task was matched.
+func softDelete(ctx context.Context, db preparer, userID, id string, version int) (bool, error) {
+	query := "UPDATE tasks SET is_deleted = true, deleted_on = CURRENT_TIMESTAMP, version = version + 1 WHERE id = $1 AND " + writableBy(2) + " AND is_deleted = false AND ($3 = 0 OR version = $3)"
 
 	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
 	defer cancel()
 
-	stmt, err := s.db.PrepareContext(prepareCtx, query)
+	stmt, err := db.PrepareContext(prepareCtx, query)
 	if err != nil {
-		return err
+		return false, err
 	}
 	defer stmt.Close()
 
-	res, err := stmt.ExecContext(ctx, id)
+	res, err := stmt.ExecContext(ctx, id, userID, version)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	count, err := res.RowsAffected()
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	if count != 1 {
-		return tasks.ErrNotFound
+	return count == 1, nil
+}
+
+// Restore moves a task editable by a given user out of the trash.
+//
+// If restore succeeds returns the restored task.
+// If the task is not in the trash or is not editable by the user returns tasks.ErrNotFound.
+func (s *TasksStorage) Restore(ctx context.Context, userID, id string) (data.Task, error) {
+	query := "UPDATE tasks SET is_deleted = false, deleted_on = NULL, updated_on = CURRENT_TIMESTAMP, version = version + 1 WHERE id = $1 AND " + writableBy(2) + " AND is_deleted = true RETURNING " + taskColumns
+
+	var task data.Task
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+		defer cancel()
+
+		stmt, err := tx.PrepareContext(prepareCtx, query)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		if err = scanTask(stmt.QueryRowContext(ctx, id, userID), &task); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return tasks.ErrNotFound
+			}
+
+			return err
+		}
+
+		return recordEvent(ctx, tx, userID, data.TaskEventRestored, nil, task)
+	})
+	if err != nil {
+		return data.Task{}, err
 	}
 
-	return nil
+	return task, nil
 }
 
-// Update updates a task in the database.
+// Purge permanently deletes a task editable by a given user, either active or in the trash.
 //
-// If count of affected rows is not 1 returns tasks.ErrNotFound.
-func (s *TasksStorage) Update(ctx context.Context, t *data.Task) error {
-	query := "UPDATE tasks SET title = $1, description = $2, is_completed = $3 WHERE id = $4"
+// Items and labels of the task are deleted with it, its history is kept.
+// If purge succeeds returns the ID, UserID, ProjectID and AssigneeID fields of the purged task.
+// If version is not 0 and does not match the task version returns tasks.ErrVersionConflict.
+// If the task does not exist or is not editable by the user returns tasks.ErrNotFound.
+func (s *TasksStorage) Purge(ctx context.Context, userID, id string, version int) (data.Task, error) {
+	query := "DELETE FROM tasks WHERE id = $1 AND " + writableBy(2) + " AND ($3 = 0 OR version = $3) RETURNING id, user_id, project_id, assignee_id"
 
 	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
 	defer cancel()
 
 	stmt, err := s.db.PrepareContext(prepareCtx, query)
 	if err != nil {
-		return err
+		return data.Task{}, err
+	}
+	defer stmt.Close()
+
+	var task data.Task
+	if err = stmt.QueryRowContext(ctx, id, userID, version).Scan(&task.ID, &task.UserID, &task.ProjectID, &task.AssigneeID); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return data.Task{}, err
+		}
+
+		if version == 0 {
+			return data.Task{}, tasks.ErrNotFound
+		}
+
+		exists, err := s.exists(ctx, userID, id)
+		if err != nil {
+			return data.Task{}, err
+		}
+		if !exists {
+			return data.Task{}, tasks.ErrNotFound
+		}
+
+		return data.Task{}, tasks.ErrVersionConflict
+	}
+
+	return task, nil
+}
+
+// PurgeDeleted permanently deletes all tasks moved to the trash before a given time.
+//
+// Returns the number of purged tasks.
+func (s *TasksStorage) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	const query = "DELETE FROM tasks WHERE is_deleted = true AND deleted_on < $1"
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := s.db.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// Update updates a task editable by t.UserID in the database.
+//
+// If t.Version is not 0 the task is updated only when its version matches,
+// otherwise returns tasks.ErrVersionConflict.
+// An empty t.Priority leaves the priority unchanged, an empty t.Recurrence clears the recurrence.
+// If update succeeds t is replaced with the stored task, whose UserID is the task creator.
+// If the reminder is later than the due time returns tasks.ErrInvalidReminder.
+// If the task does not exist or is not editable by the user returns tasks.ErrNotFound.
+// The changed fields are recorded in the task history.
+// Completing a recurring task creates its next occurrence in the same transaction.
+func (s *TasksStorage) Update(ctx context.Context, t *data.Task) error {
+	query := "UPDATE tasks SET title = $1, description = $2, is_completed = $3, due_at = $7, remind_at = $8, priority = COALESCE($9, priority), recurrence = $10, recurrence_start = $11, updated_on = CURRENT_TIMESTAMP, version = version + 1 WHERE id = $4 AND " + writableBy(5) + " AND is_deleted = false AND ($6 = 0 OR version = $6) RETURNING " + taskColumns
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		prev, err := lockTask(ctx, tx, t.UserID, t.ID)
+		if err != nil {
+			return err
+		}
+
+		prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+		defer cancel()
+
+		stmt, err := tx.PrepareContext(prepareCtx, query)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		var next data.Task
+		row := stmt.QueryRowContext(ctx, t.Title, t.Description, t.IsCompleted, t.ID, t.UserID, t.Version, utc(t.DueAt), utc(t.RemindAt), nullIfEmpty(t.Priority), nullIfEmpty(t.Recurrence), utc(t.RecurrenceStart))
+		if err = scanTask(row, &next); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return s.notFoundOrConflict(ctx, t.UserID, t.ID, t.Version)
+			}
+
+			if isCheckViolation(err) {
+				return tasks.ErrInvalidReminder
+			}
+
+			return err
+		}
+
+		userID := t.UserID
+		*t = next
+
+		if err := recordEvent(ctx, tx, userID, data.TaskEventUpdated, &prev, next); err != nil {
+			return err
+		}
+
+		return spawnNext(ctx, tx, userID, prev, t)
+	})
+}
+
+// Patch updates only the fields of a task set in a given patch.
+//
+// If patch succeeds returns the updated task.
+// If p.Version is not 0 and does not match the task version returns tasks.ErrVersionConflict.
+// If the reminder becomes later than the due time returns tasks.ErrInvalidReminder.
+// If the task does not exist or is not editable by the user returns tasks.ErrNotFound,
+// the assignee of a task may only complete or reopen it, see data.TaskPatch.IsCompletion.
+// The changed fields are recorded in the task history.
+// Completing a recurring task creates its next occurrence in the same transaction.
+func (s *TasksStorage) Patch(ctx context.Context, userID, id string, p data.TaskPatch) (data.Task, error) {
+	var task data.Task
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		prev, err := lockTask(ctx, tx, userID, id)
+		if err != nil {
+			return err
+		}
+
+		task, err = patch(ctx, tx, userID, id, p)
+		if errors.Is(err, sql.ErrNoRows) {
+			return s.notFoundOrConflict(ctx, userID, id, p.Version)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := recordEvent(ctx, tx, userID, data.TaskEventUpdated, &prev, task); err != nil {
+			return err
+		}
+
+		return spawnNext(ctx, tx, userID, prev, &task)
+	})
+	if err != nil {
+		return data.Task{}, err
+	}
+
+	return task, nil
+}
+
+// patch applies a given patch to a task.
+//
+// If the task is not matched returns sql.ErrNoRows.
+func patch(ctx context.Context, db preparer, userID, id string, p data.TaskPatch) (data.Task, error) {
+	var (
+		sets []string
+		args []any
+	)
+
+	if p.Title != nil {
+		args = append(args, *p.Title)
+		sets = append(sets, fmt.Sprintf("title = $%d", len(args)))
+	}
+	if p.Description != nil {
+		args = append(args, *p.Description)
+		sets = append(sets, fmt.Sprintf("description = $%d", len(args)))
+	}
+	if p.IsCompleted != nil {
+		args = append(args, *p.IsCompleted)
+		sets = append(sets, fmt.Sprintf("is_completed = $%d", len(args)))
+	}
+	if p.Priority != nil {
+		args = append(args, *p.Priority)
+		sets = append(sets, fmt.Sprintf("priority = $%d", len(args)))
+	}
+	if p.DueAt != nil {
+		args = append(args, p.DueAt.UTC())
+		sets = append(sets, fmt.Sprintf("due_at = $%d", len(args)))
+	} else if p.ClearDueAt {
+		sets = append(sets, "due_at = NULL")
+	}
+	if p.RemindAt != nil {
+		args = append(args, p.RemindAt.UTC())
+		sets = append(sets, fmt.Sprintf("remind_at = $%d", len(args)))
+	} else if p.ClearRemindAt {
+		sets = append(sets, "remind_at = NULL")
+	}
+	// a task moved to another list is placed at its end.
+	if p.ProjectID != nil {
+		args = append(args, *p.ProjectID)
+		sets = append(sets,
+			fmt.Sprintf("project_id = $%d", len(args)),
+			fmt.Sprintf("position = CASE WHEN tasks.project_id IS DISTINCT FROM $%d THEN (SELECT COALESCE(MAX(l.position), 0) + 1 FROM tasks l WHERE l.project_id = $%d) ELSE tasks.position END", len(args), len(args)),
+		)
+	} else if p.ClearProjectID {
+		sets = append(sets,
+			"project_id = NULL",
+			"position = CASE WHEN tasks.project_id IS NOT NULL THEN (SELECT COALESCE(MAX(l.position), 0) + 1 FROM tasks l WHERE l.project_id IS NULL AND l.user_id = tasks.user_id) ELSE tasks.position END",
+		)
+	}
+	if p.AssigneeID != nil {
+		args = append(args, *p.AssigneeID)
+		sets = append(sets, fmt.Sprintf("assignee_id = $%d", len(args)))
+	} else if p.ClearAssigneeID {
+		sets = append(sets, "assignee_id = NULL")
+	}
+	if p.Recurrence != nil {
+		args = append(args, *p.Recurrence)
+		sets = append(sets, fmt.Sprintf("recurrence = $%d", len(args)))
+	} else if p.ClearRecurrence {
+		sets = append(sets, "recurrence = NULL", "recurrence_start = NULL")
+	}
+	if p.RecurrenceStart != nil && !p.ClearRecurrence {
+		args = append(args, p.RecurrenceStart.UTC())
+		sets = append(sets, fmt.Sprintf("recurrence_start = $%d", len(args)))
+	}
+	sets = append(sets, "updated_on = CURRENT_TIMESTAMP", "version = version + 1")
+
+	// assignees may only complete the tasks assigned to them.
+	access := writableBy
+	if p.IsCompletion() {
+		access = completableBy
+	}
+
+	args = append(args, id, userID, p.Version)
+	query := fmt.Sprintf(
+		"UPDATE tasks SET %s WHERE id = $%d AND %s AND is_deleted = false AND ($%d = 0 OR version = $%d) RETURNING %s",
+		strings.Join(sets, ", "), len(args)-2, access(len(args)-1), len(args), len(args), taskColumns,
+	)
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := db.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return data.Task{}, err
 	}
 	defer stmt.Close()
 
-	res, err := stmt.ExecContext(ctx, t.Title, t.Description, t.IsCompleted, t.ID)
+	var task data.Task
+	if err = scanTask(stmt.QueryRowContext(ctx, args...), &task); err != nil {
+		if isCheckViolation(err) {
+			return data.Task{}, tasks.ErrInvalidReminder
+		}
+
+		return data.Task{}, err
+	}
+
+	return task, nil
+}
+
+// Move places a task between its new neighbours in the list of the task's project,
+// or in the list of the task creator for a task out of projects.
+//
+// The task gets a position in the middle of the neighbours positions, so other
+// tasks are not rewritten. Only when the gap between the neighbours is exhausted
+// positions of all tasks of the list are renumbered.
+// If the task is not editable by the user or one of the neighbours is not in the same list
+// returns tasks.ErrNotFound.
+// If the neighbours are not in order or include the task itself returns tasks.ErrInvalidMove.
+func (s *TasksStorage) Move(ctx context.Context, userID, id string, m data.TaskMove) (data.Task, error) {
+	if id == m.AfterID || id == m.BeforeID || (m.AfterID == "" && m.BeforeID == "") {
+		return data.Task{}, tasks.ErrInvalidMove
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
+		return data.Task{}, err
+	}
+	defer tx.Rollback()
+
+	list, err := lockTaskList(ctx, tx, userID, id)
+	if err != nil {
+		return data.Task{}, err
+	}
+
+	position, err := taskPositions.between(ctx, tx, list, id, m)
+	if err != nil {
+		return data.Task{}, err
+	}
+
+	const query = "UPDATE tasks SET position = $1, updated_on = CURRENT_TIMESTAMP, version = version + 1 WHERE id = $2 RETURNING " + taskColumns
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := tx.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return data.Task{}, err
+	}
+	defer stmt.Close()
+
+	var task data.Task
+	if err = scanTask(stmt.QueryRowContext(ctx, position, id), &task); err != nil {
+		return data.Task{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return data.Task{}, err
+	}
+
+	return task, nil
+}
+
+// lockTaskList locks a task editable by a given user until the end of the transaction
+// and returns the owner of its list: the task's project or, out of projects, the task creator.
+// If the task does not exist or is not editable by the user returns tasks.ErrNotFound.
+func lockTaskList(ctx context.Context, tx *sql.Tx, userID, id string) (string, error) {
+	query := "SELECT COALESCE(project_id, user_id) FROM tasks WHERE id = $1 AND " + writableBy(2) + " AND is_deleted = false FOR UPDATE"
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := tx.PrepareContext(prepareCtx, query)
+	if err != nil {
+		return "", err
+	}
+	defer stmt.Close()
+
+	var list string
+	if err = stmt.QueryRowContext(ctx, id, userID).Scan(&list); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", tasks.ErrNotFound
+		}
+
+		return "", err
+	}
+
+	return list, nil
+}
+
+// notFoundOrConflict explains why a conditional write matched no rows.
+//
+// If the task exists its version did not match and tasks.ErrVersionConflict is returned,
+// otherwise tasks.ErrNotFound.
+func (s *TasksStorage) notFoundOrConflict(ctx context.Context, userID, id string, version int) error {
+	if version == 0 {
+		return tasks.ErrNotFound
+	}
+
+	if _, err := s.FindByID(ctx, userID, id); err != nil {
 		return err
 	}
 
-	count, err := res.RowsAffected()
+	return tasks.ErrVersionConflict
+}
+
+// exists reports whether a task editable by a given user exists, either active or in the trash.
+func (s *TasksStorage) exists(ctx context.Context, userID, id string) (bool, error) {
+	query := "SELECT EXISTS (SELECT 1 FROM tasks WHERE id = $1 AND " + writableBy(2) + ")"
+
+	prepareCtx, cancel := context.WithTimeout(ctx, storages.PrepareTimeout)
+	defer cancel()
+
+	stmt, err := s.db.PrepareContext(prepareCtx, query)
 	if err != nil {
+		return false, err
+	}
+	defer stmt.Close()
+
+	var exists bool
+	if err = stmt.QueryRowContext(ctx, id, userID).Scan(&exists); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+const taskColumns = "id, user_id, title, description, is_completed, created_on, updated_on, deleted_on, version, due_at, remind_at, priority, position, project_id, assignee_id, COALESCE(recurrence, ''), recurrence_start, " +
+	"(SELECT COUNT(*) FROM task_items i WHERE i.task_id = tasks.id), " +
+	"(SELECT COUNT(*) FROM task_items i WHERE i.task_id = tasks.id AND i.is_completed = true), " +
+	"(SELECT COALESCE(json_agg(json_build_object('id', l.id, 'user_id', l.user_id, 'name', l.name, 'color', l.color) ORDER BY l.name), '[]') FROM task_labels tl JOIN labels l ON l.id = tl.label_id WHERE tl.task_id = tasks.id)"
+
+// readableBy returns a condition matching tasks visible to the user in a given parameter:
+// the user's tasks out of projects, all tasks of the projects the user is a member of
+// and the tasks assigned to the user.
+func readableBy(param int) string {
+	return fmt.Sprintf("((tasks.project_id IS NULL AND tasks.user_id = $%d) OR tasks.assignee_id = $%d OR tasks.project_id IN (SELECT m.project_id FROM project_members m WHERE m.user_id = $%d))", param, param, param)
+}
+
+// writableBy returns a condition matching tasks editable by the user in a given parameter:
+// the user's tasks out of projects and all tasks of the projects the user owns or edits.
+func writableBy(param int) string {
+	return fmt.Sprintf("((tasks.project_id IS NULL AND tasks.user_id = $%d) OR tasks.project_id IN (SELECT m.project_id FROM project_members m WHERE m.user_id = $%d AND m.role <> 'viewer'))", param, param)
+}
+
+// completableBy returns a condition matching tasks the user in a given parameter may
+// complete or reopen, it is writableBy and the tasks assigned to the user.
+func completableBy(param int) string {
+	return fmt.Sprintf("(%s OR tasks.assignee_id = $%d)", writableBy(param), param)
+}
+
+// preparer prepares statements on a db pool or in a transaction.
+type preparer interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanTask scans a row selected with taskColumns into a given task.
+//
+// Columns selected after taskColumns are scanned into extra.
+func scanTask(row scanner, t *data.Task, extra ...any) error {
+	var labels []byte
+	dest := append([]any{&t.ID, &t.UserID, &t.Title, &t.Description, &t.IsCompleted, &t.CreatedOn, &t.UpdatedOn, &t.DeletedOn, &t.Version, &t.DueAt, &t.RemindAt, &t.Priority, &t.Position, &t.ProjectID, &t.AssigneeID, &t.Recurrence, &t.RecurrenceStart, &t.ItemsCount, &t.ItemsCompleted, &labels}, extra...)
+	if err := row.Scan(dest...); err != nil {
 		return err
 	}
 
-	if count != 1 {
-		return tasks.ErrNotFound
+	return json.Unmarshal(labels, &t.Labels)
+}
+
+// utc returns a given time in UTC, because timestamp columns are stored without time zone.
+func utc(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
 	}
 
-	return nil
+	u := t.UTC()
+	return &u
+}
+
+func nullIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func isCheckViolation(err error) bool {
+	psqlErr, ok := err.(*pq.Error)
+	return ok && psqlErr.Code == storages.CheckViolationCode
+}
+
+var sortColumns = map[string]string{
+	data.TasksSortCreatedOn: "created_on",
+	data.TasksSortTitle:     "title",
+	data.TasksSortPriority:  "priority",
+	data.TasksSortPosition:  "position",
+	data.TasksSortDeletedOn: "deleted_on",
+}
+
+func encodeCursor(t data.Task, sort string) string {
+	c := tasks.Cursor{Sort: sort, ID: t.ID}
+
+	switch sort {
+	case data.TasksSortTitle:
+		c.Value = t.Title
+	case data.TasksSortPriority:
+		c.Value = t.Priority
+	case data.TasksSortPosition:
+		c.Value = strconv.FormatFloat(t.Position, 'g', -1, 64)
+	case data.TasksSortDeletedOn:
+		if t.DeletedOn != nil {
+			c.Value = t.DeletedOn.Format(time.RFC3339Nano)
+		}
+	default:
+		c.Value = t.CreatedOn.Format(time.RFC3339Nano)
+	}
+
+	return tasks.EncodeCursor(c)
+}
+
+func decodeCursor(s, sort string) (any, string, error) {
+	c, err := tasks.DecodeCursor(s)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if c.Sort != sort {
+		return nil, "", tasks.ErrInvalidCursor
+	}
+
+	switch sort {
+	case data.TasksSortTitle:
+		return c.Value, c.ID, nil
+	case data.TasksSortPriority:
+		switch c.Value {
+		case data.PriorityLow, data.PriorityNormal, data.PriorityHigh, data.PriorityUrgent:
+			return c.Value, c.ID, nil
+		default:
+			return nil, "", tasks.ErrInvalidCursor
+		}
+	case data.TasksSortPosition:
+		position, err := strconv.ParseFloat(c.Value, 64)
+		if err != nil {
+			return nil, "", tasks.ErrInvalidCursor
+		}
+
+		return position, c.ID, nil
+	case data.TasksSortDeletedOn:
+		deletedOn, err := time.Parse(time.RFC3339Nano, c.Value)
+		if err != nil {
+			return nil, "", tasks.ErrInvalidCursor
+		}
+
+		return deletedOn, c.ID, nil
+	default:
+		createdOn, err := time.Parse(time.RFC3339Nano, c.Value)
+		if err != nil {
+			return nil, "", tasks.ErrInvalidCursor
+		}
+
+		return createdOn, c.ID, nil
+	}
 }
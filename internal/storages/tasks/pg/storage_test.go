@@ -0,0 +1,206 @@
+package pg_test
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/romankravchuk/eldorado/internal/data"
+	"github.com/romankravchuk/eldorado/internal/pkg/recurrence"
+	"github.com/romankravchuk/eldorado/internal/storages"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks"
+	"github.com/romankravchuk/eldorado/internal/storages/tasks/pg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newStorage returns a storage of the database in the PG_URL variable used by the api,
+// e.g. the postgres service of docker-compose.yaml migrated with db/migrations.
+// The test is skipped when PG_URL is not set.
+func newStorage(t *testing.T) (*pg.TasksStorage, *sql.DB) {
+	t.Helper()
+
+	url := os.Getenv("PG_URL")
+	if url == "" {
+		t.Skip("PG_URL is not set")
+	}
+
+	db, err := storages.NewDBPool("postgres", url)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	storage, err := pg.New(db)
+	require.NoError(t, err)
+
+	return storage, db
+}
+
+// newUser inserts a user which is removed with all of its tasks when the test ends.
+func newUser(t *testing.T, db *sql.DB) string {
+	t.Helper()
+
+	name := "test" + strconv.FormatInt(time.Now().UnixNano(), 10)
+
+	var id string
+	err := db.QueryRow(
+		"INSERT INTO users (email, username, name, encrypted_password) VALUES ($1, $2, $2, 'x') RETURNING id",
+		name+"@example.com", name,
+	).Scan(&id)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_, _ = db.Exec("DELETE FROM task_events WHERE task_id IN (SELECT id FROM tasks WHERE user_id = $1)", id)
+		_, _ = db.Exec("DELETE FROM tasks WHERE user_id = $1", id)
+		_, _ = db.Exec("DELETE FROM users WHERE id = $1", id)
+	})
+
+	return id
+}
+
+// newTasks saves tasks of a given user with given titles, in order of their positions.
+func newTasks(t *testing.T, storage *pg.TasksStorage, userID string, titles ...string) []data.Task {
+	t.Helper()
+
+	saved := make([]data.Task, len(titles))
+	for i, title := range titles {
+		saved[i] = data.Task{
+			UserID:      userID,
+			Title:       title,
+			Description: title,
+			Priority:    data.PriorityNormal,
+		}
+		require.NoError(t, storage.Save(context.Background(), &saved[i]))
+	}
+
+	return saved
+}
+
+// titlesByPosition returns the titles of the user's tasks sorted by position,
+// listed in pages of a given size.
+func titlesByPosition(t *testing.T, storage *pg.TasksStorage, userID string, limit int) []string {
+	t.Helper()
+
+	var titles []string
+	q := data.TasksQuery{UserID: userID, Limit: limit, Sort: data.TasksSortPosition}
+	for {
+		page, err := storage.FindByUserID(context.Background(), q)
+		require.NoError(t, err)
+
+		for _, task := range page.Tasks {
+			titles = append(titles, task.Title)
+		}
+
+		if page.NextCursor == "" {
+			return titles
+		}
+		q.Cursor = page.NextCursor
+	}
+}
+
+func TestMove(t *testing.T) {
+	storage, db := newStorage(t)
+	userID := newUser(t, db)
+	ctx := context.Background()
+
+	saved := newTasks(t, storage, userID, "a", "b", "c", "d")
+	a, b, c, d := saved[0], saved[1], saved[2], saved[3]
+
+	moved, err := storage.Move(ctx, userID, d.ID, data.TaskMove{AfterID: a.ID})
+	require.NoError(t, err)
+	assert.Equal(t, (a.Position+b.Position)/2, moved.Position, "a one-sided move lands next to the neighbour")
+	assert.Equal(t, d.Version+1, moved.Version)
+
+	moved, err = storage.Move(ctx, userID, a.ID, data.TaskMove{AfterID: b.ID, BeforeID: c.ID})
+	require.NoError(t, err)
+	assert.Equal(t, (b.Position+c.Position)/2, moved.Position)
+
+	_, err = storage.Move(ctx, userID, c.ID, data.TaskMove{BeforeID: d.ID})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"c", "d", "b", "a"}, titlesByPosition(t, storage, userID, 2))
+
+	_, err = storage.Move(ctx, userID, a.ID, data.TaskMove{AfterID: b.ID, BeforeID: c.ID})
+	assert.ErrorIs(t, err, tasks.ErrInvalidMove, "the neighbours are not in order")
+
+	other := newTasks(t, storage, newUser(t, db), "e")[0]
+
+	_, err = storage.Move(ctx, userID, a.ID, data.TaskMove{AfterID: other.ID})
+	assert.ErrorIs(t, err, tasks.ErrNotFound, "the neighbour is in another list")
+}
+
+func TestMoveRenumbersExhaustedGap(t *testing.T) {
+	storage, db := newStorage(t)
+	userID := newUser(t, db)
+	ctx := context.Background()
+
+	saved := newTasks(t, storage, userID, "a", "b", "c")
+	a, b, c := saved[0], saved[1], saved[2]
+
+	// no float fits between b and c.
+	_, err := db.Exec("UPDATE tasks SET position = $1 WHERE id = $2", math.Nextafter(b.Position, math.Inf(1)), c.ID)
+	require.NoError(t, err)
+
+	moved, err := storage.Move(ctx, userID, a.ID, data.TaskMove{AfterID: b.ID, BeforeID: c.ID})
+	require.NoError(t, err)
+
+	// the list is renumbered to 1, 2, 3 before a is placed between b and c.
+	assert.Equal(t, 2.5, moved.Position)
+	assert.Equal(t, []string{"b", "a", "c"}, titlesByPosition(t, storage, userID, 10))
+
+	var positions []float64
+	rows, err := db.Query("SELECT position FROM tasks WHERE user_id = $1 ORDER BY position", userID)
+	require.NoError(t, err)
+	defer rows.Close()
+	for rows.Next() {
+		var p float64
+		require.NoError(t, rows.Scan(&p))
+		positions = append(positions, p)
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []float64{2, 2.5, 3}, positions)
+}
+
+func TestPatchRecurringTaskSpawnsOnce(t *testing.T) {
+	storage, db := newStorage(t)
+	userID := newUser(t, db)
+	ctx := context.Background()
+
+	dueAt := time.Now().UTC().Add(time.Hour).Truncate(time.Second)
+	task := data.Task{
+		UserID:      userID,
+		Title:       "Water plants",
+		Description: "Water plants",
+		Priority:    data.PriorityNormal,
+		DueAt:       &dueAt,
+		Recurrence:  recurrence.Daily,
+	}
+	require.NoError(t, storage.Save(ctx, &task))
+
+	completed, err := storage.Patch(ctx, userID, task.ID, data.TaskPatch{IsCompleted: ptr(true)})
+	require.NoError(t, err)
+	assert.Empty(t, completed.Recurrence, "the recurrence passes to the next occurrence")
+	assert.Nil(t, completed.RecurrenceStart)
+
+	_, err = storage.Patch(ctx, userID, task.ID, data.TaskPatch{IsCompleted: ptr(false)})
+	require.NoError(t, err)
+
+	_, err = storage.Patch(ctx, userID, task.ID, data.TaskPatch{IsCompleted: ptr(true)})
+	require.NoError(t, err)
+
+	var count, recurring int
+	err = db.QueryRow(
+		"SELECT COUNT(*), COUNT(*) FILTER (WHERE recurrence IS NOT NULL) FROM tasks WHERE user_id = $1",
+		userID,
+	).Scan(&count, &recurring)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count, "reopening and completing the task again spawns nothing")
+	assert.Equal(t, 1, recurring)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
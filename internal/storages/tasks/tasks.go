@@ -3,17 +3,56 @@ package tasks
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/romankravchuk/eldorado/internal/data"
 )
 
-var ErrNotFound = errors.New("the task not found")
+var (
+	ErrNotFound  = errors.New("the task not found")
+	ErrForbidden = errors.New("the task is not accessible for the user")
+
+	ErrVersionConflict   = errors.New("the task version does not match")
+	ErrInvalidReminder   = errors.New("the task reminder must not be later than its due time")
+	ErrInvalidMove       = errors.New("the task cannot be placed between given neighbours")
+	ErrInvalidAssignee   = errors.New("the assignee is not a member of the task project")
+	ErrInvalidRecurrence = errors.New("the task recurrence rule is invalid or the task has no due time")
+	ErrInvalidSearch     = errors.New("the search query has no words")
+)
+
+// OpError is an error of a failed operation which rolled back the whole batch.
+type OpError struct {
+	Index int
+	Err   error
+}
+
+func (e *OpError) Error() string {
+	return fmt.Sprintf("operation %d: %s", e.Index, e.Err)
+}
+
+func (e *OpError) Unwrap() error {
+	return e.Err
+}
 
 //go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name Storage
 type Storage interface {
-	FindByUserID(ctx context.Context, userID string) ([]data.Task, error)
+	FindByUserID(ctx context.Context, q data.TasksQuery) (data.TasksPage, error)
+	FindByID(ctx context.Context, userID, id string) (data.Task, error)
+	Search(ctx context.Context, q data.TasksSearch) ([]data.TaskHit, error)
 	UncompletedStatistic(ctx context.Context) ([]data.StatisticTask, error)
+	OverdueStatistic(ctx context.Context) ([]data.StatisticTask, error)
+	ProjectStatistic(ctx context.Context) ([]data.ProjectStatistic, error)
+	AssignedStatistic(ctx context.Context) ([]data.StatisticTask, error)
 	Save(ctx context.Context, task *data.Task) error
-	Delete(ctx context.Context, id string) error
+	Delete(ctx context.Context, userID, id string, version int) error
+	Restore(ctx context.Context, userID, id string) (data.Task, error)
+	Purge(ctx context.Context, userID, id string, version int) (data.Task, error)
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
 	Update(ctx context.Context, task *data.Task) error
+	Patch(ctx context.Context, userID, id string, p data.TaskPatch) (data.Task, error)
+	Move(ctx context.Context, userID, id string, m data.TaskMove) (data.Task, error)
+	Batch(ctx context.Context, userID string, ops []data.TaskOp, atomic bool) ([]data.TaskOpResult, error)
+	Import(ctx context.Context, userID string, rows []data.TaskImport, dryRun bool) ([]data.TaskImportResult, error)
+	History(ctx context.Context, q data.TaskEventsQuery) ([]data.TaskEvent, error)
 }
@@ -38,6 +38,30 @@ func (_m *Storage) FindByEmail(ctx context.Context, email string) (data.User, er
 	return r0, r1
 }
 
+// FindByID provides a mock function with given fields: ctx, id
+func (_m *Storage) FindByID(ctx context.Context, id string) (data.User, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 data.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (data.User, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) data.User); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(data.User)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindByUsername provides a mock function with given fields: ctx, username
 func (_m *Storage) FindByUsername(ctx context.Context, username string) (data.User, error) {
 	ret := _m.Called(ctx, username)
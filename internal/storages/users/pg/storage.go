@@ -47,6 +47,15 @@ func (s *UsersStorage) FindByEmail(ctx context.Context, email string) (data.User
 	return s.findUser(ctx, query, email)
 }
 
+// FindByID returns user by given id.
+//
+// If user is not found returns users.ErrNotFound.
+func (s *UsersStorage) FindByID(ctx context.Context, id string) (data.User, error) {
+	const query = "SELECT id, email, username, encrypted_password, name, created_on FROM users WHERE id = $1 AND deleted_on IS NULL"
+
+	return s.findUser(ctx, query, id)
+}
+
 // Save saves a given user in database.
 //
 // If user with given email or username already exists returns users.ErrAlreadyExists.
@@ -16,5 +16,6 @@ var (
 type Storage interface {
 	FindByUsername(ctx context.Context, username string) (data.User, error)
 	FindByEmail(ctx context.Context, email string) (data.User, error)
+	FindByID(ctx context.Context, id string) (data.User, error)
 	Save(ctx context.Context, u *data.User) error
 }